@@ -2,6 +2,8 @@ package app
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	bam "github.com/cosmos/cosmos-sdk/baseapp"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -9,6 +11,9 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	"github.com/cosmos/cosmos-sdk/x/ibc"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/cosmos/cosmos-sdk/x/slashing"
+	"github.com/cosmos/cosmos-sdk/x/stake"
 	abci "github.com/tendermint/tendermint/abci/types"
 	cmn "github.com/tendermint/tendermint/libs/common"
 	dbm "github.com/tendermint/tendermint/libs/db"
@@ -16,7 +21,20 @@ import (
 	tmtypes "github.com/tendermint/tendermint/types"
 
 	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/allowance"
+	"github.com/dcgraph/bvs-cosmos/x/amm"
+	"github.com/dcgraph/bvs-cosmos/x/claim"
+	"github.com/dcgraph/bvs-cosmos/x/dispute"
+	"github.com/dcgraph/bvs-cosmos/x/fee"
+	"github.com/dcgraph/bvs-cosmos/x/gov"
+	"github.com/dcgraph/bvs-cosmos/x/guarantee"
+	"github.com/dcgraph/bvs-cosmos/x/htlc"
+	"github.com/dcgraph/bvs-cosmos/x/market"
+	"github.com/dcgraph/bvs-cosmos/x/multisig"
+	"github.com/dcgraph/bvs-cosmos/x/refund"
 	"github.com/dcgraph/bvs-cosmos/x/shop"
+	"github.com/dcgraph/bvs-cosmos/x/upgrade"
+	"github.com/dcgraph/bvs-cosmos/x/vesting"
 )
 
 const (
@@ -32,19 +50,53 @@ type BvsApp struct {
 	cdc *wire.Codec
 
 	// keys to access the multistore
-	keyMain    *sdk.KVStoreKey
-	keyAccount *sdk.KVStoreKey
-	keyCodex   *sdk.KVStoreKey
-	keyVoucher *sdk.KVStoreKey
-	keyIBC     *sdk.KVStoreKey
+	keyMain      *sdk.KVStoreKey
+	keyAccount   *sdk.KVStoreKey
+	keyCodex     *sdk.KVStoreKey
+	keyVoucher   *sdk.KVStoreKey
+	keyPending   *sdk.KVStoreKey
+	keyAllowance *sdk.KVStoreKey
+	keyClaim     *sdk.KVStoreKey
+	keyHTLC      *sdk.KVStoreKey
+	keyRefund    *sdk.KVStoreKey
+	keyDispute   *sdk.KVStoreKey
+	keyMultisig  *sdk.KVStoreKey
+	keyMarket    *sdk.KVStoreKey
+	keyAMM       *sdk.KVStoreKey
+	keyFee       *sdk.KVStoreKey
+	keyFeePool   *sdk.KVStoreKey
+	keyStake     *sdk.KVStoreKey
+	keySlashing  *sdk.KVStoreKey
+	keyParams    *sdk.KVStoreKey
+	keyGov       *sdk.KVStoreKey
+	keyIBC       *sdk.KVStoreKey
 
 	// manage getting and setting accounts
 	accountMapper       auth.AccountMapper
 	codexMapper         types.CodexMapper
 	voucherMapper       types.VoucherMapper
+	pendingMapper       types.PendingMapper
+	paramsMapper        types.ParamsMapper
 	feeCollectionKeeper auth.FeeCollectionKeeper
 	coinKeeper          bank.Keeper
+	vestingKeeper       vesting.Keeper
 	ibcMapper           ibc.Mapper
+	shopKeeper          shop.Keeper
+	allowanceKeeper     allowance.Keeper
+	claimKeeper         claim.Keeper
+	htlcKeeper          htlc.Keeper
+	refundKeeper        refund.Keeper
+	disputeKeeper       dispute.Keeper
+	guaranteeKeeper     guarantee.Keeper
+	multisigKeeper      multisig.Keeper
+	marketKeeper        market.Keeper
+	ammKeeper           amm.Keeper
+	feeKeeper           fee.Keeper
+	stakeKeeper         stake.Keeper
+	slashingKeeper      slashing.Keeper
+	paramsKeeper        params.Keeper
+	govKeeper           gov.Keeper
+	upgradeKeeper       upgrade.Keeper
 }
 
 // NewBvsApp returns a reference to a new BvsApp given a logger and
@@ -58,13 +110,28 @@ func NewBvsApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam.BaseApp
 
 	// create your application type
 	var app = &BvsApp{
-		cdc:        cdc,
-		BaseApp:    bam.NewBaseApp(appName, logger, db, auth.DefaultTxDecoder(cdc), baseAppOptions...),
-		keyMain:    sdk.NewKVStoreKey("main"),
-		keyAccount: sdk.NewKVStoreKey("acc"),
-		keyCodex:   sdk.NewKVStoreKey("codex"),
-		keyVoucher: sdk.NewKVStoreKey("voucher"),
-		keyIBC:     sdk.NewKVStoreKey("ibc"),
+		cdc:          cdc,
+		BaseApp:      bam.NewBaseApp(appName, logger, db, auth.DefaultTxDecoder(cdc), baseAppOptions...),
+		keyMain:      sdk.NewKVStoreKey("main"),
+		keyAccount:   sdk.NewKVStoreKey("acc"),
+		keyCodex:     sdk.NewKVStoreKey("codex"),
+		keyVoucher:   sdk.NewKVStoreKey("voucher"),
+		keyPending:   sdk.NewKVStoreKey("pending"),
+		keyAllowance: sdk.NewKVStoreKey("allowance"),
+		keyClaim:     sdk.NewKVStoreKey("claim"),
+		keyHTLC:      sdk.NewKVStoreKey("htlc"),
+		keyRefund:    sdk.NewKVStoreKey("refund"),
+		keyDispute:   sdk.NewKVStoreKey("dispute"),
+		keyMultisig:  sdk.NewKVStoreKey("multisig"),
+		keyMarket:    sdk.NewKVStoreKey("market"),
+		keyAMM:       sdk.NewKVStoreKey("amm"),
+		keyFee:       sdk.NewKVStoreKey("fee"),
+		keyFeePool:   sdk.NewKVStoreKey("fee-pool"),
+		keyStake:     sdk.NewKVStoreKey("stake"),
+		keySlashing:  sdk.NewKVStoreKey("slashing"),
+		keyParams:    sdk.NewKVStoreKey("params"),
+		keyGov:       sdk.NewKVStoreKey("gov"),
+		keyIBC:       sdk.NewKVStoreKey("ibc"),
 	}
 
 	// define and attach the mappers and keepers
@@ -89,25 +156,64 @@ func NewBvsApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam.BaseApp
 			return &types.Voucher{}
 		},
 	)
+	app.pendingMapper = types.NewPendingMapper(
+		cdc,
+		app.keyPending,
+		func() *types.PendingTransfer {
+			return &types.PendingTransfer{}
+		},
+	)
+	app.paramsMapper = types.NewParamsMapper(cdc, app.keyMain)
 	app.coinKeeper = bank.NewKeeper(app.accountMapper)
+	app.vestingKeeper = vesting.NewKeeper(app.cdc, app.accountMapper, app.RegisterCodespace(vesting.DefaultCodespace))
+	app.feeCollectionKeeper = auth.NewFeeCollectionKeeper(app.cdc, app.keyFee)
+	app.stakeKeeper = stake.NewKeeper(app.cdc, app.keyStake, app.coinKeeper, app.RegisterCodespace(stake.DefaultCodespace))
+	app.paramsKeeper = params.NewKeeper(app.cdc, app.keyParams)
+	app.slashingKeeper = slashing.NewKeeper(app.cdc, app.keySlashing, app.stakeKeeper, app.paramsKeeper.Getter(), app.RegisterCodespace(slashing.DefaultCodespace))
 	app.ibcMapper = ibc.NewMapper(app.cdc, app.keyIBC, app.RegisterCodespace(ibc.DefaultCodespace))
+	app.shopKeeper = shop.NewKeeper(app.codexMapper, app.voucherMapper, app.pendingMapper, app.coinKeeper, app.vestingKeeper, app.paramsMapper, app.RegisterCodespace(shop.DefaultCodespace))
+	app.allowanceKeeper = allowance.NewKeeper(app.cdc, app.keyAllowance, app.shopKeeper, app.RegisterCodespace(allowance.DefaultCodespace))
+	app.claimKeeper = claim.NewKeeper(app.cdc, app.keyClaim, app.shopKeeper, app.RegisterCodespace(claim.DefaultCodespace))
+	app.htlcKeeper = htlc.NewKeeper(app.cdc, app.keyHTLC, app.shopKeeper, app.RegisterCodespace(htlc.DefaultCodespace))
+	app.refundKeeper = refund.NewKeeper(app.cdc, app.keyRefund, app.shopKeeper, app.RegisterCodespace(refund.DefaultCodespace))
+	app.disputeKeeper = dispute.NewKeeper(app.cdc, app.keyDispute, app.shopKeeper, app.RegisterCodespace(dispute.DefaultCodespace))
+	app.guaranteeKeeper = guarantee.NewKeeper(app.shopKeeper, app.disputeKeeper, app.RegisterCodespace(guarantee.DefaultCodespace))
+	app.multisigKeeper = multisig.NewKeeper(app.cdc, app.keyMultisig, app.Router(), app.RegisterCodespace(multisig.DefaultCodespace))
+	app.marketKeeper = market.NewKeeper(app.cdc, app.keyMarket, app.shopKeeper, app.RegisterCodespace(market.DefaultCodespace))
+	app.ammKeeper = amm.NewKeeper(app.cdc, app.keyAMM, app.coinKeeper, app.vestingKeeper, app.paramsMapper, app.RegisterCodespace(amm.DefaultCodespace))
+	app.feeKeeper = fee.NewKeeper(app.cdc, app.keyFeePool, app.feeCollectionKeeper, app.coinKeeper, app.stakeKeeper, app.RegisterCodespace(fee.DefaultCodespace))
+	app.govKeeper = gov.NewKeeper(app.cdc, app.keyGov, app.coinKeeper, app.stakeKeeper, app.newParamStore(), app.RegisterCodespace(gov.DefaultCodespace))
+	app.upgradeKeeper = upgrade.NewKeeper(app.cdc, app.keyMain, app.govKeeper, SchemaVersion)
 
 	// register message routes
 	app.Router().
-		AddRoute("bank", bank.NewHandler(app.coinKeeper)).
+		AddRoute("bank", vesting.NewBankHandler(app.vestingKeeper, bank.NewHandler(app.coinKeeper))).
 		AddRoute("ibc", ibc.NewHandler(app.ibcMapper, app.coinKeeper)).
-		AddRoute("bvs", shop.NewHandler(app.codexMapper))
+		AddRoute("stake", stake.NewHandler(app.stakeKeeper)).
+		AddRoute("slashing", slashing.NewHandler(app.slashingKeeper)).
+		AddRoute("bvs", shop.NewHandler(app.shopKeeper)).
+		AddRoute("allowance", allowance.NewHandler(app.allowanceKeeper)).
+		AddRoute("claim", claim.NewHandler(app.claimKeeper)).
+		AddRoute("htlc", htlc.NewHandler(app.htlcKeeper)).
+		AddRoute("refund", refund.NewHandler(app.refundKeeper)).
+		AddRoute("dispute", dispute.NewHandler(app.disputeKeeper)).
+		AddRoute("guarantee", guarantee.NewHandler(app.guaranteeKeeper)).
+		AddRoute("multisig", multisig.NewHandler(app.multisigKeeper)).
+		AddRoute("market", market.NewHandler(app.marketKeeper)).
+		AddRoute("amm", amm.NewHandler(app.ammKeeper)).
+		AddRoute("gov", gov.NewHandler(app.govKeeper))
 
 	// perform initialization logic
 	app.SetInitChainer(app.initChainer)
 	app.SetBeginBlocker(app.BeginBlocker)
 	app.SetEndBlocker(app.EndBlocker)
-	app.SetAnteHandler(auth.NewAnteHandler(app.accountMapper, app.feeCollectionKeeper))
-	// XXX: Do we need our own AnteHandler?
+	app.SetAnteHandler(fee.NewAnteHandler(app.feeKeeper, auth.NewAnteHandler(app.accountMapper, app.feeCollectionKeeper)))
 
 	// mount the multistore and load the latest state
 	app.MountStoresIAVL(app.keyMain,
-		app.keyAccount, app.keyCodex, app.keyVoucher, app.keyIBC)
+		app.keyAccount, app.keyCodex, app.keyVoucher, app.keyPending,
+		app.keyAllowance, app.keyClaim, app.keyHTLC, app.keyRefund, app.keyDispute,
+		app.keyMultisig, app.keyMarket, app.keyAMM, app.keyFee, app.keyFeePool, app.keyStake, app.keySlashing, app.keyParams, app.keyGov, app.keyIBC)
 	err := app.LoadLatestVersion(app.keyMain)
 	if err != nil {
 		cmn.Exit(err.Error())
@@ -128,11 +234,55 @@ func MakeCodec() *wire.Codec {
 	bank.RegisterWire(cdc)
 	ibc.RegisterWire(cdc)
 	auth.RegisterWire(cdc)
+	stake.RegisterWire(cdc)
+	slashing.RegisterWire(cdc)
 
 	// register custom type
 	cdc.RegisterConcrete(&types.UserAccount{}, "bvs/UserAccount", nil)
+	cdc.RegisterConcrete(&types.ContinuousVestingAccount{}, "bvs/ContinuousVestingAccount", nil)
+	cdc.RegisterConcrete(&types.DelayedVestingAccount{}, "bvs/DelayedVestingAccount", nil)
 	cdc.RegisterConcrete(&types.Codex{}, "bvs/Codex", nil)
 	cdc.RegisterConcrete(&shop.MsgBvs{}, "bvs/MsgBvs", nil)
+	cdc.RegisterConcrete(&shop.MsgSpendVoucher{}, "bvs/MsgSpendVoucher", nil)
+	cdc.RegisterConcrete(&shop.MsgReloadVoucher{}, "bvs/MsgReloadVoucher", nil)
+	cdc.RegisterConcrete(&shop.MsgApproveTransfer{}, "bvs/MsgApproveTransfer", nil)
+	cdc.RegisterConcrete(&shop.MsgAcceptTransfer{}, "bvs/MsgAcceptTransfer", nil)
+	cdc.RegisterConcrete(&shop.MsgReclaimTransfer{}, "bvs/MsgReclaimTransfer", nil)
+	cdc.RegisterConcrete(&shop.MsgSettleExpired{}, "bvs/MsgSettleExpired", nil)
+	cdc.RegisterConcrete(&shop.MsgWithdraw{}, "bvs/MsgWithdraw", nil)
+	cdc.RegisterConcrete(&shop.MsgRestock{}, "bvs/MsgRestock", nil)
+	cdc.RegisterConcrete(&shop.MsgTransferOwnership{}, "bvs/MsgTransferOwnership", nil)
+	cdc.RegisterConcrete(&allowance.MsgApprove{}, "bvs/MsgApprove", nil)
+	cdc.RegisterConcrete(&allowance.MsgRevoke{}, "bvs/MsgRevoke", nil)
+	cdc.RegisterConcrete(&allowance.MsgTransferFrom{}, "bvs/MsgTransferFrom", nil)
+	cdc.RegisterConcrete(&claim.MsgPublishCodes{}, "bvs/MsgPublishCodes", nil)
+	cdc.RegisterConcrete(&claim.MsgCommitClaim{}, "bvs/MsgCommitClaim", nil)
+	cdc.RegisterConcrete(&claim.MsgClaimWithCode{}, "bvs/MsgClaimWithCode", nil)
+	cdc.RegisterConcrete(&htlc.MsgCreateHTLC{}, "bvs/MsgCreateHTLC", nil)
+	cdc.RegisterConcrete(&htlc.MsgClaimHTLC{}, "bvs/MsgClaimHTLC", nil)
+	cdc.RegisterConcrete(&htlc.MsgRefundHTLC{}, "bvs/MsgRefundHTLC", nil)
+	cdc.RegisterConcrete(&refund.MsgRequestRefund{}, "bvs/MsgRequestRefund", nil)
+	cdc.RegisterConcrete(&refund.MsgApproveRefund{}, "bvs/MsgApproveRefund", nil)
+	cdc.RegisterConcrete(&refund.MsgRejectRefund{}, "bvs/MsgRejectRefund", nil)
+	cdc.RegisterConcrete(&dispute.MsgOpenDispute{}, "bvs/MsgOpenDispute", nil)
+	cdc.RegisterConcrete(&dispute.MsgRuleDispute{}, "bvs/MsgRuleDispute", nil)
+	cdc.RegisterConcrete(&guarantee.MsgCloseCodex{}, "bvs/MsgCloseCodex", nil)
+	cdc.RegisterConcrete(&guarantee.MsgDeclareDefault{}, "bvs/MsgDeclareDefault", nil)
+	cdc.RegisterConcrete(&guarantee.MsgClaimCompensation{}, "bvs/MsgClaimCompensation", nil)
+	cdc.RegisterConcrete(&multisig.MsgCreateMultisig{}, "bvs/MsgCreateMultisig", nil)
+	cdc.RegisterConcrete(&multisig.MsgPropose{}, "bvs/MsgPropose", nil)
+	cdc.RegisterConcrete(&multisig.MsgApproveProposal{}, "bvs/MsgApproveProposal", nil)
+	cdc.RegisterConcrete(&market.MsgList{}, "bvs/MsgList", nil)
+	cdc.RegisterConcrete(&market.MsgBuy{}, "bvs/MsgBuy", nil)
+	cdc.RegisterConcrete(&market.MsgDelist{}, "bvs/MsgDelist", nil)
+	cdc.RegisterConcrete(&market.MsgPlaceOrder{}, "bvs/MsgPlaceOrder", nil)
+	cdc.RegisterConcrete(&market.MsgCancelOrder{}, "bvs/MsgCancelOrder", nil)
+	cdc.RegisterConcrete(&amm.MsgAddLiquidity{}, "bvs/MsgAddLiquidity", nil)
+	cdc.RegisterConcrete(&amm.MsgRemoveLiquidity{}, "bvs/MsgRemoveLiquidity", nil)
+	cdc.RegisterConcrete(&amm.MsgSwap{}, "bvs/MsgSwap", nil)
+	cdc.RegisterConcrete(&gov.MsgSubmitProposal{}, "bvs/MsgSubmitProposal", nil)
+	cdc.RegisterConcrete(&gov.MsgDeposit{}, "bvs/MsgDeposit", nil)
+	cdc.RegisterConcrete(&gov.MsgVote{}, "bvs/MsgVote", nil)
 
 	cdc.Seal()
 
@@ -140,15 +290,54 @@ func MakeCodec() *wire.Codec {
 }
 
 // BeginBlocker reflects logic to run before any TXs application are processed
-// by the application.
-func (app *BvsApp) BeginBlocker(_ sdk.Context, _ abci.RequestBeginBlock) abci.ResponseBeginBlock {
-	return abci.ResponseBeginBlock{}
+// by the application. The upgrade planned at the height is carried out, and
+// the validators that missed too many blocks or signed twice are slashed and
+// jailed.
+func (app *BvsApp) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock) abci.ResponseBeginBlock {
+	tags := upgrade.BeginBlocker(ctx, app.upgradeKeeper)
+	tags = tags.AppendTags(slashing.BeginBlocker(ctx, req, app.slashingKeeper))
+	return abci.ResponseBeginBlock{Tags: tags.ToKVPairs()}
 }
 
 // EndBlocker reflects logic to run after all TXs are processed by the
-// application.
-func (app *BvsApp) EndBlocker(_ sdk.Context, _ abci.RequestEndBlock) abci.ResponseEndBlock {
-	return abci.ResponseEndBlock{}
+// application, and returns the changes to the validator set.
+func (app *BvsApp) EndBlocker(ctx sdk.Context, _ abci.RequestEndBlock) abci.ResponseEndBlock {
+	tags := refund.EndBlocker(ctx, app.refundKeeper)
+	tags = tags.AppendTags(multisig.EndBlocker(ctx, app.multisigKeeper))
+	tags = tags.AppendTags(market.EndBlocker(ctx, app.marketKeeper))
+	tags = tags.AppendTags(gov.EndBlocker(ctx, app.govKeeper))
+	tags = tags.AppendTags(fee.EndBlocker(ctx, app.feeKeeper))
+	validatorUpdates := stake.EndBlocker(ctx, app.stakeKeeper)
+	app.slashingKeeper.AddValidators(ctx, validatorUpdates)
+	return abci.ResponseEndBlock{ValidatorUpdates: validatorUpdates, Tags: tags.ToKVPairs()}
+}
+
+// Query answers "custom/<module>/..." queries with the queriers of the
+// modules against the last committed state, and hands any other query over
+// to the BaseApp.
+func (app *BvsApp) Query(req abci.RequestQuery) abci.ResponseQuery {
+	path := strings.Split(strings.Trim(req.Path, "/"), "/")
+	if len(path) < 2 || path[0] != "custom" {
+		return app.BaseApp.Query(req)
+	}
+
+	ctx := app.NewContext(true, abci.Header{Height: app.LastBlockHeight()})
+	var res []byte
+	var err sdk.Error
+	switch path[1] {
+	case "amm":
+		res, err = amm.Query(ctx, app.ammKeeper, path[2:])
+	case "fee":
+		res, err = fee.Query(ctx, app.feeKeeper, path[2:])
+	case "vesting":
+		res, err = vesting.Query(ctx, app.vestingKeeper, path[2:])
+	default:
+		err = sdk.ErrUnknownRequest(fmt.Sprintf("no custom querier for %s", path[1]))
+	}
+	if err != nil {
+		return err.QueryResult()
+	}
+	return abci.ResponseQuery{Code: uint32(sdk.ABCICodeOK), Value: res}
 }
 
 // initChainer implements the custom application logic that the BaseApp will
@@ -166,15 +355,53 @@ func (app *BvsApp) initChainer(ctx sdk.Context, req abci.RequestInitChain) abci.
 		panic(err)
 	}
 
+	if err := genesisState.Validate(); err != nil {
+		panic(err)
+	}
+
+	params := genesisState.Params
+	if params == nil {
+		params = types.DefaultBvsParams()
+	}
+	if err := app.paramsMapper.SetParams(ctx, params); err != nil {
+		panic(err)
+	}
+
+	// exported accounts keep their numbers, while those of a new genesis
+	// state, which have none, are numbered in turn
+	var maxNumber int64
+	numbered := false
+	for _, gacc := range genesisState.Accounts {
+		if gacc.AccountNumber != 0 {
+			numbered = true
+		}
+		if gacc.AccountNumber > maxNumber {
+			maxNumber = gacc.AccountNumber
+		}
+	}
+
+	loose := sdk.ZeroInt()
 	for _, gacc := range genesisState.Accounts {
-		acc, err := gacc.ToUserAccount()
+		loose = loose.Add(gacc.Coins.AmountOf(types.DenomSilver))
+		acc, err := gacc.ToAccount()
 		if err != nil {
 			panic(err)
 		}
 
-		acc.AccountNumber = app.accountMapper.GetNextAccountNumber(ctx)
+		if !numbered {
+			err = acc.SetAccountNumber(app.accountMapper.GetNextAccountNumber(ctx))
+			if err != nil {
+				panic(err)
+			}
+		}
 		app.accountMapper.SetAccount(ctx, acc)
 	}
+	if numbered {
+		// new accounts get numbers after the greatest, which the account
+		// mapper only hands out one at a time
+		for app.accountMapper.GetNextAccountNumber(ctx) < maxNumber {
+		}
+	}
 
 	for _, cod := range genesisState.Codices {
 		app.codexMapper.SetCodex(ctx, cod)
@@ -184,28 +411,60 @@ func (app *BvsApp) initChainer(ctx sdk.Context, req abci.RequestInitChain) abci.
 		app.voucherMapper.SetVoucher(ctx, vou)
 	}
 
-	return abci.ResponseInitChain{}
+	for _, pt := range genesisState.Pendings {
+		app.pendingMapper.SetPending(ctx, pt)
+	}
+
+	allowance.InitGenesis(ctx, app.allowanceKeeper, genesisState.Allowances)
+	claim.InitGenesis(ctx, app.claimKeeper, genesisState.ClaimCampaigns, genesisState.ClaimCommits)
+	htlc.InitGenesis(ctx, app.htlcKeeper, genesisState.HTLCs)
+	refund.InitGenesis(ctx, app.refundKeeper, genesisState.RefundRequests)
+	dispute.InitGenesis(ctx, app.disputeKeeper, genesisState.Disputes, genesisState.Arbiters)
+	multisig.InitGenesis(ctx, app.multisigKeeper, genesisState.Multisigs, genesisState.Proposals)
+	market.InitGenesis(ctx, app.marketKeeper, genesisState.Listings, genesisState.Orders)
+	amm.InitGenesis(ctx, app.ammKeeper, genesisState.Pool, genesisState.PoolShares)
+	fee.InitGenesis(ctx, app.feeKeeper, genesisState.FeePolicy, genesisState.CommunityPool, genesisState.BlockFees)
+	gov.InitGenesis(ctx, app.govKeeper, genesisState.GovParams, genesisState.GovProposals, genesisState.GovVotes, genesisState.UpgradePlan)
+	upgrade.InitGenesis(ctx, app.upgradeKeeper)
+	if err := app.loadStores(ctx, genesisState.Stores); err != nil {
+		panic(err)
+	}
+
+	stakeData := genesisState.StakeData
+	if stakeData == nil {
+		stakeData = types.DefaultStakeGenesis(loose)
+	}
+	validators, err := stake.InitGenesis(ctx, app.stakeKeeper, *stakeData)
+	if err != nil {
+		panic(err)
+	}
+	// the validators of the tendermint genesis sign the first blocks when
+	// none are bonded yet, so their signatures must be known too
+	slashing.InitGenesis(ctx, app.slashingKeeper, *stakeData)
+	app.slashingKeeper.AddValidators(ctx, req.Validators)
+
+	return abci.ResponseInitChain{Validators: validators}
+}
+
+// LoadHeight loads the state committed at a past height, to be exported.
+func (app *BvsApp) LoadHeight(height int64) error {
+	return app.LoadVersion(height, app.keyMain)
 }
 
 // ExportAppStateAndValidators implements custom application logic that exposes
 // various parts of the application's state and set of validators. An error is
-// returned if any step getting the state or set of validators fails.
-func (app *BvsApp) ExportAppStateAndValidators() (appState json.RawMessage, validators []tmtypes.GenesisValidator, err error) {
+// returned if any step getting the state or set of validators fails. With
+// forZeroHeight the heights in the state are rebased for a new chain
+// starting over from height zero.
+func (app *BvsApp) ExportAppStateAndValidators(forZeroHeight bool) (appState json.RawMessage, validators []tmtypes.GenesisValidator, err error) {
 	ctx := app.NewContext(true, abci.Header{})
 	accounts := []*types.GenesisAccount{}
 	codices := []*types.Codex{}
 	vouchers := []*types.Voucher{}
+	pendings := []*types.PendingTransfer{}
 
 	appendAccountsFn := func(acc auth.Account) bool {
-		i := app.accountMapper.GetAccount(ctx, acc.GetAddress())
-		v := i.(*types.UserAccount)
-		account := &types.GenesisAccount{
-			Id:      v.Id,
-			Address: v.Address,
-			Coins:   v.Coins,
-		}
-
-		accounts = append(accounts, account)
+		accounts = append(accounts, types.NewGenesisAccount(acc))
 		return false
 	}
 	app.accountMapper.IterateAccounts(ctx, appendAccountsFn)
@@ -222,12 +481,49 @@ func (app *BvsApp) ExportAppStateAndValidators() (appState json.RawMessage, vali
 	}
 	app.voucherMapper.IterateVouchers(ctx, appendVouchersFn)
 
-	genState := types.GenesisState{Accounts: accounts,
-		Codices: codices, Vouchers: vouchers}
+	appendPendingsFn := func(pt *types.PendingTransfer) bool {
+		pendings = append(pendings, pt)
+		return false
+	}
+	app.pendingMapper.IteratePendings(ctx, appendPendingsFn)
+
+	campaigns, commits := claim.WriteGenesis(ctx, app.claimKeeper)
+	disputes, arbiters := dispute.WriteGenesis(ctx, app.disputeKeeper)
+	multisigs, proposals := multisig.WriteGenesis(ctx, app.multisigKeeper)
+	listings, orders := market.WriteGenesis(ctx, app.marketKeeper)
+	pool, poolShares := amm.WriteGenesis(ctx, app.ammKeeper)
+	feePolicy, communityPool, blockFees := fee.WriteGenesis(ctx, app.feeKeeper)
+	govParams, govProposals, govVotes, upgradePlan := gov.WriteGenesis(ctx, app.govKeeper)
+	stakeData := stake.WriteGenesis(ctx, app.stakeKeeper)
+
+	genState := types.GenesisState{Params: app.paramsMapper.GetParams(ctx),
+		Accounts: accounts,
+		Codices:  codices, Vouchers: vouchers, Pendings: pendings,
+		Allowances:     allowance.WriteGenesis(ctx, app.allowanceKeeper),
+		ClaimCampaigns: campaigns, ClaimCommits: commits,
+		HTLCs:          htlc.WriteGenesis(ctx, app.htlcKeeper),
+		RefundRequests: refund.WriteGenesis(ctx, app.refundKeeper),
+		Disputes:       disputes, Arbiters: arbiters,
+		Multisigs: multisigs, Proposals: proposals,
+		Listings: listings, Orders: orders,
+		Pool: pool, PoolShares: poolShares,
+		FeePolicy: feePolicy, CommunityPool: communityPool, BlockFees: blockFees,
+		GovParams: govParams, GovProposals: govProposals, GovVotes: govVotes,
+		UpgradePlan: upgradePlan,
+		StakeData:   &stakeData,
+		Stores:      app.dumpStores(ctx)}
+	if forZeroHeight {
+		height := app.LastBlockHeight()
+		genState.ForZeroHeight(height)
+		if err := app.signingInfosForZeroHeight(genState.Stores, height); err != nil {
+			return nil, nil, err
+		}
+	}
 	appState, err = wire.MarshalJSONIndent(app.cdc, genState)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	validators = stake.WriteValidators(ctx, app.stakeKeeper)
 	return appState, validators, err
 }
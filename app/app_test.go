@@ -1,19 +1,40 @@
 package app
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"os"
 	"testing"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/slashing"
+	"github.com/cosmos/cosmos-sdk/x/stake"
 	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	dbm "github.com/tendermint/tendermint/libs/db"
 	"github.com/tendermint/tendermint/libs/log"
+	tmtypes "github.com/tendermint/tendermint/types"
 
 	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/allowance"
+	"github.com/dcgraph/bvs-cosmos/x/amm"
+	"github.com/dcgraph/bvs-cosmos/x/claim"
+	"github.com/dcgraph/bvs-cosmos/x/dispute"
+	"github.com/dcgraph/bvs-cosmos/x/fee"
+	"github.com/dcgraph/bvs-cosmos/x/gov"
+	"github.com/dcgraph/bvs-cosmos/x/guarantee"
+	"github.com/dcgraph/bvs-cosmos/x/htlc"
+	"github.com/dcgraph/bvs-cosmos/x/market"
+	"github.com/dcgraph/bvs-cosmos/x/multisig"
+	"github.com/dcgraph/bvs-cosmos/x/refund"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+	"github.com/dcgraph/bvs-cosmos/x/upgrade"
+	"github.com/dcgraph/bvs-cosmos/x/vesting"
 )
 
 func setGenesis(bvsApp *BvsApp, accounts ...*types.UserAccount) (types.GenesisState, error) {
@@ -37,6 +58,31 @@ func setGenesis(bvsApp *BvsApp, accounts ...*types.UserAccount) (types.GenesisSt
 	return genesisState, nil
 }
 
+func setGenesisState(bvsApp *BvsApp, genesisState types.GenesisState) error {
+	stateBytes, err := wire.MarshalJSONIndent(bvsApp.cdc, genesisState)
+	if err != nil {
+		return err
+	}
+
+	// initialize and commit the chain
+	bvsApp.InitChain(abci.RequestInitChain{
+		Validators: []abci.Validator{}, AppStateBytes: stateBytes,
+	})
+	bvsApp.Commit()
+
+	return nil
+}
+
+// newTestUser returns a genesis account with the given coins along with its
+// address and user id.
+func newTestUser(t *testing.T, coins string) (*types.GenesisAccount, sdk.AccAddress, string) {
+	addr := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	parsed, err := sdk.ParseCoins(coins)
+	require.Nil(t, err)
+	id := types.UserId(addr)
+	return &types.GenesisAccount{Id: id, Address: addr, Coins: parsed}, addr, id
+}
+
 func TestGenesis(t *testing.T) {
 	logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout)).With("module", "sdk/app")
 	db := dbm.NewMemDB()
@@ -80,3 +126,1619 @@ func TestGenesis(t *testing.T) {
 	res = bvsApp.accountMapper.GetAccount(ctx, baseAcct.Address)
 	require.Equal(t, bvsAcct, res)
 }
+
+func TestStoredValueVoucher(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	buyerAcc, buyerAddr, buyer := newTestUser(t, "1000bvs")
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	codex := &types.Codex{
+		Id:          "0:c:gift",
+		Owner:       owner,
+		Value:       "gift card",
+		UnitPrice:   100,
+		SaleType:    "sale",
+		CountAvail:  10,
+		StoredValue: true,
+	}
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{buyerAcc, ownerAcc},
+		Codices:  []*types.Codex{codex},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := shop.NewHandler(bvsApp.shopKeeper)
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+
+	// less than the unit price cannot fund a card
+	asset := types.BvsAsset{Coins: silver(50)}
+	res := handler(ctx, shop.BuildBvsMsg(buyerAddr, buyer, codex.Id, &asset))
+	require.False(t, res.IsOK())
+
+	asset = types.BvsAsset{Coins: silver(500)}
+	res = handler(ctx, shop.BuildBvsMsg(buyerAddr, buyer, codex.Id, &asset))
+	require.True(t, res.IsOK(), res.Log)
+	vouId := string(res.Data)
+
+	vou := bvsApp.voucherMapper.GetVoucher(ctx, vouId)
+	require.Equal(t, buyer, vou.Holder)
+	require.Equal(t, silver(500), vou.Balance)
+	require.Equal(t, silver(500), bvsApp.coinKeeper.GetCoins(ctx, buyerAddr))
+	require.True(t, bvsApp.coinKeeper.GetCoins(ctx, ownerAddr).IsZero())
+
+	// the balance is held by the codex, out of the reach of its owner
+	cod := bvsApp.codexMapper.GetCodex(ctx, codex.Id)
+	require.Equal(t, silver(500), cod.Coins)
+	require.Equal(t, 500, cod.Escrowed)
+	require.Equal(t, 0, cod.Deposit)
+	res = handler(ctx, shop.BuildWithdrawMsg(ownerAddr, owner, codex.Id, silver(100), owner))
+	require.False(t, res.IsOK())
+
+	res = handler(ctx, shop.BuildSpendVoucherMsg(buyerAddr, buyer, vouId, silver(200)))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(200), bvsApp.coinKeeper.GetCoins(ctx, ownerAddr))
+	require.Equal(t, silver(300), bvsApp.voucherMapper.GetVoucher(ctx, vouId).Balance)
+	require.Equal(t, silver(300), bvsApp.codexMapper.GetCodex(ctx, codex.Id).Coins)
+
+	// the merchant cannot be paid more than the escrowed balance
+	res = handler(ctx, shop.BuildSpendVoucherMsg(buyerAddr, buyer, vouId, silver(400)))
+	require.False(t, res.IsOK())
+
+	res = handler(ctx, shop.BuildReloadVoucherMsg(buyerAddr, buyer, vouId, silver(100)))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(400), bvsApp.voucherMapper.GetVoucher(ctx, vouId).Balance)
+	require.Equal(t, silver(400), bvsApp.coinKeeper.GetCoins(ctx, buyerAddr))
+	require.Equal(t, 400, bvsApp.codexMapper.GetCodex(ctx, codex.Id).Escrowed)
+}
+
+func TestTransferPolicy(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, alice := newTestUser(t, "")
+	bobAcc, bobAddr, bob := newTestUser(t, "")
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	codices := []*types.Codex{
+		{Id: "0:c:bound", Owner: owner, CountLive: 1, Transfer: types.TransferNone},
+		{Id: "0:c:approved", Owner: owner, CountLive: 1, Transfer: types.TransferOwnerApproved},
+	}
+	vouchers := []*types.Voucher{
+		{Id: "0:v:bound:0", Origin: "0:c:bound", Holder: alice},
+		{Id: "0:v:approved:0", Origin: "0:c:approved", Holder: alice},
+	}
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc, ownerAcc},
+		Codices:  codices,
+		Vouchers: vouchers,
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := shop.NewHandler(bvsApp.shopKeeper)
+	send := func(from sdk.AccAddress, fromId string, to string, voucher string) sdk.Result {
+		asset := types.BvsAsset{Vouchers: []string{voucher}}
+		return handler(ctx, shop.BuildBvsMsg(from, fromId, to, &asset))
+	}
+
+	require.False(t, send(aliceAddr, alice, bob, "0:v:bound:0").IsOK())
+	require.False(t, send(aliceAddr, alice, bob, "0:v:approved:0").IsOK())
+
+	// an unknown recipient is not approved, whatever the approval says
+	vou := bvsApp.voucherMapper.GetVoucher(ctx, "0:v:approved:0")
+	require.NotNil(t, bvsApp.shopKeeper.CheckTransferable(ctx, vou, ""))
+
+	// only the codex owner may approve
+	res := handler(ctx, shop.BuildApproveTransferMsg(aliceAddr, alice, "0:v:approved:0", bob))
+	require.False(t, res.IsOK())
+	res = handler(ctx, shop.BuildApproveTransferMsg(ownerAddr, owner, "0:v:approved:0", bob))
+	require.True(t, res.IsOK(), res.Log)
+
+	res = send(aliceAddr, alice, bob, "0:v:approved:0")
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, bob, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:approved:0").Holder)
+
+	// the approval is used up by the transfer
+	require.False(t, send(bobAddr, bob, alice, "0:v:approved:0").IsOK())
+}
+
+func TestPendingTransfer(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, alice := newTestUser(t, "100bvs")
+	bobAcc, bobAddr, bob := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc},
+		Codices:  []*types.Codex{{Id: "0:c:latte", Owner: alice, CountLive: 1}},
+		Vouchers: []*types.Voucher{{Id: "0:v:latte:0", Origin: "0:c:latte", Holder: alice}},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 10})
+	handler := shop.NewHandler(bvsApp.shopKeeper)
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+
+	asset := types.BvsAsset{Coins: silver(30), Vouchers: []string{"0:v:latte:0"}}
+	res := handler(ctx, shop.BuildPendingBvsMsg(aliceAddr, alice, bob, &asset, 5))
+	require.True(t, res.IsOK(), res.Log)
+	accepted := string(res.Data)
+	require.Equal(t, silver(70), bvsApp.coinKeeper.GetCoins(ctx, aliceAddr))
+	require.Equal(t, accepted, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:latte:0").Holder)
+
+	asset = types.BvsAsset{Coins: silver(20)}
+	res = handler(ctx, shop.BuildPendingBvsMsg(aliceAddr, alice, bob, &asset, 5))
+	require.True(t, res.IsOK(), res.Log)
+	reclaimed := string(res.Data)
+
+	// the sender cannot reclaim before the deadline
+	res = handler(ctx, shop.BuildReclaimTransferMsg(aliceAddr, alice, reclaimed))
+	require.False(t, res.IsOK())
+
+	ctx = ctx.WithBlockHeight(15)
+	res = handler(ctx, shop.BuildAcceptTransferMsg(bobAddr, bob, accepted))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(30), bvsApp.coinKeeper.GetCoins(ctx, bobAddr))
+	require.Equal(t, bob, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:latte:0").Holder)
+
+	ctx = ctx.WithBlockHeight(16)
+	res = handler(ctx, shop.BuildAcceptTransferMsg(bobAddr, bob, reclaimed))
+	require.False(t, res.IsOK())
+	res = handler(ctx, shop.BuildReclaimTransferMsg(aliceAddr, alice, reclaimed))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(70), bvsApp.coinKeeper.GetCoins(ctx, aliceAddr))
+	require.Nil(t, bvsApp.pendingMapper.GetPending(ctx, reclaimed))
+}
+
+func TestAllowance(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	ownerAcc, ownerAddr, owner := newTestUser(t, "100bvs")
+	posAcc, posAddr, pos := newTestUser(t, "")
+	shopAcc, _, merchant := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{ownerAcc, posAcc, shopAcc},
+		Codices:  []*types.Codex{{Id: "0:c:meal", Owner: merchant, CountLive: 1}},
+		Vouchers: []*types.Voucher{{Id: "0:v:meal:0", Origin: "0:c:meal", Holder: owner}},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := allowance.NewHandler(bvsApp.allowanceKeeper)
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	transferFrom := func(asset types.BvsAsset) sdk.Result {
+		return handler(ctx, &allowance.MsgTransferFrom{
+			SpenderAccount: posAddr, Spender: pos, Owner: owner, Recipient: merchant, Asset: asset,
+		})
+	}
+
+	require.False(t, transferFrom(types.BvsAsset{Coins: silver(10)}).IsOK())
+
+	res := handler(ctx, &allowance.MsgApprove{
+		OwnerAccount: ownerAddr, Owner: owner, Spender: pos,
+		Coins: silver(30), Codices: []string{"0:c:meal"}, ExpireOn: 10,
+	})
+	require.True(t, res.IsOK(), res.Log)
+
+	require.True(t, transferFrom(types.BvsAsset{Coins: silver(20)}).IsOK())
+	require.False(t, transferFrom(types.BvsAsset{Coins: silver(20)}).IsOK())
+	require.True(t, transferFrom(types.BvsAsset{Vouchers: []string{"0:v:meal:0"}}).IsOK())
+	require.Equal(t, merchant, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:meal:0").Holder)
+	require.Equal(t, silver(10), bvsApp.allowanceKeeper.GetAllowance(ctx, owner, pos).Coins)
+
+	ctx = ctx.WithBlockHeight(11)
+	require.False(t, transferFrom(types.BvsAsset{Coins: silver(5)}).IsOK())
+
+	res = handler(ctx, &allowance.MsgRevoke{OwnerAccount: ownerAddr, Owner: owner, Spender: pos})
+	require.True(t, res.IsOK(), res.Log)
+	require.Nil(t, bvsApp.allowanceKeeper.GetAllowance(ctx, owner, pos))
+}
+
+func TestClaimCodes(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	aliceAcc, aliceAddr, alice := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{ownerAcc, aliceAcc},
+		Codices:  []*types.Codex{{Id: "0:c:promo", Owner: owner, CountAvail: 10}},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := claim.NewHandler(bvsApp.claimKeeper)
+	claimCode := func(code string, proof [][]byte) sdk.Result {
+		return handler(ctx, &claim.MsgClaimWithCode{
+			ClaimerAccount: aliceAddr, Claimer: alice, Codex: "0:c:promo", Code: []byte(code), Proof: proof,
+		})
+	}
+
+	// "red" is published by hash, "green" and "blue" under a Merkle root
+	green, blue := types.ClaimCodeHash([]byte("green")), types.ClaimCodeHash([]byte("blue"))
+	res := handler(ctx, &claim.MsgPublishCodes{
+		OwnerAccount: ownerAddr, Owner: owner, Codex: "0:c:promo",
+		Hashes: [][]byte{types.ClaimCodeHash([]byte("red"))},
+		Roots:  [][]byte{types.MerkleRoot(green, [][]byte{blue})},
+	})
+	require.True(t, res.IsOK(), res.Log)
+
+	require.False(t, claimCode("yellow", nil).IsOK())
+	res = claimCode("red", nil)
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, alice, bvsApp.voucherMapper.GetVoucher(ctx, string(res.Data)).Holder)
+	require.False(t, claimCode("red", nil).IsOK())
+
+	require.False(t, claimCode("green", nil).IsOK())
+
+	// the two children of a node do not pass for a code hashing to that node
+	grandchild := types.ClaimCodeHash([]byte("grey"))
+	black := types.MerkleRoot(grandchild, [][]byte{green})
+	res = handler(ctx, &claim.MsgPublishCodes{
+		OwnerAccount: ownerAddr, Owner: owner, Codex: "0:c:promo",
+		Roots: [][]byte{types.MerkleRoot(black, [][]byte{blue})},
+	})
+	require.True(t, res.IsOK(), res.Log)
+	children := append(append([]byte{}, grandchild...), green...)
+	if bytes.Compare(grandchild, green) > 0 {
+		children = append(append([]byte{}, green...), grandchild...)
+	}
+	require.False(t, claimCode(string(children), [][]byte{blue}).IsOK())
+
+	require.True(t, claimCode("green", [][]byte{blue}).IsOK())
+	require.False(t, claimCode("green", [][]byte{blue}).IsOK())
+
+	// with commits required, the claim must follow a commit from an earlier block
+	res = handler(ctx, &claim.MsgPublishCodes{
+		OwnerAccount: ownerAddr, Owner: owner, Codex: "0:c:promo", RequireCommit: true,
+	})
+	require.True(t, res.IsOK(), res.Log)
+	require.False(t, claimCode("blue", [][]byte{green}).IsOK())
+	res = handler(ctx, &claim.MsgCommitClaim{
+		ClaimerAccount: aliceAddr, Claimer: alice, Commitment: types.ClaimCommitment([]byte("blue"), alice),
+	})
+	require.True(t, res.IsOK(), res.Log)
+	require.False(t, claimCode("blue", [][]byte{green}).IsOK())
+	ctx = ctx.WithBlockHeight(2)
+	require.True(t, claimCode("blue", [][]byte{green}).IsOK())
+	require.Equal(t, 7, bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:promo").CountAvail)
+}
+
+func TestHTLC(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, alice := newTestUser(t, "100bvs")
+	bobAcc, bobAddr, bob := newTestUser(t, "")
+	ownerAcc, _, owner := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc, ownerAcc},
+		Codices:  []*types.Codex{{Id: "0:c:ticket", Owner: owner, CountLive: 1}},
+		Vouchers: []*types.Voucher{{Id: "0:v:ticket:0", Origin: "0:c:ticket", Holder: alice}},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := htlc.NewHandler(bvsApp.htlcKeeper)
+	secret := []byte("swap secret")
+	hashLock := sha256.Sum256(secret)
+	create := func() string {
+		res := handler(ctx, &htlc.MsgCreateHTLC{
+			SenderAccount: aliceAddr, Sender: alice, Recipient: bob, HashLock: hashLock[:], Timeout: 10,
+			Asset: types.BvsAsset{Coins: sdk.Coins{sdk.NewInt64Coin("bvs", 40)}, Vouchers: []string{"0:v:ticket:0"}},
+		})
+		require.True(t, res.IsOK(), res.Log)
+		return string(res.Data)
+	}
+	claimHTLC := func(id string, preimage []byte) sdk.Result {
+		return handler(ctx, &htlc.MsgClaimHTLC{RecipientAccount: bobAddr, Recipient: bob, HTLC: id, Preimage: preimage})
+	}
+	refund := func(id string) sdk.Result {
+		return handler(ctx, &htlc.MsgRefundHTLC{SenderAccount: aliceAddr, Sender: alice, HTLC: id})
+	}
+
+	id := create()
+	require.Equal(t, id, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:ticket:0").Holder)
+	require.False(t, claimHTLC(id, []byte("wrong")).IsOK())
+	require.False(t, refund(id).IsOK())
+	require.True(t, claimHTLC(id, secret).IsOK())
+	require.Equal(t, bob, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:ticket:0").Holder)
+	require.Equal(t, int64(40), bvsApp.accountMapper.GetAccount(ctx, bobAddr).GetCoins().AmountOf("bvs").Int64())
+	require.Nil(t, bvsApp.htlcKeeper.GetHTLC(ctx, id))
+
+	// after the timeout only the sender can get the assets back
+	bvsApp.voucherMapper.SetVoucher(ctx, &types.Voucher{Id: "0:v:ticket:0", Origin: "0:c:ticket", Holder: alice})
+	id = create()
+	ctx = ctx.WithBlockHeight(10)
+	require.False(t, claimHTLC(id, secret).IsOK())
+	require.True(t, refund(id).IsOK())
+	require.Equal(t, alice, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:ticket:0").Holder)
+	require.Equal(t, int64(60), bvsApp.accountMapper.GetAccount(ctx, aliceAddr).GetCoins().AmountOf("bvs").Int64())
+}
+
+func TestRefund(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	buyerAcc, buyerAddr, buyer := newTestUser(t, "")
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{buyerAcc, ownerAcc},
+		Codices: []*types.Codex{{Id: "0:c:class", Owner: owner, UnitPrice: 30, RefundWindow: 5,
+			CountLive: 3, Deposit: 100, Coins: silver(100)}},
+		Vouchers: []*types.Voucher{
+			{Id: "0:v:class:0", Origin: "0:c:class", Holder: buyer, IssuedOn: 1},
+			{Id: "0:v:class:1", Origin: "0:c:class", Holder: buyer, IssuedOn: 1},
+			{Id: "0:v:class:2", Origin: "0:c:class", Holder: buyer, IssuedOn: 1},
+		},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 6})
+	handler := refund.NewHandler(bvsApp.refundKeeper)
+	request := func(id string) sdk.Result {
+		return handler(ctx, &refund.MsgRequestRefund{HolderAccount: buyerAddr, Holder: buyer, Voucher: id})
+	}
+	buyerSilver := func() int64 {
+		return bvsApp.accountMapper.GetAccount(ctx, buyerAddr).GetCoins().AmountOf("bvs").Int64()
+	}
+
+	// approved by the owner
+	res := request("0:v:class:0")
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, string(res.Data), bvsApp.voucherMapper.GetVoucher(ctx, "0:v:class:0").Holder)
+	res = handler(ctx, &refund.MsgApproveRefund{OwnerAccount: ownerAddr, Owner: owner, Voucher: "0:v:class:0"})
+	require.True(t, res.IsOK(), res.Log)
+	require.Nil(t, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:class:0"))
+	require.Equal(t, int64(30), buyerSilver())
+
+	// rejected by the owner
+	require.True(t, request("0:v:class:1").IsOK())
+	res = handler(ctx, &refund.MsgRejectRefund{OwnerAccount: ownerAddr, Owner: owner, Voucher: "0:v:class:1"})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, buyer, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:class:1").Holder)
+
+	// left unanswered past the deadline
+	require.True(t, request("0:v:class:2").IsOK())
+	bvsApp.EndBlocker(ctx.WithBlockHeight(6+refund.ResponsePeriod), abci.RequestEndBlock{})
+	require.NotNil(t, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:class:2"))
+	bvsApp.EndBlocker(ctx.WithBlockHeight(7+refund.ResponsePeriod), abci.RequestEndBlock{})
+	require.Nil(t, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:class:2"))
+	require.Equal(t, int64(60), buyerSilver())
+	require.Equal(t, 1, bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:class").CountLive)
+
+	// neither a stale deadline nor a voucher which cannot be returned halts
+	// the chain
+	store := ctx.KVStore(bvsApp.keyRefund)
+	stale := append(types.RefundByDeadlinePrefix(6+refund.ResponsePeriod), []byte("refund-99")...)
+	store.Set(stale, []byte("refund-99"))
+	res = request("0:v:class:1")
+	require.True(t, res.IsOK(), res.Log)
+	cod := bvsApp.codexMapper.GetCodex(ctx, "0:c:class")
+	cod.Coins, cod.Deposit = nil, 0
+	bvsApp.codexMapper.SetCodex(ctx, cod)
+	vou := bvsApp.voucherMapper.GetVoucher(ctx, "0:v:class:1")
+	vou.Holder = owner
+	bvsApp.voucherMapper.SetVoucher(ctx, vou)
+	require.NotPanics(t, func() {
+		bvsApp.EndBlocker(ctx.WithBlockHeight(7+refund.ResponsePeriod), abci.RequestEndBlock{})
+	})
+	require.False(t, store.Has(stale))
+	require.Nil(t, bvsApp.refundKeeper.GetRequest(ctx, string(res.Data)))
+	vou.Holder = buyer
+	bvsApp.voucherMapper.SetVoucher(ctx, vou)
+
+	// the window is closed
+	ctx = ctx.WithBlockHeight(7)
+	require.False(t, request("0:v:class:1").IsOK())
+}
+
+func TestDispute(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	holderAcc, holderAddr, holder := newTestUser(t, "")
+	arbiterAcc, arbiterAddr, arbiter := newTestUser(t, "")
+	ownerAcc, _, owner := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{holderAcc, arbiterAcc, ownerAcc},
+		Codices: []*types.Codex{
+			{Id: "0:c:spa", Owner: owner, CountLive: 3, Deposit: 50, Unearned: 20, EscrowProceeds: true, Coins: silver(70)},
+		},
+		Vouchers: []*types.Voucher{
+			{Id: "0:v:spa:0", Origin: "0:c:spa", Holder: holder},
+			{Id: "0:v:spa:1", Origin: "0:c:spa", Holder: holder, Proceeds: 20},
+			{Id: "0:v:spa:2", Origin: "0:c:spa", Holder: holder},
+		},
+		Arbiters: []string{arbiter},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := dispute.NewHandler(bvsApp.disputeKeeper)
+	open := func(id string) string {
+		res := handler(ctx, &dispute.MsgOpenDispute{HolderAccount: holderAddr, Holder: holder, Voucher: id, Reason: "refused"})
+		require.True(t, res.IsOK(), res.Log)
+		return string(res.Data)
+	}
+	rule := func(signer sdk.AccAddress, id string, forHolder bool, amt int64) sdk.Result {
+		return handler(ctx, &dispute.MsgRuleDispute{
+			ArbiterAccount: signer, Arbiter: types.UserId(signer), Dispute: id, ForHolder: forHolder, Compensation: silver(amt),
+		})
+	}
+
+	d0 := open("0:v:spa:0")
+	require.Equal(t, d0, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:spa:0").Holder)
+	require.False(t, rule(holderAddr, d0, true, 10).IsOK())
+	res := rule(arbiterAddr, d0, false, 0)
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, holder, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:spa:0").Holder)
+	require.Equal(t, types.DisputeForMerchant, bvsApp.disputeKeeper.GetDispute(ctx, d0).Outcome)
+	require.False(t, rule(arbiterAddr, d0, true, 10).IsOK())
+
+	// compensation is capped by the deposit, which leaves out the escrowed
+	// proceeds paid back on top of it
+	d1 := open("0:v:spa:1")
+	res = rule(arbiterAddr, d1, true, 80)
+	require.True(t, res.IsOK(), res.Log)
+	require.Nil(t, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:spa:1"))
+	require.Equal(t, silver(70), bvsApp.accountMapper.GetAccount(ctx, holderAddr).GetCoins())
+	require.Equal(t, 0, bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:spa").Deposit)
+	require.Equal(t, silver(50), bvsApp.disputeKeeper.GetDispute(ctx, d1).Compensation)
+
+	// nothing but silver is paid, even bypassing the message checks
+	d2 := open("0:v:spa:0")
+	_, sdkErr := bvsApp.disputeKeeper.RuleDispute(ctx, arbiter, d2, true, sdk.Coins{sdk.NewInt64Coin(types.DenomGold, 5)}, "")
+	require.NotNil(t, sdkErr)
+	require.Equal(t, d2, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:spa:0").Holder)
+
+	disputes, arbiters := dispute.WriteGenesis(ctx, bvsApp.disputeKeeper)
+	require.Len(t, disputes, 3)
+	require.Equal(t, []string{arbiter}, arbiters)
+
+	// a voucher nobody may rule on is not frozen
+	bvsApp.disputeKeeper.SetArbiters(ctx, nil)
+	res = handler(ctx, &dispute.MsgOpenDispute{HolderAccount: holderAddr, Holder: holder, Voucher: "0:v:spa:2", Reason: "refused"})
+	require.False(t, res.IsOK())
+	require.Equal(t, holder, bvsApp.voucherMapper.GetVoucher(ctx, "0:v:spa:2").Holder)
+}
+
+func TestGuarantee(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, alice := newTestUser(t, "")
+	bobAcc, bobAddr, bob := newTestUser(t, "")
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc, ownerAcc},
+		Codices: []*types.Codex{
+			{Id: "0:c:gym", Owner: owner, CountAvail: 5, CountLive: 3, Deposit: 100, Coins: silver(100)},
+			{Id: "0:c:cafe", Owner: owner, CountLive: 1, Deposit: 10, Coins: silver(10), LastActive: 1},
+			{Id: "0:c:yoga", Owner: owner, CountAvail: 90, CountLive: 2, Deposit: 9200, Coins: silver(9200)},
+			{Id: "0:c:spa", Owner: owner, CountLive: 2, Deposit: 100, Coins: silver(100)},
+			{Id: "0:c:spa:x", Owner: owner, CountLive: 1},
+		},
+		Vouchers: []*types.Voucher{
+			{Id: "0:v:spa:0", Origin: "0:c:spa", Holder: alice},
+			{Id: "0:v:spa:1", Origin: "0:c:spa", Holder: alice},
+			{Id: "0:v:spa:x:0", Origin: "0:c:spa:x", Holder: alice},
+			{Id: "0:v:yoga:0", Origin: "0:c:yoga", Holder: alice},
+			{Id: "0:v:yoga:1", Origin: "0:c:yoga", Holder: bob},
+			{Id: "0:v:gym:0", Origin: "0:c:gym", Holder: alice},
+			{Id: "0:v:gym:1", Origin: "0:c:gym", Holder: bob},
+			{Id: "0:v:gym:2", Origin: "0:c:gym", Holder: bob, ExpireOn: 1},
+			{Id: "0:v:cafe:0", Origin: "0:c:cafe", Holder: alice},
+		},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 2})
+	handler := guarantee.NewHandler(bvsApp.guaranteeKeeper)
+	claimComp := func(signer sdk.AccAddress, id string) sdk.Result {
+		return handler(ctx, &guarantee.MsgClaimCompensation{HolderAccount: signer, Holder: types.UserId(signer), Voucher: id})
+	}
+
+	require.False(t, claimComp(aliceAddr, "0:v:gym:0").IsOK())
+
+	// the owner cannot take out the share of the deposit backing the vouchers
+	// sold, 3/8 of it, nor any of it once the codex is settled
+	shopHandler := shop.NewHandler(bvsApp.shopKeeper)
+	require.False(t, shopHandler(ctx, shop.BuildWithdrawMsg(ownerAddr, owner, "0:c:gym", silver(64), owner)).IsOK())
+	res := handler(ctx, &guarantee.MsgCloseCodex{OwnerAccount: ownerAddr, Owner: owner, Codex: "0:c:gym"})
+	require.True(t, res.IsOK(), res.Log)
+	require.False(t, shopHandler(ctx, shop.BuildWithdrawMsg(ownerAddr, owner, "0:c:gym", silver(1), owner)).IsOK())
+	cod := bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:gym")
+	require.Equal(t, types.CodexClosed, cod.Status)
+	require.Equal(t, 50, cod.Share) // the expired voucher is owed nothing
+	require.Equal(t, 0, cod.CountAvail)
+
+	// withdrawals retire the vouchers for sale they backed, so that the
+	// backing of those sold does not shrink with each of them
+	withdraw := func(amt int64) sdk.Result {
+		return shopHandler(ctx, shop.BuildWithdrawMsg(ownerAddr, owner, "0:c:yoga", silver(amt), owner))
+	}
+	require.True(t, withdraw(4500).IsOK())
+	require.Equal(t, 45, bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:yoga").CountAvail)
+	require.True(t, withdraw(4500).IsOK())
+	require.False(t, withdraw(1).IsOK())
+	cod = bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:yoga")
+	require.Equal(t, 0, cod.CountAvail)
+	require.Equal(t, 200, cod.Deposit)
+
+	require.True(t, claimComp(aliceAddr, "0:v:gym:0").IsOK())
+	require.True(t, claimComp(bobAddr, "0:v:gym:1").IsOK())
+	require.False(t, claimComp(bobAddr, "0:v:gym:2").IsOK())
+	require.Equal(t, silver(50), bvsApp.accountMapper.GetAccount(ctx, bobAddr).GetCoins())
+
+	// a codex without owner activity is settled on the first claim
+	require.False(t, claimComp(aliceAddr, "0:v:cafe:0").IsOK())
+	ctx = ctx.WithBlockHeight(2 + guarantee.AbandonPeriod)
+	require.True(t, claimComp(aliceAddr, "0:v:cafe:0").IsOK())
+	require.Equal(t, types.CodexAbandoned, bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:cafe").Status)
+	require.Equal(t, silver(60), bvsApp.accountMapper.GetAccount(ctx, aliceAddr).GetCoins())
+
+	// a voucher in escrow when the codex is settled keeps its share for
+	// whoever it is released to, while the vouchers of another codex do not
+	// count
+	asset := types.BvsAsset{Vouchers: []string{"0:v:spa:1"}}
+	res = shopHandler(ctx, shop.BuildPendingBvsMsg(aliceAddr, alice, bob, &asset, 5))
+	require.True(t, res.IsOK(), res.Log)
+	pending := string(res.Data)
+	res = handler(ctx, &guarantee.MsgCloseCodex{OwnerAccount: ownerAddr, Owner: owner, Codex: "0:c:spa"})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, 50, bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:spa").Share)
+	res = shopHandler(ctx, shop.BuildAcceptTransferMsg(bobAddr, bob, pending))
+	require.True(t, res.IsOK(), res.Log)
+	require.True(t, claimComp(bobAddr, "0:v:spa:1").IsOK())
+	require.Equal(t, silver(100), bvsApp.accountMapper.GetAccount(ctx, bobAddr).GetCoins())
+}
+
+func TestEscrowedProceeds(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	buyerAcc, buyerAddr, buyer := newTestUser(t, "100bvs")
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{buyerAcc, ownerAcc},
+		Codices: []*types.Codex{
+			{Id: "0:c:show", Owner: owner, UnitPrice: 30, CountAvail: 5, ExpireAfter: 10,
+				EscrowProceeds: true, Breakage: types.BreakageRefund, Deposit: 20, Coins: silver(20)},
+		},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := shop.NewHandler(bvsApp.shopKeeper)
+	buy := func() string {
+		res := handler(ctx, shop.BuildBvsMsg(buyerAddr, buyer, "0:c:show", &types.BvsAsset{Coins: silver(30)}))
+		require.True(t, res.IsOK(), res.Log)
+		return string(res.Data)
+	}
+	codex := func() *types.Codex { return bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:show") }
+	coinsOf := func(addr sdk.AccAddress) sdk.Coins { return bvsApp.accountMapper.GetAccount(ctx, addr).GetCoins() }
+
+	v0, v1 := buy(), buy()
+	require.Equal(t, 60, codex().Unearned)
+	require.Equal(t, 20, codex().Deposit)
+	require.Nil(t, coinsOf(ownerAddr))
+
+	// unearned proceeds cannot be taken out of the codex
+	_, err = bvsApp.shopKeeper.SubtractCoins(ctx, "0:c:show", silver(30))
+	require.NotNil(t, err)
+
+	res := handler(ctx, shop.BuildBvsMsg(buyerAddr, buyer, "0:c:show", &types.BvsAsset{Vouchers: []string{v0}}))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(30), coinsOf(ownerAddr))
+	require.Equal(t, 30, codex().Unearned)
+
+	require.False(t, handler(ctx, shop.BuildSettleExpiredMsg(ownerAddr, owner, v1)).IsOK())
+	ctx = ctx.WithBlockHeight(12)
+	res = handler(ctx, shop.BuildSettleExpiredMsg(ownerAddr, owner, v1))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(70), coinsOf(buyerAddr))
+	require.Equal(t, 0, codex().Unearned)
+	require.Equal(t, silver(20), codex().Coins)
+}
+
+func TestMultisig(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, alice := newTestUser(t, "")
+	bobAcc, bobAddr, bob := newTestUser(t, "")
+	carolAcc, carolAddr, carol := newTestUser(t, "")
+	daveAcc, daveAddr, dave := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc, carolAcc, daveAcc},
+		Codices: []*types.Codex{
+			{Id: "0:c:firm", Owner: alice, CountAvail: 1, Deposit: 100, Coins: silver(100)},
+		},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := multisig.NewHandler(bvsApp.multisigKeeper)
+	shopHandler := shop.NewHandler(bvsApp.shopKeeper)
+	codex := func() *types.Codex { return bvsApp.shopKeeper.CodexMapper().GetCodex(ctx, "0:c:firm") }
+	approve := func(signer sdk.AccAddress, id string) sdk.Result {
+		return handler(ctx, &multisig.MsgApproveProposal{ApproverAccount: signer, Approver: types.UserId(signer), Proposal: id})
+	}
+
+	res := handler(ctx, &multisig.MsgCreateMultisig{CreatorAccount: aliceAddr, Creator: alice,
+		Members: []string{alice, bob, carol}, Threshold: 2})
+	require.True(t, res.IsOK(), res.Log)
+	ms := string(res.Data)
+	res = shopHandler(ctx, shop.BuildTransferOwnershipMsg(aliceAddr, alice, "0:c:firm", ms))
+	require.True(t, res.IsOK(), res.Log)
+
+	// the former owner can no longer act alone
+	require.False(t, shopHandler(ctx, shop.BuildWithdrawMsg(aliceAddr, alice, "0:c:firm", silver(40), alice)).IsOK())
+
+	res = handler(ctx, &multisig.MsgPropose{ProposerAccount: aliceAddr, Proposer: alice, Multisig: ms,
+		Msg: shop.BuildWithdrawMsg(nil, ms, "0:c:firm", silver(40), dave)})
+	require.True(t, res.IsOK(), res.Log)
+	withdrawal := string(res.Data)
+	require.Equal(t, []string{alice}, bvsApp.multisigKeeper.GetProposal(ctx, withdrawal).Approvals)
+	require.False(t, approve(aliceAddr, withdrawal).IsOK())
+	require.False(t, approve(daveAddr, withdrawal).IsOK())
+	require.Equal(t, silver(100), codex().Coins)
+
+	res = approve(bobAddr, withdrawal)
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(60), codex().Coins)
+	require.Equal(t, silver(40), bvsApp.accountMapper.GetAccount(ctx, daveAddr).GetCoins())
+	require.Nil(t, bvsApp.multisigKeeper.GetProposal(ctx, withdrawal))
+
+	// messages not sent by the multisig cannot be proposed
+	res = handler(ctx, &multisig.MsgPropose{ProposerAccount: aliceAddr, Proposer: alice, Multisig: ms,
+		Msg: shop.BuildRestockMsg(nil, alice, "0:c:firm", 5)})
+	require.False(t, res.IsOK())
+
+	// nor can messages which would be invalid if signed
+	res = handler(ctx, &multisig.MsgPropose{ProposerAccount: aliceAddr, Proposer: alice, Multisig: ms,
+		Msg: shop.BuildWithdrawMsg(nil, ms, "0:c:firm", silver(-40), dave)})
+	require.False(t, res.IsOK())
+	res = handler(ctx, &multisig.MsgPropose{ProposerAccount: aliceAddr, Proposer: alice, Multisig: ms,
+		Msg: shop.BuildRestockMsg(nil, ms, "0:c:firm", -5)})
+	require.False(t, res.IsOK())
+	_, err = bvsApp.shopKeeper.Withdraw(ctx, ms, "0:c:firm", silver(-40), dave)
+	require.NotNil(t, err)
+	_, err = bvsApp.shopKeeper.Restock(ctx, ms, "0:c:firm", -5)
+	require.NotNil(t, err)
+	require.Equal(t, silver(40), bvsApp.accountMapper.GetAccount(ctx, daveAddr).GetCoins())
+
+	// approvals expire
+	res = handler(ctx, &multisig.MsgPropose{ProposerAccount: carolAddr, Proposer: carol, Multisig: ms,
+		Msg: shop.BuildRestockMsg(nil, ms, "0:c:firm", 5), ExpireAfter: 10})
+	require.True(t, res.IsOK(), res.Log)
+	restock := string(res.Data)
+	ctx = ctx.WithBlockHeight(12)
+	require.False(t, approve(bobAddr, restock).IsOK())
+	multisig.EndBlocker(ctx, bvsApp.multisigKeeper)
+	require.Nil(t, bvsApp.multisigKeeper.GetProposal(ctx, restock))
+	require.Equal(t, 1, codex().CountAvail)
+
+	// a codex owned by a multisig is paid through the codex itself
+	require.Equal(t, ms, codex().Owner)
+	require.Equal(t, "0:c:firm", bvsApp.shopKeeper.Payee(codex()))
+}
+
+func TestRoyalty(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	sellerAcc, sellerAddr, seller := newTestUser(t, "")
+	buyerAcc, buyerAddr, buyer := newTestUser(t, "100bvs")
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{sellerAcc, buyerAcc, ownerAcc},
+		Codices: []*types.Codex{
+			{Id: "0:c:show", Owner: owner, CountLive: 2, Royalty: 10},
+		},
+		Vouchers: []*types.Voucher{
+			{Id: "0:v:show:0", Origin: "0:c:show", Holder: seller},
+			{Id: "0:v:show:1", Origin: "0:c:show", Holder: seller},
+		},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	coinsOf := func(addr sdk.AccAddress) sdk.Coins { return bvsApp.accountMapper.GetAccount(ctx, addr).GetCoins() }
+	tagValue := func(tags sdk.Tags, key string) string {
+		for _, tag := range tags {
+			if string(tag.Key) == key {
+				return string(tag.Value)
+			}
+		}
+		return ""
+	}
+
+	tags, err := bvsApp.shopKeeper.SellVoucher(ctx, seller, seller, buyer, "0:v:show:0", silver(55))
+	require.Nil(t, err)
+	require.Equal(t, "sale", tagValue(tags, "action"))
+	require.Equal(t, "5bvs", tagValue(tags, "royalty"))
+	require.Equal(t, owner, tagValue(tags, "royalty-to"))
+	require.Equal(t, silver(5), coinsOf(ownerAddr))
+	require.Equal(t, silver(50), coinsOf(sellerAddr))
+	require.Equal(t, buyer, bvsApp.shopKeeper.VoucherMapper().GetVoucher(ctx, "0:v:show:0").Holder)
+
+	// the buyer cannot pay for a second one
+	_, err = bvsApp.shopKeeper.SellVoucher(ctx, seller, seller, buyer, "0:v:show:1", silver(55))
+	require.NotNil(t, err)
+
+	// royalties out of range or owed to anyone but a user are refused
+	cod := bvsApp.codexMapper.GetCodex(ctx, "0:c:show")
+	cod.Royalty = 150
+	bvsApp.codexMapper.SetCodex(ctx, cod)
+	_, err = bvsApp.shopKeeper.SellVoucher(ctx, seller, seller, buyer, "0:v:show:1", silver(10))
+	require.NotNil(t, err)
+	cod.Royalty, cod.RoyaltyTo = 10, "0:c:show"
+	bvsApp.codexMapper.SetCodex(ctx, cod)
+	_, err = bvsApp.shopKeeper.SellVoucher(ctx, seller, seller, buyer, "0:v:show:1", silver(10))
+	require.NotNil(t, err)
+	cod.RoyaltyTo = ""
+	bvsApp.codexMapper.SetCodex(ctx, cod)
+	require.Equal(t, silver(45), coinsOf(buyerAddr))
+
+	// gifts owe no royalty
+	res := shop.NewHandler(bvsApp.shopKeeper)(ctx, shop.BuildBvsMsg(sellerAddr, seller, buyer,
+		&types.BvsAsset{Vouchers: []string{"0:v:show:1"}}))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, "gift", tagValue(res.Tags, "action"))
+	require.Equal(t, "", tagValue(res.Tags, "royalty"))
+	require.Equal(t, silver(5), coinsOf(ownerAddr))
+	require.Equal(t, silver(45), coinsOf(buyerAddr))
+}
+
+func TestMarket(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	sellerAcc, sellerAddr, seller := newTestUser(t, "")
+	buyerAcc, buyerAddr, buyer := newTestUser(t, "100bvs")
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{sellerAcc, buyerAcc, ownerAcc},
+		Codices: []*types.Codex{
+			{Id: "0:c:show", Owner: owner, CountLive: 2, Royalty: 10},
+			{Id: "0:c:pass", Owner: owner, CountLive: 1, Transfer: types.TransferNone},
+		},
+		Vouchers: []*types.Voucher{
+			{Id: "0:v:show:0", Origin: "0:c:show", Holder: seller},
+			{Id: "0:v:show:1", Origin: "0:c:show", Holder: seller},
+			{Id: "0:v:pass:0", Origin: "0:c:pass", Holder: seller},
+		},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := market.NewHandler(bvsApp.marketKeeper)
+	holderOf := func(id string) string { return bvsApp.shopKeeper.VoucherMapper().GetVoucher(ctx, id).Holder }
+	coinsOf := func(addr sdk.AccAddress) sdk.Coins { return bvsApp.accountMapper.GetAccount(ctx, addr).GetCoins() }
+	list := func(id string, price int64) sdk.Result {
+		return handler(ctx, &market.MsgList{SellerAccount: sellerAddr, Seller: seller, Voucher: id, Price: sdk.NewInt64Coin("bvs", price)})
+	}
+
+	require.False(t, list("0:v:pass:0", 10).IsOK())
+	huge := &market.MsgList{SellerAccount: sellerAddr, Seller: seller, Voucher: "0:v:show:0", Price: sdk.NewCoin("bvs", sdk.NewIntWithDecimal(1, 30))}
+	require.NotNil(t, huge.ValidateBasic())
+	res := list("0:v:show:0", 40)
+	require.True(t, res.IsOK(), res.Log)
+	sale := string(res.Data)
+	res = list("0:v:show:1", 20)
+	require.True(t, res.IsOK(), res.Log)
+	unsold := string(res.Data)
+	require.Equal(t, sale, holderOf("0:v:show:0"))
+
+	// listings are indexed by codex and by price
+	var listings []*types.Listing
+	bvsApp.marketKeeper.IterateListings(ctx, func(l *types.Listing) bool {
+		listings = append(listings, l)
+		return false
+	})
+	require.Len(t, listings, 2)
+	store := ctx.KVStore(bvsApp.keyMarket)
+	iter := sdk.KVStorePrefixIterator(store, types.ListingByPricePrefix("bvs"))
+	require.Equal(t, unsold, string(iter.Value()))
+	iter.Close()
+	iter = sdk.KVStorePrefixIterator(store, types.ListingByCodexPrefix("0:c:show"))
+	count := 0
+	for ; iter.Valid(); iter.Next() {
+		count++
+	}
+	iter.Close()
+	require.Equal(t, 2, count)
+
+	res = handler(ctx, &market.MsgBuy{BuyerAccount: buyerAddr, Buyer: buyer, Listing: sale})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, buyer, holderOf("0:v:show:0"))
+	require.Equal(t, silver(60), coinsOf(buyerAddr))
+	require.Equal(t, silver(36), coinsOf(sellerAddr))
+	require.Equal(t, silver(4), coinsOf(ownerAddr))
+	require.Nil(t, bvsApp.marketKeeper.GetListing(ctx, sale))
+	require.False(t, handler(ctx, &market.MsgBuy{BuyerAccount: buyerAddr, Buyer: buyer, Listing: sale}).IsOK())
+
+	require.False(t, handler(ctx, &market.MsgDelist{SellerAccount: buyerAddr, Seller: buyer, Listing: unsold}).IsOK())
+	res = handler(ctx, &market.MsgDelist{SellerAccount: sellerAddr, Seller: seller, Listing: unsold})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, seller, holderOf("0:v:show:1"))
+}
+
+func TestOrderBook(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	sellerAcc, sellerAddr, seller := newTestUser(t, "")
+	aliceAcc, aliceAddr, alice := newTestUser(t, "100bvs")
+	bobAcc, bobAddr, bob := newTestUser(t, "100bvs")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{sellerAcc, aliceAcc, bobAcc},
+		Codices: []*types.Codex{
+			{Id: "0:c:gym", CountLive: 4},
+			{Id: "0:c:card", CountLive: 1, StoredValue: true},
+		},
+		Vouchers: []*types.Voucher{
+			{Id: "0:v:gym:0", Origin: "0:c:gym", Holder: seller},
+			{Id: "0:v:gym:1", Origin: "0:c:gym", Holder: seller},
+			{Id: "0:v:gym:2", Origin: "0:c:gym", Holder: seller},
+			{Id: "0:v:gym:3", Origin: "0:c:gym", Holder: seller},
+			{Id: "0:v:card:0", Origin: "0:c:card", Holder: seller},
+		},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := market.NewHandler(bvsApp.marketKeeper)
+	holderOf := func(id string) string { return bvsApp.shopKeeper.VoucherMapper().GetVoucher(ctx, id).Holder }
+	coinsOf := func(addr sdk.AccAddress) sdk.Coins { return bvsApp.accountMapper.GetAccount(ctx, addr).GetCoins() }
+	ask := func(codex string, price int64, vouchers ...string) sdk.Result {
+		return handler(ctx, &market.MsgPlaceOrder{TraderAccount: sellerAddr, Trader: seller, Codex: codex,
+			Side: types.OrderAsk, Price: sdk.NewInt64Coin("bvs", price), Vouchers: vouchers})
+	}
+	bid := func(addr sdk.AccAddress, price int64, quantity int) string {
+		res := handler(ctx, &market.MsgPlaceOrder{TraderAccount: addr, Trader: types.UserId(addr), Codex: "0:c:gym",
+			Side: types.OrderBid, Price: sdk.NewInt64Coin("bvs", price), Quantity: quantity})
+		require.True(t, res.IsOK(), res.Log)
+		return string(res.Data)
+	}
+
+	require.False(t, ask("0:c:card", 20, "0:v:card:0").IsOK())
+	huge := &market.MsgPlaceOrder{TraderAccount: aliceAddr, Trader: alice, Codex: "0:c:gym",
+		Side: types.OrderBid, Price: sdk.NewCoin("bvs", sdk.NewIntWithDecimal(1, 30)), Quantity: 1}
+	require.NotNil(t, huge.ValidateBasic())
+	res := ask("0:c:gym", 20, "0:v:gym:0", "0:v:gym:1", "0:v:gym:2")
+	require.True(t, res.IsOK(), res.Log)
+	sell := string(res.Data)
+
+	// the bid crosses the resting ask and trades at the ask price
+	bid(aliceAddr, 25, 2)
+	require.Equal(t, silver(50), coinsOf(aliceAddr))
+	market.EndBlocker(ctx, bvsApp.marketKeeper)
+	require.Equal(t, alice, holderOf("0:v:gym:0"))
+	require.Equal(t, alice, holderOf("0:v:gym:1"))
+	require.Equal(t, silver(60), coinsOf(aliceAddr))
+	require.Equal(t, silver(40), coinsOf(sellerAddr))
+	require.Equal(t, 1, bvsApp.marketKeeper.GetOrder(ctx, sell).Quantity)
+
+	// a partially filled bid rests in the book until cancelled
+	rest := bid(bobAddr, 20, 3)
+	market.EndBlocker(ctx, bvsApp.marketKeeper)
+	require.Equal(t, bob, holderOf("0:v:gym:2"))
+	require.Nil(t, bvsApp.marketKeeper.GetOrder(ctx, sell))
+	o := bvsApp.marketKeeper.GetOrder(ctx, rest)
+	require.Equal(t, 2, o.Quantity)
+	require.Equal(t, 1, o.Filled)
+	require.Equal(t, silver(40), o.Escrow)
+	require.False(t, handler(ctx, &market.MsgCancelOrder{TraderAccount: aliceAddr, Trader: alice, Order: rest}).IsOK())
+	res = handler(ctx, &market.MsgCancelOrder{TraderAccount: bobAddr, Trader: bob, Order: rest})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(80), coinsOf(bobAddr))
+
+	// orders expire
+	res = handler(ctx, &market.MsgPlaceOrder{TraderAccount: sellerAddr, Trader: seller, Codex: "0:c:gym",
+		Side: types.OrderAsk, Price: sdk.NewInt64Coin("bvs", 30), Vouchers: []string{"0:v:gym:3"}, ExpireAfter: 5})
+	require.True(t, res.IsOK(), res.Log)
+	ctx = ctx.WithBlockHeight(7)
+	market.EndBlocker(ctx, bvsApp.marketKeeper)
+	require.Nil(t, bvsApp.marketKeeper.GetOrder(ctx, string(res.Data)))
+	require.Equal(t, seller, holderOf("0:v:gym:3"))
+
+	// an order whose assets cannot be handed back is dropped at expiry
+	// rather than halting the chain
+	res = handler(ctx, &market.MsgPlaceOrder{TraderAccount: sellerAddr, Trader: seller, Codex: "0:c:gym",
+		Side: types.OrderAsk, Price: sdk.NewInt64Coin("bvs", 30), Vouchers: []string{"0:v:gym:3"}, ExpireAfter: 5})
+	require.True(t, res.IsOK(), res.Log)
+	vou := bvsApp.voucherMapper.GetVoucher(ctx, "0:v:gym:3")
+	vou.Holder = alice
+	bvsApp.voucherMapper.SetVoucher(ctx, vou)
+	ctx = ctx.WithBlockHeight(13)
+	require.NotPanics(t, func() { market.EndBlocker(ctx, bvsApp.marketKeeper) })
+	require.Nil(t, bvsApp.marketKeeper.GetOrder(ctx, string(res.Data)))
+	require.Equal(t, alice, holderOf("0:v:gym:3"))
+}
+
+func TestAMM(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, alice := newTestUser(t, "1000bvg,1000bvs")
+	bobAcc, bobAddr, bob := newTestUser(t, "100bvs")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := amm.NewHandler(bvsApp.ammKeeper)
+	coinsOf := func(addr sdk.AccAddress) sdk.Coins { return bvsApp.accountMapper.GetAccount(ctx, addr).GetCoins() }
+	swap := func(min int64) sdk.Result {
+		return handler(ctx, &amm.MsgSwap{TraderAccount: bobAddr, Trader: bob, Offer: sdk.NewInt64Coin("bvs", 100), MinReceive: sdk.NewInt(min)})
+	}
+
+	require.False(t, swap(0).IsOK())
+	res := handler(ctx, &amm.MsgAddLiquidity{ProviderAccount: aliceAddr, Provider: alice, Silver: sdk.NewInt(1000), MaxGold: sdk.NewInt(500)})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, "1000", string(res.Data))
+
+	// 100 silver less the fee buys 500 * 99.7 / 1099.7 gold
+	require.False(t, swap(46).IsOK())
+	res = swap(45)
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvg", 45)}, coinsOf(bobAddr))
+
+	qres := bvsApp.Query(abci.RequestQuery{Path: "/custom/amm/pool"})
+	require.Equal(t, uint32(sdk.ABCICodeOK), qres.Code, qres.Log)
+	pool := &types.Pool{}
+	require.Nil(t, bvsApp.cdc.UnmarshalJSON(qres.Value, pool))
+	require.Equal(t, sdk.NewInt(1100), pool.Silver)
+	require.Equal(t, sdk.NewInt(455), pool.Gold)
+
+	res = handler(ctx, &amm.MsgRemoveLiquidity{ProviderAccount: aliceAddr, Provider: alice, Shares: sdk.NewInt(1001), MinSilver: sdk.ZeroInt(), MinGold: sdk.ZeroInt()})
+	require.False(t, res.IsOK())
+	res = handler(ctx, &amm.MsgRemoveLiquidity{ProviderAccount: aliceAddr, Provider: alice, Shares: sdk.NewInt(1000), MinSilver: sdk.NewInt(1100), MinGold: sdk.ZeroInt()})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvg", 955), sdk.NewInt64Coin("bvs", 1100)}, coinsOf(aliceAddr))
+	require.True(t, bvsApp.ammKeeper.GetPool(ctx).IsEmpty())
+}
+
+func TestFees(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	priv := ed25519.GenPrivKey()
+	aliceAddr := sdk.AccAddress(priv.PubKey().Address())
+	aliceAcc := &types.GenesisAccount{Id: types.UserId(aliceAddr), Address: aliceAddr, Coins: sdk.Coins{sdk.NewInt64Coin("bvs", 1000)}}
+	bobAcc, bobAddr, bob := newTestUser(t, "")
+	carolAcc, carolAddr, carol := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc, carolAcc},
+		FeePolicy: &types.FeePolicy{MinFee: 10, CommunityShare: 10, Validators: []*types.FeeValidator{
+			{Payee: bob, Power: 2}, {Payee: carol, Power: 1},
+		}},
+	})
+	require.Nil(t, err)
+
+	sendTx := func(seq int64, amt int64) []byte {
+		msgs := []sdk.Msg{bank.NewMsgSend(
+			[]bank.Input{bank.NewInput(aliceAddr, sdk.Coins{sdk.NewInt64Coin("bvs", 1)})},
+			[]bank.Output{bank.NewOutput(bobAddr, sdk.Coins{sdk.NewInt64Coin("bvs", 1)})},
+		)}
+		stdFee := auth.NewStdFee(20000, sdk.NewInt64Coin("bvs", amt))
+		sig, err := priv.Sign(auth.StdSignBytes("", 0, seq, stdFee, msgs, ""))
+		require.Nil(t, err)
+		tx := auth.NewStdTx(msgs, stdFee, []auth.StdSignature{{PubKey: priv.PubKey(), Signature: sig, Sequence: seq}}, "")
+		return bvsApp.cdc.MustMarshalBinary(tx)
+	}
+
+	bvsApp.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 2}})
+	dres := bvsApp.DeliverTx(sendTx(0, 9))
+	require.Equal(t, fee.CodeInsufficientFee, sdk.CodeType(dres.Code&0xffff), dres.Log)
+	dres = bvsApp.DeliverTx(sendTx(0, 100))
+	require.True(t, dres.IsOK(), dres.Log)
+	bvsApp.EndBlock(abci.RequestEndBlock{Height: 2})
+	bvsApp.Commit()
+
+	// 90 silver go to the validators by power, the rest to the community
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 2})
+	coinsOf := func(addr sdk.AccAddress) sdk.Coins { return bvsApp.accountMapper.GetAccount(ctx, addr).GetCoins() }
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 899)}, coinsOf(aliceAddr))
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 61)}, coinsOf(bobAddr))
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 30)}, coinsOf(carolAddr))
+	require.True(t, bvsApp.feeCollectionKeeper.GetCollectedFees(ctx).IsZero())
+
+	qres := bvsApp.Query(abci.RequestQuery{Path: "/custom/fee/block/2"})
+	require.Equal(t, uint32(sdk.ABCICodeOK), qres.Code, qres.Log)
+	bf := &types.BlockFees{}
+	require.Nil(t, bvsApp.cdc.UnmarshalJSON(qres.Value, bf))
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 100)}, bf.Collected)
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 10)}, bf.Community)
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 10)}, bvsApp.feeKeeper.GetCommunityPool(ctx))
+}
+
+func TestStake(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, _ := newTestUser(t, "1000bvs")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	pk := ed25519.GenPrivKey().PubKey()
+	msg := stake.NewMsgCreateValidator(aliceAddr, pk, sdk.NewInt64Coin("bvs", 100), stake.NewDescription("alice", "", "", ""))
+	res := stake.NewHandler(bvsApp.stakeKeeper)(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 900)}, bvsApp.accountMapper.GetAccount(ctx, aliceAddr).GetCoins())
+
+	eres := bvsApp.EndBlocker(ctx, abci.RequestEndBlock{Height: 1})
+	require.Equal(t, 1, len(eres.ValidatorUpdates))
+	require.Equal(t, int64(100), eres.ValidatorUpdates[0].Power)
+
+	appState, validators, err := bvsApp.ExportAppStateAndValidators(false)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(validators))
+	require.Equal(t, pk, validators[0].PubKey)
+	require.Equal(t, int64(100), validators[0].Power)
+
+	// a chain restarted from the export has the same validator
+	restarted := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+	ires := restarted.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: appState})
+	require.Equal(t, 1, len(ires.Validators))
+	require.Equal(t, int64(100), ires.Validators[0].Power)
+	restarted.Commit()
+	_, validators, err = restarted.ExportAppStateAndValidators(false)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(validators))
+}
+
+func TestSlashing(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, _ := newTestUser(t, "1000bvs")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc},
+	})
+	require.Nil(t, err)
+
+	start := time.Unix(1000000, 0)
+	ctxAt := func(height int64, after time.Duration) sdk.Context {
+		return bvsApp.BaseApp.NewContext(true, abci.Header{Height: height, Time: start.Add(after)})
+	}
+	ctx := ctxAt(1, 0)
+	pk := ed25519.GenPrivKey().PubKey()
+	msg := stake.NewMsgCreateValidator(aliceAddr, pk, sdk.NewInt64Coin("bvs", 100), stake.NewDescription("alice", "", "", ""))
+	res := stake.NewHandler(bvsApp.stakeKeeper)(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+	bvsApp.EndBlocker(ctx, abci.RequestEndBlock{Height: 1})
+	bvsApp.paramsKeeper.Setter().SetInt64(ctx, slashing.SignedBlocksWindowKey, 10)
+
+	validator := func() stake.Validator {
+		v, found := bvsApp.stakeKeeper.GetValidator(ctx, aliceAddr)
+		require.True(t, found)
+		return v
+	}
+	signed := func(height int64, signed bool) {
+		bvsApp.BeginBlocker(ctxAt(height, 0), abci.RequestBeginBlock{LastCommitInfo: abci.LastCommitInfo{
+			Validators: []abci.SigningValidator{{Validator: abci.Validator{Address: pk.Address(), Power: 100}, SignedLastBlock: signed}},
+		}})
+	}
+
+	// missing more than half of the window jails the validator
+	for h := int64(2); h <= 12; h++ {
+		signed(h, false)
+		require.False(t, validator().Revoked)
+	}
+	signed(13, false)
+	require.True(t, validator().Revoked)
+	require.Equal(t, sdk.NewRat(99), validator().Tokens)
+
+	unjail := slashing.NewMsgUnrevoke(aliceAddr)
+	handler := slashing.NewHandler(bvsApp.slashingKeeper)
+	res = handler(ctxAt(14, time.Minute), unjail)
+	require.False(t, res.IsOK())
+	res = handler(ctxAt(14, 11*time.Minute), unjail)
+	require.True(t, res.IsOK(), res.Log)
+	require.False(t, validator().Revoked)
+
+	// signing twice jails it again with a heavier slash
+	bvsApp.BeginBlocker(ctxAt(15, 11*time.Minute), abci.RequestBeginBlock{ByzantineValidators: []abci.Evidence{{
+		Type:      tmtypes.ABCIEvidenceTypeDuplicateVote,
+		Validator: abci.Validator{Address: pk.Address(), Power: 99},
+		Height:    14,
+		Time:      start.Add(11 * time.Minute),
+	}}})
+	require.True(t, validator().Revoked)
+	require.True(t, validator().Tokens.LT(sdk.NewRat(99)))
+}
+
+func TestGovernance(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, alice := newTestUser(t, "1000bvs")
+	bobAcc, bobAddr, bob := newTestUser(t, "1000bvs")
+	carolAcc, carolAddr, carol := newTestUser(t, "1000bvs")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts:  []*types.GenesisAccount{aliceAcc, bobAcc, carolAcc},
+		GovParams: &types.GovParams{MinDeposit: sdk.Coins{sdk.NewInt64Coin("bvs", 100)}, DepositPeriod: 10, VotingPeriod: 10, Quorum: 33, Threshold: 50, Veto: 33},
+	})
+	require.Nil(t, err)
+
+	ctxAt := func(height int64) sdk.Context { return bvsApp.BaseApp.NewContext(true, abci.Header{Height: height}) }
+	ctx := ctxAt(1)
+	stakeHandler := stake.NewHandler(bvsApp.stakeKeeper)
+	res := stakeHandler(ctx, stake.NewMsgCreateValidator(aliceAddr, ed25519.GenPrivKey().PubKey(), sdk.NewInt64Coin("bvs", 100), stake.NewDescription("alice", "", "", "")))
+	require.True(t, res.IsOK(), res.Log)
+	res = stakeHandler(ctx, stake.NewMsgDelegate(bobAddr, aliceAddr, sdk.NewInt64Coin("bvs", 50)))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, sdk.NewRat(50), bvsApp.govKeeper.VotingPower(ctx, bob))
+
+	handler := gov.NewHandler(bvsApp.govKeeper)
+	submit := func(kind string, changes []types.ParamChange, upgrade *types.UpgradePlan, deposit int64) sdk.Result {
+		return handler(ctx, &gov.MsgSubmitProposal{ProposerAccount: aliceAddr, Proposer: alice, Kind: kind, Title: kind,
+			Changes: changes, Upgrade: upgrade, Deposit: sdk.Coins{sdk.NewInt64Coin("bvs", deposit)}})
+	}
+	vote := func(voterAddr sdk.AccAddress, voter string, id string, option string) sdk.Result {
+		return handler(ctx, &gov.MsgVote{VoterAccount: voterAddr, Voter: voter, Proposal: id, Option: option})
+	}
+
+	res = submit(types.ProposalParamChange, []types.ParamChange{{Key: "fee/max-fee", Value: "5"}}, nil, 50)
+	require.False(t, res.IsOK())
+	res = submit(types.ProposalParamChange, []types.ParamChange{{Key: "fee/min-fee", Value: "-5"}}, nil, 50)
+	require.False(t, res.IsOK())
+	res = submit(types.ProposalParamChange, []types.ParamChange{{Key: "fee/min-fee", Value: "5"}}, nil, 50)
+	require.True(t, res.IsOK(), res.Log)
+	change := string(res.Data)
+	require.False(t, vote(aliceAddr, alice, change, types.VoteYes).IsOK())
+	res = handler(ctx, &gov.MsgDeposit{DepositorAccount: bobAddr, Depositor: bob, Proposal: change, Amount: sdk.Coins{sdk.NewInt64Coin("bvs", 50)}})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, types.ProposalVotingPeriod, bvsApp.govKeeper.GetProposal(ctx, change).Status)
+
+	res = submit(types.ProposalText, nil, nil, 100)
+	require.True(t, res.IsOK(), res.Log)
+	text := string(res.Data)
+	res = submit(types.ProposalSoftwareUpgrade, nil, &types.UpgradePlan{Name: "v2", Height: 100}, 100)
+	require.True(t, res.IsOK(), res.Log)
+	upgrade := string(res.Data)
+
+	require.True(t, vote(aliceAddr, alice, change, types.VoteYes).IsOK())
+	require.True(t, vote(bobAddr, bob, change, types.VoteNo).IsOK())
+	require.False(t, vote(carolAddr, carol, change, types.VoteYes).IsOK())
+	require.True(t, vote(aliceAddr, alice, text, types.VoteNoWithVeto).IsOK())
+	require.True(t, vote(bobAddr, bob, upgrade, types.VoteYes).IsOK())
+	require.True(t, vote(aliceAddr, alice, upgrade, types.VoteAbstain).IsOK())
+
+	// the votes are tallied at the end of the voting period
+	gov.EndBlocker(ctxAt(10), bvsApp.govKeeper)
+	require.Equal(t, types.ProposalVotingPeriod, bvsApp.govKeeper.GetProposal(ctx, change).Status)
+	gov.EndBlocker(ctxAt(11), bvsApp.govKeeper)
+	require.Equal(t, types.ProposalPassed, bvsApp.govKeeper.GetProposal(ctx, change).Status)
+	require.Equal(t, int64(5), bvsApp.feeKeeper.GetPolicy(ctx).MinFee)
+	require.Equal(t, types.ProposalRejected, bvsApp.govKeeper.GetProposal(ctx, text).Status)
+	require.Equal(t, types.ProposalPassed, bvsApp.govKeeper.GetProposal(ctx, upgrade).Status)
+	require.Equal(t, &types.UpgradePlan{Name: "v2", Height: 100}, bvsApp.govKeeper.GetUpgradePlan(ctx))
+
+	// deposits are refunded, except on the vetoed proposal
+	coinsOf := func(addr sdk.AccAddress) sdk.Coins { return bvsApp.accountMapper.GetAccount(ctx, addr).GetCoins() }
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 800)}, coinsOf(aliceAddr))
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("bvs", 950)}, coinsOf(bobAddr))
+
+	appState, _, err := bvsApp.ExportAppStateAndValidators(false)
+	require.Nil(t, err)
+	genState := types.GenesisState{}
+	require.Nil(t, bvsApp.cdc.UnmarshalJSON(appState, &genState))
+	require.Equal(t, 3, len(genState.GovProposals))
+	require.Equal(t, 10, genState.GovParams.VotingPeriod)
+	require.Equal(t, "v2", genState.UpgradePlan.Name)
+}
+
+func TestParams(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	buyerAcc, buyerAddr, buyer := newTestUser(t, "100bvs")
+	ownerAcc, ownerAddr, owner := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Params: &types.BvsParams{
+			Shop:   types.ShopParams{MinDepositPerVoucher: 10, MaxExpireAfter: 100},
+			Refund: types.RefundParams{MinWindow: 5, MaxWindow: 20},
+			Market: types.MarketParams{AllowedDenoms: []string{"bvs"}},
+			AMM:    types.AMMParams{SwapFee: 3},
+		},
+		Accounts: []*types.GenesisAccount{buyerAcc, ownerAcc},
+		Codices: []*types.Codex{{Id: "0:c:class", Owner: owner, UnitPrice: 30, RefundWindow: 50,
+			CountAvail: 5, Deposit: 100, Coins: silver(100)}},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	handler := shop.NewHandler(bvsApp.shopKeeper)
+
+	// vouchers of a codex which never expire do after MaxExpireAfter
+	res := handler(ctx, shop.BuildBvsMsg(buyerAddr, buyer, "0:c:class", &types.BvsAsset{Coins: silver(30)}))
+	require.True(t, res.IsOK(), res.Log)
+	v0 := string(res.Data)
+	require.Equal(t, 101, bvsApp.voucherMapper.GetVoucher(ctx, v0).ExpireOn)
+
+	// the deposit must cover the live and available vouchers
+	restock := func(count int) sdk.Result {
+		return handler(ctx, &shop.MsgRestock{OwnerAccount: ownerAddr, Owner: owner, Codex: "0:c:class", Count: count})
+	}
+	require.False(t, restock(6).IsOK())
+	require.True(t, restock(5).IsOK())
+	res = handler(ctx, shop.BuildWithdrawMsg(ownerAddr, owner, "0:c:class", silver(1), owner))
+	require.False(t, res.IsOK())
+
+	// refund windows are bounded
+	cod := bvsApp.codexMapper.GetCodex(ctx, "0:c:class")
+	require.Equal(t, 20, bvsApp.refundKeeper.Window(ctx, cod))
+	cod.RefundWindow = 0
+	require.Equal(t, 5, bvsApp.refundKeeper.Window(ctx, cod))
+
+	// vouchers are priced in allowed denominations only
+	marketHandler := market.NewHandler(bvsApp.marketKeeper)
+	res = marketHandler(ctx, &market.MsgList{SellerAccount: buyerAddr, Seller: buyer, Voucher: v0, Price: sdk.NewInt64Coin("bvg", 10)})
+	require.False(t, res.IsOK())
+	res = marketHandler(ctx, &market.MsgList{SellerAccount: buyerAddr, Seller: buyer, Voucher: v0, Price: sdk.NewInt64Coin("bvs", 10)})
+	require.True(t, res.IsOK(), res.Log)
+
+	// the params change through governance only, and stay valid
+	ps := bvsApp.newParamStore()
+	require.NotNil(t, ps.ValidateParam(ctx, "market/allowed-denoms", `["foo"]`))
+	require.NotNil(t, ps.ValidateParam(ctx, "refund/min-window", "30"))
+	require.Nil(t, ps.SetParam(ctx, "market/allowed-denoms", `["bvs","bvg"]`))
+	require.Nil(t, ps.SetParam(ctx, "amm/swap-fee", "10"))
+	require.True(t, bvsApp.paramsMapper.GetParams(ctx).IsDenomAllowed("bvg"))
+	require.Equal(t, int64(10), bvsApp.ammKeeper.SwapFee(ctx))
+
+	appState, _, err := bvsApp.ExportAppStateAndValidators(false)
+	require.Nil(t, err)
+	genState := types.GenesisState{}
+	require.Nil(t, bvsApp.cdc.UnmarshalJSON(appState, &genState))
+	require.Equal(t, 10, genState.Params.Shop.MinDepositPerVoucher)
+}
+
+func TestUpgrade(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	ownerAcc, _, owner := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts:    []*types.GenesisAccount{ownerAcc},
+		UpgradePlan: &types.UpgradePlan{Name: "v2", Height: 5},
+	})
+	require.Nil(t, err)
+
+	ctxAt := func(height int64) sdk.Context { return bvsApp.BaseApp.NewContext(true, abci.Header{Height: height}) }
+	require.Equal(t, SchemaVersion, bvsApp.upgradeKeeper.GetSchemaVersion(ctxAt(1)))
+
+	// a codex record of the schema before the upgrade
+	type codexV1 struct {
+		Id        string
+		Owner     string
+		UnitPrice int
+	}
+	ctx := ctxAt(4)
+	ctx.KVStore(bvsApp.keyCodex).Set([]byte("codex:0:c:old"), bvsApp.cdc.MustMarshalBinaryBare(codexV1{"0:c:old", owner, 30}))
+
+	// the binary lacking the handler halts at the height of the upgrade
+	require.NotPanics(t, func() { upgrade.BeginBlocker(ctx, bvsApp.upgradeKeeper) })
+	require.Panics(t, func() { upgrade.BeginBlocker(ctxAt(5), bvsApp.upgradeKeeper) })
+
+	// the binary having it migrates the records
+	k := upgrade.NewKeeper(bvsApp.cdc, bvsApp.keyMain, bvsApp.govKeeper, 2)
+	require.Panics(t, func() { upgrade.BeginBlocker(ctx, k) })
+	k.SetHandler("v2", upgrade.Migration{Version: 2, Migrate: func(ctx sdk.Context) error {
+		return upgrade.MigrateCodices(ctx, bvsApp.cdc, bvsApp.keyCodex, func(bz []byte) (*types.Codex, error) {
+			old := codexV1{}
+			if err := bvsApp.cdc.UnmarshalBinaryBare(bz, &old); err != nil {
+				return nil, err
+			}
+			return &types.Codex{Id: old.Id, Owner: old.Owner, UnitPrice: old.UnitPrice, Transfer: types.TransferFree}, nil
+		})
+	}})
+	ctx = ctxAt(5)
+	tags := upgrade.BeginBlocker(ctx, k)
+	require.Equal(t, "v2", string(tags[0].Value))
+	require.Equal(t, 2, k.GetSchemaVersion(ctx))
+	require.Nil(t, bvsApp.govKeeper.GetUpgradePlan(ctx))
+	cod := bvsApp.codexMapper.GetCodex(ctx, "0:c:old")
+	require.Equal(t, 30, cod.UnitPrice)
+	require.Equal(t, types.TransferFree, cod.Transfer)
+
+	// and the old binary can no longer read the stores
+	require.Panics(t, func() { upgrade.BeginBlocker(ctxAt(6), bvsApp.upgradeKeeper) })
+}
+
+func TestVesting(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	aliceAcc, aliceAddr, alice := newTestUser(t, "1000bvs")
+	aliceAcc.Vesting = &types.VestingSchedule{Kind: types.VestingContinuous, Original: sdk.Coins{sdk.NewInt64Coin("bvs", 1000)}, EndHeight: 100}
+	bobAcc, bobAddr, bob := newTestUser(t, "500bvs")
+	bobAcc.Vesting = &types.VestingSchedule{Kind: types.VestingDelayed, Original: sdk.Coins{sdk.NewInt64Coin("bvs", 400)}, EndHeight: 50}
+	carolAcc, carolAddr, carol := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc, carolAcc},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 10})
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	require.Equal(t, &types.VestingBalance{Address: aliceAddr, Coins: silver(1000), Vested: silver(100), Locked: silver(900), Spendable: silver(100)},
+		bvsApp.vestingKeeper.Balance(ctx, aliceAddr))
+
+	// locked coins cannot be sent through the bank
+	bankHandler := vesting.NewBankHandler(bvsApp.vestingKeeper, bank.NewHandler(bvsApp.coinKeeper))
+	send := func(amt int64) sdk.Result {
+		return bankHandler(ctx, bank.NewMsgSend(
+			[]bank.Input{bank.NewInput(aliceAddr, silver(amt))},
+			[]bank.Output{bank.NewOutput(carolAddr, silver(amt))},
+		))
+	}
+	require.False(t, send(200).IsOK())
+	res := send(100)
+	require.True(t, res.IsOK(), res.Log)
+
+	// nor through bvs
+	handler := shop.NewHandler(bvsApp.shopKeeper)
+	bvsSend := func(amt int64) sdk.Result {
+		return handler(ctx, shop.BuildBvsMsg(bobAddr, bob, carol, &types.BvsAsset{Coins: silver(amt)}))
+	}
+	require.False(t, bvsSend(200).IsOK())
+	require.True(t, bvsSend(100).IsOK())
+	ctx = ctx.WithBlockHeight(50)
+	res = bvsSend(400)
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, silver(600), bvsApp.accountMapper.GetAccount(ctx, carolAddr).GetCoins())
+
+	// the schedules survive an export
+	appState, _, err := bvsApp.ExportAppStateAndValidators(false)
+	require.Nil(t, err)
+	genState := types.GenesisState{}
+	require.Nil(t, bvsApp.cdc.UnmarshalJSON(appState, &genState))
+	for _, acc := range genState.Accounts {
+		if acc.Id == alice {
+			require.Equal(t, aliceAcc.Vesting, acc.Vesting)
+		}
+	}
+	_, err = (&types.GenesisAccount{Id: alice, Address: aliceAddr, Coins: silver(10), Vesting: aliceAcc.Vesting}).ToAccount()
+	require.NotNil(t, err)
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+
+	priv := ed25519.GenPrivKey()
+	aliceAddr := sdk.AccAddress(priv.PubKey().Address())
+	alice := types.UserId(aliceAddr)
+	aliceAcc := &types.GenesisAccount{Id: alice, Address: aliceAddr, Coins: sdk.Coins{sdk.NewInt64Coin("bvg", 1000), sdk.NewInt64Coin("bvs", 1000)}}
+	bobAcc, bobAddr, bob := newTestUser(t, "500bvs")
+	bobAcc.Vesting = &types.VestingSchedule{Kind: types.VestingDelayed, Original: sdk.Coins{sdk.NewInt64Coin("bvs", 400)}, EndHeight: 50}
+	ownerAcc, _, owner := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts:  []*types.GenesisAccount{bobAcc, ownerAcc, aliceAcc},
+		Codices:   []*types.Codex{{Id: "0:c:ticket", Owner: owner, CountLive: 1}},
+		Vouchers:  []*types.Voucher{{Id: "0:v:ticket:0", Origin: "0:c:ticket", Holder: alice}},
+		FeePolicy: &types.FeePolicy{MinFee: 10, CommunityShare: 10, Validators: []*types.FeeValidator{{Payee: bob, Power: 1}}},
+	})
+	require.Nil(t, err)
+
+	ctx := bvsApp.BaseApp.NewContext(true, abci.Header{Height: 1})
+	aliceNumber := bvsApp.accountMapper.GetAccount(ctx, aliceAddr).GetAccountNumber()
+	require.Equal(t, int64(2), aliceNumber)
+	sendTx := func(seq int64) []byte {
+		msgs := []sdk.Msg{bank.NewMsgSend(
+			[]bank.Input{bank.NewInput(aliceAddr, sdk.Coins{sdk.NewInt64Coin("bvs", 1)})},
+			[]bank.Output{bank.NewOutput(bobAddr, sdk.Coins{sdk.NewInt64Coin("bvs", 1)})},
+		)}
+		stdFee := auth.NewStdFee(20000, sdk.NewInt64Coin("bvs", 100))
+		sig, err := priv.Sign(auth.StdSignBytes("", aliceNumber, seq, stdFee, msgs, ""))
+		require.Nil(t, err)
+		tx := auth.NewStdTx(msgs, stdFee, []auth.StdSignature{{PubKey: priv.PubKey(), Signature: sig, AccountNumber: aliceNumber, Sequence: seq}}, "")
+		return bvsApp.cdc.MustMarshalBinary(tx)
+	}
+	bvsApp.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 2}})
+	dres := bvsApp.DeliverTx(sendTx(0))
+	require.True(t, dres.IsOK(), dres.Log)
+	bvsApp.EndBlock(abci.RequestEndBlock{Height: 2})
+	bvsApp.Commit()
+
+	// fill the stores of the other modules
+	ctx = bvsApp.BaseApp.NewContext(true, abci.Header{Height: 3})
+	createHTLC := func(ctx sdk.Context, k htlc.Keeper, vouchers ...string) string {
+		res := htlc.NewHandler(k)(ctx, &htlc.MsgCreateHTLC{
+			SenderAccount: aliceAddr, Sender: alice, Recipient: bob, HashLock: make([]byte, 32), Timeout: 10,
+			Asset: types.BvsAsset{Coins: sdk.Coins{sdk.NewInt64Coin("bvs", 40)}, Vouchers: vouchers},
+		})
+		require.True(t, res.IsOK(), res.Log)
+		return string(res.Data)
+	}
+	htlcId := createHTLC(ctx, bvsApp.htlcKeeper, "0:v:ticket:0")
+	res := amm.NewHandler(bvsApp.ammKeeper)(ctx, &amm.MsgAddLiquidity{ProviderAccount: aliceAddr, Provider: alice, Silver: sdk.NewInt(100), MaxGold: sdk.NewInt(50)})
+	require.True(t, res.IsOK(), res.Log)
+	pk := ed25519.GenPrivKey().PubKey()
+	res = stake.NewHandler(bvsApp.stakeKeeper)(ctx, stake.NewMsgCreateValidator(aliceAddr, pk, sdk.NewInt64Coin("bvs", 100), stake.NewDescription("alice", "", "", "")))
+	require.True(t, res.IsOK(), res.Log)
+	eres := bvsApp.EndBlocker(ctx, abci.RequestEndBlock{Height: 3})
+	require.Equal(t, 1, len(eres.ValidatorUpdates))
+
+	appState, validators, err := bvsApp.ExportAppStateAndValidators(false)
+	require.Nil(t, err)
+
+	restarted := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+	restarted.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: appState})
+	restarted.Commit()
+	reState, reValidators, err := restarted.ExportAppStateAndValidators(false)
+	require.Nil(t, err)
+	require.Equal(t, string(appState), string(reState))
+	require.Equal(t, validators, reValidators)
+
+	// the accounts keep their numbers, sequences and keys, so the txs
+	// already delivered cannot be replayed
+	ctx = restarted.BaseApp.NewContext(true, abci.Header{Height: 1})
+	acc := restarted.accountMapper.GetAccount(ctx, aliceAddr)
+	require.Equal(t, aliceNumber, acc.GetAccountNumber())
+	require.Equal(t, int64(1), acc.GetSequence())
+	require.Equal(t, priv.PubKey(), acc.GetPubKey())
+	require.Equal(t, int64(3), restarted.accountMapper.GetNextAccountNumber(ctx))
+	restarted.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	dres = restarted.DeliverTx(sendTx(0))
+	require.False(t, dres.IsOK())
+	dres = restarted.DeliverTx(sendTx(1))
+	require.True(t, dres.IsOK(), dres.Log)
+
+	// ids are not given away twice, even those of records gone since
+	ctx = restarted.BaseApp.NewContext(true, abci.Header{Height: 1})
+	restarted.htlcKeeper.DeleteHTLC(ctx, htlcId)
+	require.NotEqual(t, htlcId, createHTLC(ctx, restarted.htlcKeeper))
+}
+
+func TestExportForZeroHeight(t *testing.T) {
+	db := dbm.NewMemDB()
+	bvsApp := NewBvsApp(log.NewNopLogger(), db)
+
+	priv := ed25519.GenPrivKey()
+	aliceAddr := sdk.AccAddress(priv.PubKey().Address())
+	alice := types.UserId(aliceAddr)
+	aliceAcc := &types.GenesisAccount{Id: alice, Address: aliceAddr, Coins: sdk.Coins{sdk.NewInt64Coin("bvs", 1000)},
+		Vesting: &types.VestingSchedule{Kind: types.VestingContinuous, Original: sdk.Coins{sdk.NewInt64Coin("bvs", 500)}, EndHeight: 100}}
+	bobAcc, bobAddr, bob := newTestUser(t, "")
+	ownerAcc, _, owner := newTestUser(t, "")
+	err := setGenesisState(bvsApp, types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, bobAcc, ownerAcc},
+		Codices:  []*types.Codex{{Id: "0:c:ticket", Owner: owner, CountLive: 3, LastActive: 8}},
+		Vouchers: []*types.Voucher{
+			{Id: "0:v:ticket:0", Origin: "0:c:ticket", Holder: alice, ExpireOn: 5},
+			{Id: "0:v:ticket:1", Origin: "0:c:ticket", Holder: alice, ExpireOn: 20},
+			{Id: "0:v:ticket:2", Origin: "0:c:ticket", Holder: "pending-0"},
+		},
+		Pendings: []*types.PendingTransfer{{Id: "pending-0", Sender: alice, Recipient: bob, Vouchers: []string{"0:v:ticket:2"}, Deadline: 15}},
+		HTLCs:    []*types.HTLC{{Id: "htlc-0", Sender: alice, Recipient: bob, HashLock: make([]byte, 32), Timeout: 3}},
+	})
+	require.Nil(t, err)
+
+	// alice sends 100 silver at height 5, and the chain goes on to height 10
+	for height := int64(2); height <= 10; height++ {
+		bvsApp.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: height}})
+		if height == 5 {
+			msgs := []sdk.Msg{bank.NewMsgSend(
+				[]bank.Input{bank.NewInput(aliceAddr, sdk.Coins{sdk.NewInt64Coin("bvs", 100)})},
+				[]bank.Output{bank.NewOutput(bobAddr, sdk.Coins{sdk.NewInt64Coin("bvs", 100)})},
+			)}
+			stdFee := auth.NewStdFee(20000)
+			sig, err := priv.Sign(auth.StdSignBytes("", 0, 0, stdFee, msgs, ""))
+			require.Nil(t, err)
+			tx := auth.NewStdTx(msgs, stdFee, []auth.StdSignature{{PubKey: priv.PubKey(), Signature: sig}}, "")
+			dres := bvsApp.DeliverTx(bvsApp.cdc.MustMarshalBinary(tx))
+			require.True(t, dres.IsOK(), dres.Log)
+		}
+		bvsApp.EndBlock(abci.RequestEndBlock{Height: height})
+		bvsApp.Commit()
+	}
+
+	appState, _, err := bvsApp.ExportAppStateAndValidators(true)
+	require.Nil(t, err)
+	genState := types.GenesisState{}
+	require.Nil(t, bvsApp.cdc.UnmarshalJSON(appState, &genState))
+	aliceOf := func(genState types.GenesisState) *types.GenesisAccount {
+		for _, acc := range genState.Accounts {
+			if acc.Id == alice {
+				return acc
+			}
+		}
+		return nil
+	}
+	require.Equal(t, &types.VestingSchedule{Kind: types.VestingContinuous, Original: sdk.Coins{sdk.NewInt64Coin("bvs", 500)}, StartHeight: -10, EndHeight: 90},
+		aliceOf(genState).Vesting)
+	require.Equal(t, -1, genState.Vouchers[0].ExpireOn)
+	require.Equal(t, 10, genState.Vouchers[1].ExpireOn)
+	require.Equal(t, 0, genState.Vouchers[2].ExpireOn)
+	require.Equal(t, 5, genState.Pendings[0].Deadline)
+	require.Equal(t, int64(0), genState.HTLCs[0].Timeout)
+	require.Equal(t, -2, genState.Codices[0].LastActive)
+
+	// the first block of the new chain is as the block after the export
+	restarted := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+	restarted.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: appState})
+	restarted.Commit()
+	ctx := restarted.BaseApp.NewContext(true, abci.Header{Height: 1})
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	require.Equal(t, silver(55), restarted.vestingKeeper.Balance(ctx, aliceAddr).Vested)
+	require.True(t, restarted.voucherMapper.GetVoucher(ctx, "0:v:ticket:0").IsExpired(1))
+	require.False(t, restarted.voucherMapper.GetVoucher(ctx, "0:v:ticket:1").IsExpired(10))
+	require.True(t, restarted.voucherMapper.GetVoucher(ctx, "0:v:ticket:1").IsExpired(11))
+	res := htlc.NewHandler(restarted.htlcKeeper)(ctx, &htlc.MsgRefundHTLC{SenderAccount: aliceAddr, Sender: alice, HTLC: "htlc-0"})
+	require.True(t, res.IsOK(), res.Log)
+	ticket := restarted.codexMapper.GetCodex(ctx, "0:c:ticket")
+	require.False(t, guarantee.IsAbandoned(ctx.WithBlockHeight(guarantee.AbandonPeriod-2), ticket))
+	require.True(t, guarantee.IsAbandoned(ctx.WithBlockHeight(guarantee.AbandonPeriod-1), ticket))
+
+	// the state of a past height can be exported too
+	reopened := NewBvsApp(log.NewNopLogger(), db)
+	require.Nil(t, reopened.LoadHeight(4))
+	appState, _, err = reopened.ExportAppStateAndValidators(false)
+	require.Nil(t, err)
+	genState = types.GenesisState{}
+	require.Nil(t, reopened.cdc.UnmarshalJSON(appState, &genState))
+	require.Equal(t, silver(1000), aliceOf(genState).Coins)
+	require.Equal(t, 5, genState.Vouchers[0].ExpireOn)
+}
+
+func TestValidateGenesis(t *testing.T) {
+	cdc := MakeCodec()
+	doc, err := tmtypes.GenesisDocFromFile("../testdata/genesis.json")
+	require.Nil(t, err)
+	genState := types.GenesisState{}
+	require.Nil(t, cdc.UnmarshalJSON(doc.AppState, &genState))
+	require.Nil(t, genState.Validate())
+	bvsApp := NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+	bvsApp.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: doc.AppState})
+
+	// every violation is reported, and none goes into a chain
+	aliceAcc, _, alice := newTestUser(t, "")
+	silver := func(amt int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("bvs", amt)} }
+	genState = types.GenesisState{
+		Accounts: []*types.GenesisAccount{aliceAcc, aliceAcc},
+		Codices:  []*types.Codex{{Id: "0:c:gym", Owner: alice, CountLive: 1, Deposit: 50, Coins: silver(100)}},
+		Vouchers: []*types.Voucher{
+			{Id: "0:v:gym:0", Origin: "0:c:gym", Holder: alice},
+			{Id: "0:v:gym:0", Origin: "0:c:gym", Holder: alice},
+			{Id: "0:v:spa:0", Origin: "0:c:spa", Holder: alice},
+		},
+	}
+	err = genState.Validate()
+	require.NotNil(t, err)
+	errs, ok := err.(types.GenesisErrors)
+	require.True(t, ok)
+	require.Equal(t, 6, len(errs), err.Error())
+
+	// codex settings out of range are reported one by one
+	for _, bad := range []func(cod *types.Codex){
+		func(cod *types.Codex) { cod.Royalty = -1 },
+		func(cod *types.Codex) { cod.Royalty = 101 },
+		func(cod *types.Codex) { cod.RoyaltyTo = "0:c:spa" },
+		func(cod *types.Codex) { cod.Transfer = "resale-only" },
+		func(cod *types.Codex) { cod.Breakage = "burn" },
+		func(cod *types.Codex) { cod.Arbiter = "0:c:spa" },
+	} {
+		cod := &types.Codex{Id: "0:c:spa", Owner: alice, Royalty: 10, RoyaltyTo: alice, Arbiter: alice,
+			Transfer: types.TransferOwnerApproved, Breakage: types.BreakageRefund}
+		require.Nil(t, (&types.GenesisState{Codices: []*types.Codex{cod}}).Validate())
+		bad(cod)
+		err := (&types.GenesisState{Codices: []*types.Codex{cod}}).Validate()
+		require.NotNil(t, err)
+		require.Equal(t, 1, len(err.(types.GenesisErrors)), err.Error())
+	}
+
+	// so are prices too large for the market indexes
+	huge := sdk.NewCoin("bvs", sdk.NewIntWithDecimal(1, 30))
+	err = (&types.GenesisState{
+		Listings: []*types.Listing{{Id: "listing-0", Price: huge}},
+		Orders:   []*types.Order{{Id: "order-0", Side: types.OrderBid, Price: huge}},
+	}).Validate()
+	require.NotNil(t, err)
+	require.Equal(t, 2, len(err.(types.GenesisErrors)), err.Error())
+
+	stateBytes, err := wire.MarshalJSONIndent(cdc, genState)
+	require.Nil(t, err)
+	bvsApp = NewBvsApp(log.NewNopLogger(), dbm.NewMemDB())
+	require.Panics(t, func() {
+		bvsApp.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: stateBytes})
+	})
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/slashing"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// paramSetter decodes the JSON value of a parameter and sets it, failing
+// when the value is invalid.
+type paramSetter func(ctx sdk.Context, value string) sdk.Error
+
+// paramStore lets passed parameter-change proposals set the parameters of
+// the modules, known by their keys.
+type paramStore struct {
+	setters map[string]paramSetter
+}
+
+// ValidateParam implements gov.ParamStore by setting the parameter in a
+// throwaway cache of the store.
+func (ps paramStore) ValidateParam(ctx sdk.Context, key string, value string) sdk.Error {
+	set, ok := ps.setters[key]
+	if !ok {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("unknown parameter %s", key))
+	}
+	cacheCtx, _ := ctx.CacheContext()
+	return set(cacheCtx, value)
+}
+
+// SetParam implements gov.ParamStore.
+func (ps paramStore) SetParam(ctx sdk.Context, key string, value string) sdk.Error {
+	set, ok := ps.setters[key]
+	if !ok {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("unknown parameter %s", key))
+	}
+	return set(ctx, value)
+}
+
+// newParamStore returns the parameters governance may change.
+func (app *BvsApp) newParamStore() paramStore {
+	ps := paramStore{setters: map[string]paramSetter{}}
+
+	// slashing parameters are kept by the params keeper
+	for _, key := range []string{slashing.MaxEvidenceAgeKey, slashing.SignedBlocksWindowKey,
+		slashing.DoubleSignUnbondDurationKey, slashing.DowntimeUnbondDurationKey} {
+		key := key
+		ps.setters[key] = func(ctx sdk.Context, value string) sdk.Error {
+			var i int64
+			if err := json.Unmarshal([]byte(value), &i); err != nil || i <= 0 {
+				return sdk.ErrUnknownRequest(fmt.Sprintf("%s is not a positive integer", value))
+			}
+			app.paramsKeeper.Setter().SetInt64(ctx, key, i)
+			return nil
+		}
+	}
+	for _, key := range []string{slashing.MinSignedPerWindowKey, slashing.SlashFractionDoubleSignKey,
+		slashing.SlashFractionDowntimeKey} {
+		key := key
+		ps.setters[key] = func(ctx sdk.Context, value string) sdk.Error {
+			var r sdk.Rat
+			if err := app.cdc.UnmarshalJSON([]byte(value), &r); err != nil || r.LT(sdk.ZeroRat()) || r.GT(sdk.OneRat()) {
+				return sdk.ErrUnknownRequest(fmt.Sprintf("%s is not a fraction", value))
+			}
+			app.paramsKeeper.Setter().SetRat(ctx, key, r)
+			return nil
+		}
+	}
+
+	// the fee policy is kept by the fee keeper, which checks it
+	ps.setters["fee/min-fee"] = app.feePolicySetter(func(p *types.FeePolicy) interface{} { return &p.MinFee })
+	ps.setters["fee/community-share"] = app.feePolicySetter(func(p *types.FeePolicy) interface{} { return &p.CommunityShare })
+
+	// so are the governance rules by the gov keeper
+	ps.setters["gov/min-deposit"] = app.govParamsSetter(func(p *types.GovParams) interface{} { return &p.MinDeposit })
+	ps.setters["gov/deposit-period"] = app.govParamsSetter(func(p *types.GovParams) interface{} { return &p.DepositPeriod })
+	ps.setters["gov/voting-period"] = app.govParamsSetter(func(p *types.GovParams) interface{} { return &p.VotingPeriod })
+	ps.setters["gov/quorum"] = app.govParamsSetter(func(p *types.GovParams) interface{} { return &p.Quorum })
+	ps.setters["gov/threshold"] = app.govParamsSetter(func(p *types.GovParams) interface{} { return &p.Threshold })
+	ps.setters["gov/veto"] = app.govParamsSetter(func(p *types.GovParams) interface{} { return &p.Veto })
+
+	// and the settings of the bvs modules by the params mapper
+	ps.setters["shop/min-deposit-per-voucher"] = app.bvsParamsSetter(func(p *types.BvsParams) interface{} { return &p.Shop.MinDepositPerVoucher })
+	ps.setters["shop/max-expire-after"] = app.bvsParamsSetter(func(p *types.BvsParams) interface{} { return &p.Shop.MaxExpireAfter })
+	ps.setters["refund/min-window"] = app.bvsParamsSetter(func(p *types.BvsParams) interface{} { return &p.Refund.MinWindow })
+	ps.setters["refund/max-window"] = app.bvsParamsSetter(func(p *types.BvsParams) interface{} { return &p.Refund.MaxWindow })
+	ps.setters["market/allowed-denoms"] = app.bvsParamsSetter(func(p *types.BvsParams) interface{} { return &p.Market.AllowedDenoms })
+	ps.setters["amm/swap-fee"] = app.bvsParamsSetter(func(p *types.BvsParams) interface{} { return &p.AMM.SwapFee })
+
+	return ps
+}
+
+// feePolicySetter returns a setter of the field of the fee policy pointed
+// to by field.
+func (app *BvsApp) feePolicySetter(field func(*types.FeePolicy) interface{}) paramSetter {
+	return func(ctx sdk.Context, value string) sdk.Error {
+		p := app.feeKeeper.GetPolicy(ctx)
+		if err := json.Unmarshal([]byte(value), field(p)); err != nil {
+			return sdk.ErrUnknownRequest(err.Error())
+		}
+		return app.feeKeeper.SetPolicy(ctx, p)
+	}
+}
+
+// govParamsSetter returns a setter of the field of the governance rules
+// pointed to by field.
+func (app *BvsApp) govParamsSetter(field func(*types.GovParams) interface{}) paramSetter {
+	return func(ctx sdk.Context, value string) sdk.Error {
+		p := app.govKeeper.GetParams(ctx)
+		if err := json.Unmarshal([]byte(value), field(p)); err != nil {
+			return sdk.ErrUnknownRequest(err.Error())
+		}
+		return app.govKeeper.SetParams(ctx, p)
+	}
+}
+
+// bvsParamsSetter returns a setter of the field of the settings of the bvs
+// modules pointed to by field.
+func (app *BvsApp) bvsParamsSetter(field func(*types.BvsParams) interface{}) paramSetter {
+	return func(ctx sdk.Context, value string) sdk.Error {
+		p := app.paramsMapper.GetParams(ctx)
+		if err := json.Unmarshal([]byte(value), field(p)); err != nil {
+			return sdk.ErrUnknownRequest(err.Error())
+		}
+		if err := app.paramsMapper.SetParams(ctx, p); err != nil {
+			return sdk.ErrUnknownRequest(err.Error())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/slashing"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// dumpedRecords are the records of a store exported as they are: the whole
+// store when no keys are given, else the records of those keys.
+type dumpedRecords struct {
+	key  *sdk.KVStoreKey
+	keys []string
+}
+
+// dumpedStores lists the records the genesis states of the modules leave
+// out. The cosmos-sdk modules below have no genesis state of their own, and
+// ours leave out the sequences their ids are drawn from, which must not go
+// back to ids already given away. A module keeping such records must be
+// listed here too for the chain to restart from its export.
+func (app *BvsApp) dumpedStores() []dumpedRecords {
+	return []dumpedRecords{
+		{key: app.keyFee},
+		{key: app.keySlashing},
+		{key: app.keyParams},
+		{key: app.keyIBC},
+		{key: app.keyPending, keys: []string{"pending-seq"}},
+		{key: app.keyHTLC, keys: []string{"htlc-seq"}},
+		{key: app.keyRefund, keys: []string{"refund-seq"}},
+		{key: app.keyDispute, keys: []string{"dispute-seq"}},
+		{key: app.keyMultisig, keys: []string{"multisig-seq", "proposal-seq"}},
+		{key: app.keyMarket, keys: []string{"listing-seq", "order-seq"}},
+		{key: app.keyGov, keys: []string{"gov-proposal-seq"}},
+	}
+}
+
+// dumpStores returns the records listed by dumpedStores.
+func (app *BvsApp) dumpStores(ctx sdk.Context) []*types.StoreDump {
+	dumps := []*types.StoreDump{}
+	for _, d := range app.dumpedStores() {
+		store := ctx.KVStore(d.key)
+		dump := &types.StoreDump{Store: d.key.Name(), Pairs: []*types.KVPair{}}
+		if d.keys == nil {
+			iter := store.Iterator(nil, nil)
+			for ; iter.Valid(); iter.Next() {
+				dump.Pairs = append(dump.Pairs, &types.KVPair{Key: iter.Key(), Value: iter.Value()})
+			}
+			iter.Close()
+		}
+		for _, k := range d.keys {
+			if bz := store.Get([]byte(k)); bz != nil {
+				dump.Pairs = append(dump.Pairs, &types.KVPair{Key: []byte(k), Value: bz})
+			}
+		}
+		dumps = append(dumps, dump)
+	}
+	return dumps
+}
+
+// signingInfosForZeroHeight rebases the heights the validators are watched
+// from in a dump of the slashing store, as ForZeroHeight of the genesis
+// state does, so that they are not spared for downtime on the new chain.
+func (app *BvsApp) signingInfosForZeroHeight(dumps []*types.StoreDump, height int64) error {
+	prefix := slashing.GetValidatorSigningInfoKey(sdk.ValAddress{})
+	for _, dump := range dumps {
+		if dump.Store != app.keySlashing.Name() {
+			continue
+		}
+		for _, p := range dump.Pairs {
+			if !bytes.HasPrefix(p.Key, prefix) {
+				continue
+			}
+			var info slashing.ValidatorSigningInfo
+			if err := app.cdc.UnmarshalBinary(p.Value, &info); err != nil {
+				return err
+			}
+			info.StartHeight -= height
+			if info.StartHeight < 0 {
+				info.StartHeight = 0
+			}
+			bz, err := app.cdc.MarshalBinary(info)
+			if err != nil {
+				return err
+			}
+			p.Value = bz
+		}
+	}
+	return nil
+}
+
+// loadStores writes back records dumped by dumpStores.
+func (app *BvsApp) loadStores(ctx sdk.Context, dumps []*types.StoreDump) error {
+	keys := map[string]*sdk.KVStoreKey{}
+	for _, d := range app.dumpedStores() {
+		keys[d.key.Name()] = d.key
+	}
+	for _, dump := range dumps {
+		key, ok := keys[dump.Store]
+		if !ok {
+			return fmt.Errorf("no records of the store %s expected", dump.Store)
+		}
+		store := ctx.KVStore(key)
+		for _, p := range dump.Pairs {
+			store.Set(p.Key, p.Value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package app
+
+// SchemaVersion is the version of the schema of the records this binary
+// reads. A change to the encoding of Codex, Voucher or any other record kept
+// in the stores bumps it, and comes with a migration rewriting the records of
+// the previous version, registered with the upgrade switching to the binary:
+//
+//	app.upgradeKeeper.SetHandler("v2", upgrade.Migration{Version: 2, Migrate: func(ctx sdk.Context) error {
+//		return upgrade.MigrateCodices(ctx, app.cdc, app.keyCodex, decodeCodexV1)
+//	}})
+//
+// A node halts at the height of an upgrade it has no handler for.
+const SchemaVersion = 1
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/allowance"
+)
+
+func GetAllowancesCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "allowances",
+		Short: "Query allowances by owner or spender",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			var keys [][]byte
+			if owner := viper.GetString("owner"); owner != "" {
+				kvs, err := cliCtx.QuerySubspace(types.AllowanceByOwnerPrefix(owner), storeName)
+				if err != nil {
+					return err
+				}
+				for _, kv := range kvs {
+					keys = append(keys, kv.Key)
+				}
+			} else if spender := viper.GetString("spender"); spender != "" {
+				kvs, err := cliCtx.QuerySubspace(types.AllowanceBySpenderPrefix(spender), storeName)
+				if err != nil {
+					return err
+				}
+				for _, kv := range kvs {
+					keys = append(keys, types.AllowanceKey(string(kv.Value), spender))
+				}
+			} else {
+				return errors.Errorf("Either --owner or --spender is required.")
+			}
+
+			allowances := []*types.Allowance{}
+			for _, key := range keys {
+				res, err := cliCtx.QueryStore(key, storeName)
+				if err != nil {
+					return err
+				}
+				a := &types.Allowance{}
+				err = cdc.UnmarshalBinaryBare(res, a)
+				if err != nil {
+					return err
+				}
+				allowances = append(allowances, a)
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, allowances)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("owner", "", "Id of the owner")
+	cmd.Flags().String("spender", "", "Id of the spender")
+
+	return cmd
+}
+
+func ApproveCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve [spender]",
+		Short: "Grant an account an allowance over your assets",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			coins, err := sdk.ParseCoins(viper.GetString("coins"))
+			if err != nil {
+				return err
+			}
+			msg := &allowance.MsgApprove{
+				OwnerAccount: accAddress,
+				Owner:        types.UserId(accAddress),
+				Spender:      args[0],
+				Coins:        coins,
+				Vouchers:     splitIds(viper.GetString("vouchers")),
+				Codices:      splitIds(viper.GetString("codices")),
+				ExpireOn:     viper.GetInt("expire-on"),
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("coins", "", "Amount of coins the spender may move")
+	cmd.Flags().String("vouchers", "", "Comma separated vouchers the spender may move")
+	cmd.Flags().String("codices", "", "Comma separated codices any voucher of which the spender may move")
+	cmd.Flags().Int("expire-on", 0, "Block height after which the allowance expires")
+
+	return cmd
+}
+
+func RevokeCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [spender]",
+		Short: "Withdraw the allowance granted to an account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := &allowance.MsgRevoke{
+				OwnerAccount: accAddress,
+				Owner:        types.UserId(accAddress),
+				Spender:      args[0],
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func TransferFromCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer-from [owner]",
+		Short: "Send assets of another account within its allowance",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			asset := types.ParseBvsAsset(viper.GetString("asset"))
+			if asset == nil {
+				return errors.Errorf("Nothing to send.")
+			}
+			msg := &allowance.MsgTransferFrom{
+				SpenderAccount: accAddress,
+				Spender:        types.UserId(accAddress),
+				Owner:          args[0],
+				Recipient:      viper.GetString("recp"),
+				Asset:          *asset,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("recp", "", "Recipient id")
+	cmd.Flags().String("asset", "", "List of assets to send")
+
+	return cmd
+}
+
+// splitIds splits a comma separated list of ids.
+func splitIds(str string) []string {
+	var ids []string
+	for _, id := range strings.Split(str, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
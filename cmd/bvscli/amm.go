@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/amm"
+)
+
+// parseInt parses an amount, where an empty string means zero.
+func parseInt(str string) (sdk.Int, error) {
+	if str == "" {
+		return sdk.ZeroInt(), nil
+	}
+	i, ok := sdk.NewIntFromString(str)
+	if !ok {
+		return sdk.Int{}, errors.Errorf("Invalid amount %s.", str)
+	}
+	return i, nil
+}
+
+// customQueryCmd builds a command printing the JSON answer of a custom query.
+func customQueryCmd(cmd *cobra.Command, path func(args []string) string) *cobra.Command {
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		cliCtx := context.NewCLIContext()
+
+		res, err := cliCtx.Query(path(args))
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(res))
+
+		return nil
+	}
+	return cmd
+}
+
+func GetPoolCmd() *cobra.Command {
+	return customQueryCmd(&cobra.Command{
+		Use:   "pool",
+		Short: "Query the reserves and shares of the silver/gold pool",
+		Args:  cobra.NoArgs,
+	}, func(args []string) string { return "custom/amm/pool" })
+}
+
+func GetPoolSharesCmd() *cobra.Command {
+	return customQueryCmd(&cobra.Command{
+		Use:   "pool-shares [user]",
+		Short: "Query the shares of the silver/gold pool owned by a user",
+		Args:  cobra.ExactArgs(1),
+	}, func(args []string) string { return "custom/amm/shares/" + args[0] })
+}
+
+func GetQuoteCmd() *cobra.Command {
+	return customQueryCmd(&cobra.Command{
+		Use:   "quote [offer]",
+		Short: "Query what the silver/gold pool gives for offered coins",
+		Args:  cobra.ExactArgs(1),
+	}, func(args []string) string { return "custom/amm/quote/" + args[0] })
+}
+
+func AddLiquidityCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-liquidity [silver] [max-gold]",
+		Short: "Put silver and gold into the pool for shares of it",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			silver, err := parseInt(args[0])
+			if err != nil {
+				return err
+			}
+			maxGold, err := parseInt(args[1])
+			if err != nil {
+				return err
+			}
+			msg := &amm.MsgAddLiquidity{
+				ProviderAccount: accAddress,
+				Provider:        types.UserId(accAddress),
+				Silver:          silver,
+				MaxGold:         maxGold,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func RemoveLiquidityCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-liquidity [shares]",
+		Short: "Give back shares of the pool for silver and gold",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			shares, err := parseInt(args[0])
+			if err != nil {
+				return err
+			}
+			minSilver, err := parseInt(viper.GetString("min-silver"))
+			if err != nil {
+				return err
+			}
+			minGold, err := parseInt(viper.GetString("min-gold"))
+			if err != nil {
+				return err
+			}
+			msg := &amm.MsgRemoveLiquidity{
+				ProviderAccount: accAddress,
+				Provider:        types.UserId(accAddress),
+				Shares:          shares,
+				MinSilver:       minSilver,
+				MinGold:         minGold,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("min-silver", "", "Least silver to withdraw")
+	cmd.Flags().String("min-gold", "", "Least gold to withdraw")
+
+	return cmd
+}
+
+func SwapCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "swap [offer]",
+		Short: "Trade silver for gold or gold for silver through the pool",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			offer, err := sdk.ParseCoin(args[0])
+			if err != nil {
+				return err
+			}
+			minReceive, err := parseInt(viper.GetString("min-receive"))
+			if err != nil {
+				return err
+			}
+			msg := &amm.MsgSwap{
+				TraderAccount: accAddress,
+				Trader:        types.UserId(accAddress),
+				Offer:         offer,
+				MinReceive:    minReceive,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("min-receive", "", "Least amount to receive, or the swap fails")
+
+	return cmd
+}
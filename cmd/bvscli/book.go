@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/market"
+)
+
+// queryOrders loads the orders whose ids are the values under a prefix, in
+// the order of the prefix index.
+func queryOrders(cliCtx context.CLIContext, cdc *wire.Codec, prefix []byte, storeName string) ([]*types.Order, error) {
+	kvs, err := cliCtx.QuerySubspace(prefix, storeName)
+	if err != nil {
+		return nil, err
+	}
+	orders := []*types.Order{}
+	for _, kv := range kvs {
+		res, err := cliCtx.QueryStore(types.OrderKey(string(kv.Value)), storeName)
+		if err != nil {
+			return nil, err
+		}
+		o := &types.Order{}
+		err = cdc.UnmarshalBinaryBare(res, o)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+func GetOrderCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "order [id]",
+		Short: "Query an order in the book",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.OrderKey(id), storeName)
+			if err != nil {
+				return err
+			} else if len(res) == 0 {
+				return fmt.Errorf("No order found with the id %s", id)
+			}
+
+			o := &types.Order{}
+			err = cdc.UnmarshalBinaryBare(res, o)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, o)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+func GetOrdersCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "orders",
+		Short: "Query the orders of a trader",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			trader := viper.GetString("trader")
+			if trader == "" {
+				return errors.Errorf("--trader is required.")
+			}
+			orders, err := queryOrders(cliCtx, cdc, types.OrderByTraderPrefix(trader), storeName)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, orders)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("trader", "", "Id of the trader")
+
+	return cmd
+}
+
+// depthLevel sums up the orders at one price on one side of a book.
+type depthLevel struct {
+	Price    sdk.Coin `json:"price"`
+	Quantity int      `json:"quantity"`
+	Orders   int      `json:"orders"`
+}
+
+// depthLevels folds orders sorted best first into price levels.
+func depthLevels(orders []*types.Order) []depthLevel {
+	levels := []depthLevel{}
+	for _, o := range orders {
+		if n := len(levels); n > 0 && levels[n-1].Price.IsEqual(o.Price) {
+			levels[n-1].Quantity += o.Quantity
+			levels[n-1].Orders++
+			continue
+		}
+		levels = append(levels, depthLevel{Price: o.Price, Quantity: o.Quantity, Orders: 1})
+	}
+	return levels
+}
+
+func GetDepthCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "depth [codex] [denom]",
+		Short: "Query the market depth of the order book of a codex",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			bids, err := queryOrders(cliCtx, cdc, types.OrderBookPrefix(args[0], types.OrderBid, args[1]), storeName)
+			if err != nil {
+				return err
+			}
+			asks, err := queryOrders(cliCtx, cdc, types.OrderBookPrefix(args[0], types.OrderAsk, args[1]), storeName)
+			if err != nil {
+				return err
+			}
+			depth := struct {
+				Bids []depthLevel `json:"bids"`
+				Asks []depthLevel `json:"asks"`
+			}{depthLevels(bids), depthLevels(asks)}
+
+			output, err := wire.MarshalJSONIndent(cdc, depth)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+// placeOrderCmd builds a command placing an order on one side of a book.
+func placeOrderCmd(cdc *wire.Codec, cmd *cobra.Command, side string) *cobra.Command {
+	cmd.Args = cobra.ExactArgs(3)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+		cliCtx := context.NewCLIContext().
+			WithCodec(cdc).
+			WithLogger(os.Stdout).
+			WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+		accAddress, err := cliCtx.GetFromAddress()
+		if err != nil {
+			return err
+		}
+		price, err := sdk.ParseCoin(args[1])
+		if err != nil {
+			return err
+		}
+		msg := &market.MsgPlaceOrder{
+			TraderAccount: accAddress,
+			Trader:        types.UserId(accAddress),
+			Codex:         args[0],
+			Side:          side,
+			Price:         price,
+			ExpireAfter:   viper.GetInt("expire-after"),
+		}
+		if side == types.OrderAsk {
+			msg.Vouchers = splitIds(args[2])
+		} else if msg.Quantity, err = strconv.Atoi(args[2]); err != nil {
+			return err
+		}
+
+		return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+	}
+
+	cmd.Flags().Int("expire-after", 0, "Number of blocks the order stays in the book")
+
+	return cmd
+}
+
+func BidCmd(cdc *wire.Codec) *cobra.Command {
+	return placeOrderCmd(cdc, &cobra.Command{
+		Use:   "bid [codex] [price] [quantity]",
+		Short: "Bid for vouchers of a codex at a price per voucher",
+	}, types.OrderBid)
+}
+
+func AskCmd(cdc *wire.Codec) *cobra.Command {
+	return placeOrderCmd(cdc, &cobra.Command{
+		Use:   "ask [codex] [price] [vouchers]",
+		Short: "Offer a comma separated list of vouchers at a price per voucher",
+	}, types.OrderAsk)
+}
+
+func CancelOrderCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel-order [order]",
+		Short: "Take an order out of the book",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := &market.MsgCancelOrder{
+				TraderAccount: accAddress,
+				Trader:        types.UserId(accAddress),
+				Order:         args[0],
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/hex"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/claim"
+)
+
+func PublishCodesCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish-codes [codex]",
+		Short: "Publish claim codes for vouchers of your codex",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			hashes, err := parseHexList(viper.GetString("hashes"))
+			if err != nil {
+				return err
+			}
+			for _, code := range splitIds(viper.GetString("codes")) {
+				hashes = append(hashes, types.ClaimCodeHash([]byte(code)))
+			}
+			roots, err := parseHexList(viper.GetString("roots"))
+			if err != nil {
+				return err
+			}
+			if len(hashes) == 0 && len(roots) == 0 {
+				return errors.Errorf("Nothing to publish.")
+			}
+			msg := &claim.MsgPublishCodes{
+				OwnerAccount:  accAddress,
+				Owner:         types.UserId(accAddress),
+				Codex:         args[0],
+				Hashes:        hashes,
+				Roots:         roots,
+				RequireCommit: viper.GetBool("require-commit"),
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("codes", "", "Comma separated claim codes, hashed before sending")
+	cmd.Flags().String("hashes", "", "Comma separated hex SHA-256 hashes of claim codes")
+	cmd.Flags().String("roots", "", "Comma separated hex Merkle roots of sets of claim codes")
+	cmd.Flags().Bool("require-commit", false, "Require claims to be committed in an earlier block")
+
+	return cmd
+}
+
+func CommitClaimCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commit-claim [code]",
+		Short: "Commit to a claim code without revealing it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			claimer := types.UserId(accAddress)
+			msg := &claim.MsgCommitClaim{
+				ClaimerAccount: accAddress,
+				Claimer:        claimer,
+				Commitment:     types.ClaimCommitment([]byte(args[0]), claimer),
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func ClaimCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claim [codex] [code]",
+		Short: "Receive a voucher of a codex with a claim code",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			proof, err := parseHexList(viper.GetString("proof"))
+			if err != nil {
+				return err
+			}
+			msg := &claim.MsgClaimWithCode{
+				ClaimerAccount: accAddress,
+				Claimer:        types.UserId(accAddress),
+				Codex:          args[0],
+				Code:           []byte(args[1]),
+				Proof:          proof,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("proof", "", "Comma separated hex Merkle proof of the code")
+
+	return cmd
+}
+
+// parseHexList decodes a comma separated list of hex strings.
+func parseHexList(str string) ([][]byte, error) {
+	var list [][]byte
+	for _, s := range splitIds(str) {
+		bz, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, bz)
+	}
+	return list, nil
+}
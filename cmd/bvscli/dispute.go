@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/dispute"
+)
+
+func GetDisputeCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dispute [id]",
+		Short: "Query a dispute and its outcome",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.DisputeKey(id), storeName)
+			if err != nil {
+				return err
+			} else if len(res) == 0 {
+				return fmt.Errorf("No dispute found with the id %s", id)
+			}
+
+			d := &types.Dispute{}
+			err = cdc.UnmarshalBinaryBare(res, d)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, d)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+func GetDisputesCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disputes",
+		Short: "Query disputes by codex or holder",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			var prefix []byte
+			if codex := viper.GetString("codex"); codex != "" {
+				prefix = types.DisputeByCodexPrefix(codex)
+			} else if holder := viper.GetString("holder"); holder != "" {
+				prefix = types.DisputeByHolderPrefix(holder)
+			} else {
+				return errors.Errorf("Either --codex or --holder is required.")
+			}
+			kvs, err := cliCtx.QuerySubspace(prefix, storeName)
+			if err != nil {
+				return err
+			}
+
+			disputes := []*types.Dispute{}
+			for _, kv := range kvs {
+				res, err := cliCtx.QueryStore(types.DisputeKey(string(kv.Value)), storeName)
+				if err != nil {
+					return err
+				}
+				d := &types.Dispute{}
+				err = cdc.UnmarshalBinaryBare(res, d)
+				if err != nil {
+					return err
+				}
+				disputes = append(disputes, d)
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, disputes)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("codex", "", "Id of the codex")
+	cmd.Flags().String("holder", "", "Id of the holder")
+
+	return cmd
+}
+
+func OpenDisputeCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "open-dispute [voucher]",
+		Short: "Dispute a voucher the merchant refuses to honor",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := &dispute.MsgOpenDispute{
+				HolderAccount: accAddress,
+				Holder:        types.UserId(accAddress),
+				Voucher:       args[0],
+				Reason:        viper.GetString("reason"),
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("reason", "", "What the dispute is about")
+
+	return cmd
+}
+
+func RuleDisputeCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rule-dispute [id] [holder|merchant]",
+		Short: "Rule a dispute as an arbiter",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			if args[1] != types.DisputeForHolder && args[1] != types.DisputeForMerchant {
+				return errors.Errorf("A ruling is either for the holder or for the merchant.")
+			}
+			compensation, err := sdk.ParseCoins(viper.GetString("compensation"))
+			if err != nil {
+				return err
+			}
+			msg := &dispute.MsgRuleDispute{
+				ArbiterAccount: accAddress,
+				Arbiter:        types.UserId(accAddress),
+				Dispute:        args[0],
+				ForHolder:      args[1] == types.DisputeForHolder,
+				Compensation:   compensation,
+				Ruling:         viper.GetString("ruling"),
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("compensation", "", "Silver paid to the holder out of the codex deposit")
+	cmd.Flags().String("ruling", "", "Grounds of the ruling")
+
+	return cmd
+}
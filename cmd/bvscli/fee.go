@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func GetFeePolicyCmd() *cobra.Command {
+	return customQueryCmd(&cobra.Command{
+		Use:   "fee-policy",
+		Short: "Query the minimum fee and how fees are distributed",
+		Args:  cobra.NoArgs,
+	}, func(args []string) string { return "custom/fee/policy" })
+}
+
+func GetCommunityPoolCmd() *cobra.Command {
+	return customQueryCmd(&cobra.Command{
+		Use:   "community-pool",
+		Short: "Query the coins of the community pool",
+		Args:  cobra.NoArgs,
+	}, func(args []string) string { return "custom/fee/community-pool" })
+}
+
+func GetBlockFeesCmd() *cobra.Command {
+	return customQueryCmd(&cobra.Command{
+		Use:   "block-fees [height]",
+		Short: "Query the fees collected in a recent block",
+		Args:  cobra.ExactArgs(1),
+	}, func(args []string) string { return "custom/fee/block/" + args[0] })
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/gov"
+)
+
+func GetGovProposalCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "gov-proposal [id]",
+		Short: "Query a governance proposal",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.GovProposalKey(id), storeName)
+			if err != nil {
+				return err
+			} else if len(res) == 0 {
+				return fmt.Errorf("No proposal found with the id %s", id)
+			}
+
+			p := &types.GovProposal{}
+			err = cdc.UnmarshalBinaryBare(res, p)
+			if err != nil {
+				return err
+			}
+
+			kvs, err := cliCtx.QuerySubspace(types.GovVotePrefix(id), storeName)
+			if err != nil {
+				return err
+			}
+			votes := []*types.GovVote{}
+			for _, kv := range kvs {
+				v := &types.GovVote{}
+				err = cdc.UnmarshalBinaryBare(kv.Value, v)
+				if err != nil {
+					return err
+				}
+				votes = append(votes, v)
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, struct {
+				*types.GovProposal
+				Votes []*types.GovVote `json:"votes"`
+			}{p, votes})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+func GetGovProposalsCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gov-proposals",
+		Short: "Query the governance proposals, optionally in a status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			kvs, err := cliCtx.QuerySubspace([]byte("gov-proposal:"), storeName)
+			if err != nil {
+				return err
+			}
+
+			status := viper.GetString("status")
+			proposals := []*types.GovProposal{}
+			for _, kv := range kvs {
+				p := &types.GovProposal{}
+				err = cdc.UnmarshalBinaryBare(kv.Value, p)
+				if err != nil {
+					return err
+				}
+				if status != "" && p.Status != status {
+					continue
+				}
+				proposals = append(proposals, p)
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, proposals)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("status", "", "Status of the proposals, e.g. voting-period")
+
+	return cmd
+}
+
+func GetGovParamsCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "gov-params",
+		Short: "Query the governance rules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.GovParamsKey, storeName)
+			if err != nil {
+				return err
+			}
+
+			params := types.DefaultGovParams()
+			if len(res) > 0 {
+				err = cdc.UnmarshalBinaryBare(res, params)
+				if err != nil {
+					return err
+				}
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, params)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+func SubmitProposalCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit-proposal [title]",
+		Short: "Submit a text, param-change or software-upgrade proposal",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			deposit, err := sdk.ParseCoins(viper.GetString("deposit"))
+			if err != nil {
+				return err
+			}
+			msg := &gov.MsgSubmitProposal{
+				ProposerAccount: accAddress,
+				Proposer:        types.UserId(accAddress),
+				Kind:            viper.GetString("kind"),
+				Title:           args[0],
+				Description:     viper.GetString("description"),
+				Deposit:         deposit,
+			}
+			changes, err := cmd.Flags().GetStringArray("change")
+			if err != nil {
+				return err
+			}
+			for _, change := range changes {
+				kv := strings.SplitN(change, "=", 2)
+				if len(kv) != 2 {
+					return errors.Errorf("Invalid change %s, expected key=value.", change)
+				}
+				msg.Changes = append(msg.Changes, types.ParamChange{Key: kv[0], Value: kv[1]})
+			}
+			if name := viper.GetString("upgrade-name"); name != "" {
+				msg.Upgrade = &types.UpgradePlan{Name: name, Height: viper.GetInt64("upgrade-height")}
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("kind", types.ProposalText, "Kind of the proposal: text, param-change or software-upgrade")
+	cmd.Flags().String("description", "", "Description of the proposal")
+	cmd.Flags().StringArray("change", nil, "Parameter change as key=value, with the value in JSON; repeatable")
+	cmd.Flags().String("upgrade-name", "", "Name of the software to upgrade to")
+	cmd.Flags().Int64("upgrade-height", 0, "Height of the upgrade")
+	cmd.Flags().String("deposit", "", "First deposit on the proposal")
+
+	return cmd
+}
+
+func DepositCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deposit [proposal] [amount]",
+		Short: "Deposit on a governance proposal",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			amount, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+			msg := &gov.MsgDeposit{
+				DepositorAccount: accAddress,
+				Depositor:        types.UserId(accAddress),
+				Proposal:         args[0],
+				Amount:           amount,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func VoteCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vote [proposal] [option]",
+		Short: "Vote yes, no, abstain or no-with-veto on a governance proposal",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := &gov.MsgVote{
+				VoterAccount: accAddress,
+				Voter:        types.UserId(accAddress),
+				Proposal:     args[0],
+				Option:       args[1],
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/htlc"
+)
+
+// HTLCCmd groups the commands on hash time-locked contracts.
+func HTLCCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "htlc",
+		Short: "Hash time-locked contract subcommands",
+	}
+	cmd.AddCommand(client.PostCommands(
+		CreateHTLCCmd(cdc),
+		ClaimHTLCCmd(cdc),
+		RefundHTLCCmd(cdc),
+	)...)
+	cmd.AddCommand(client.GetCommands(
+		GetHTLCCmd(storeName, cdc),
+	)...)
+	return cmd
+}
+
+func GetHTLCCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "query [id]",
+		Short: "Query an HTLC",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.HTLCKey(id), storeName)
+			if err != nil {
+				return err
+			} else if len(res) == 0 {
+				return fmt.Errorf("No HTLC found with the id %s", id)
+			}
+
+			h := &types.HTLC{}
+			err = cdc.UnmarshalBinaryBare(res, h)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, h)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+func CreateHTLCCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Lock assets for a recipient under a hash lock and a timeout",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			asset := types.ParseBvsAsset(viper.GetString("asset"))
+			if asset == nil {
+				return errors.Errorf("Nothing to lock.")
+			}
+			hashLock, err := hex.DecodeString(viper.GetString("hash"))
+			if err != nil {
+				return err
+			}
+			msg := &htlc.MsgCreateHTLC{
+				SenderAccount: accAddress,
+				Sender:        types.UserId(accAddress),
+				Recipient:     viper.GetString("recp"),
+				Asset:         *asset,
+				HashLock:      hashLock,
+				Timeout:       viper.GetInt64("timeout"),
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String("recp", "", "Recipient id")
+	cmd.Flags().String("asset", "", "List of assets to lock")
+	cmd.Flags().String("hash", "", "Hex SHA-256 hash of the secret preimage")
+	cmd.Flags().Int64("timeout", 0, "Block height from which the sender may refund")
+
+	return cmd
+}
+
+func ClaimHTLCCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claim [id] [preimage]",
+		Short: "Take over the assets of an HTLC by revealing the hex preimage",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			preimage, err := hex.DecodeString(args[1])
+			if err != nil {
+				return err
+			}
+			msg := &htlc.MsgClaimHTLC{
+				RecipientAccount: accAddress,
+				Recipient:        types.UserId(accAddress),
+				HTLC:             args[0],
+				Preimage:         preimage,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func RefundHTLCCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refund [id]",
+		Short: "Take back the assets of a timed out HTLC",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := &htlc.MsgRefundHTLC{
+				SenderAccount: accAddress,
+				Sender:        types.UserId(accAddress),
+				HTLC:          args[0],
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
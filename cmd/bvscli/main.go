@@ -26,6 +26,7 @@ import (
 	bankcli "github.com/cosmos/cosmos-sdk/x/bank/client/cli"
 	//bankclient "github.com/cosmos/cosmos-sdk/x/bank/client"
 	ibccli "github.com/cosmos/cosmos-sdk/x/ibc/client/cli"
+	slashingcli "github.com/cosmos/cosmos-sdk/x/slashing/client/cli"
 	stakecli "github.com/cosmos/cosmos-sdk/x/stake/client/cli"
 
 	"github.com/dcgraph/bvs-cosmos/app"
@@ -65,24 +66,92 @@ func main() {
 			stakecli.GetCmdQueryValidators("stake", cdc),
 			stakecli.GetCmdQueryDelegation("stake", cdc),
 			stakecli.GetCmdQueryDelegations("stake", cdc),
+			slashingcli.GetCmdQuerySigningInfo("slashing", cdc),
 			authcli.GetAccountCmd("acc", cdc, types.GetAccountDecoder(cdc)),
+			GetBalanceCmd(),
 			GetCodexCmd("codex", cdc),
 			GetVoucherCmd("voucher", cdc),
+			GetPendingTransfersCmd("pending", cdc),
+			GetAllowancesCmd("allowance", cdc),
+			GetDisputeCmd("dispute", cdc),
+			GetDisputesCmd("dispute", cdc),
+			GetMultisigCmd("multisig", cdc),
+			GetProposalCmd("multisig", cdc),
+			GetProposalsCmd("multisig", cdc),
+			GetListingCmd("market", cdc),
+			GetListingsCmd("market", cdc),
+			GetOrderCmd("market", cdc),
+			GetOrdersCmd("market", cdc),
+			GetDepthCmd("market", cdc),
+			GetPoolCmd(),
+			GetPoolSharesCmd(),
+			GetQuoteCmd(),
+			GetFeePolicyCmd(),
+			GetCommunityPoolCmd(),
+			GetBlockFeesCmd(),
+			GetGovProposalCmd("gov", cdc),
+			GetGovProposalsCmd("gov", cdc),
+			GetGovParamsCmd("gov", cdc),
+			GetParamsCmd("main", cdc),
+			GetUpgradeCmd("gov", "main", cdc),
 		)...)
 	rootCmd.AddCommand(client.LineBreak)
 
 	rootCmd.AddCommand(
 		client.PostCommands(
 			bankcli.SendTxCmd(cdc), // TODO
-			//BvsSendCmd(cdc), // TODO
+			BvsSendCmd(cdc),
+			SpendVoucherCmd(cdc),
+			ReloadVoucherCmd(cdc),
+			ApproveTransferCmd(cdc),
+			AcceptTransferCmd(cdc),
+			ReclaimTransferCmd(cdc),
+			SettleExpiredCmd(cdc),
+			WithdrawCmd(cdc),
+			RestockCmd(cdc),
+			TransferOwnershipCmd(cdc),
+			ApproveCmd(cdc),
+			RevokeCmd(cdc),
+			TransferFromCmd(cdc),
+			PublishCodesCmd(cdc),
+			CommitClaimCmd(cdc),
+			ClaimCmd(cdc),
+			RequestRefundCmd(cdc),
+			ApproveRefundCmd(cdc),
+			RejectRefundCmd(cdc),
+			OpenDisputeCmd(cdc),
+			RuleDisputeCmd(cdc),
+			CloseCodexCmd(cdc),
+			DeclareDefaultCmd(cdc),
+			ClaimCompensationCmd(cdc),
+			CreateMultisigCmd(cdc),
+			ProposeCmd(cdc),
+			ApproveProposalCmd(cdc),
+			ListVoucherCmd(cdc),
+			BuyListingCmd(cdc),
+			DelistCmd(cdc),
+			BidCmd(cdc),
+			AskCmd(cdc),
+			CancelOrderCmd(cdc),
+			AddLiquidityCmd(cdc),
+			RemoveLiquidityCmd(cdc),
+			SwapCmd(cdc),
+			SubmitProposalCmd(cdc),
+			DepositCmd(cdc),
+			VoteCmd(cdc),
 			ibccli.IBCTransferCmd(cdc),
 			ibccli.IBCRelayCmd(cdc),
 			stakecli.GetCmdCreateValidator(cdc),
 			stakecli.GetCmdEditValidator(cdc),
 			stakecli.GetCmdDelegate(cdc),
 			stakecli.GetCmdUnbond("stake", cdc),
+			UnjailCmd(cdc),
 		)...)
 
+	rootCmd.AddCommand(
+		HTLCCmd("htlc", cdc),
+	)
+
 	// add proxy, version and key info
 	rootCmd.AddCommand(
 		client.LineBreak,
@@ -169,6 +238,56 @@ func GetVoucherCmd(storeName string, cdc *wire.Codec) *cobra.Command {
 	}
 }
 
+func GetPendingTransfersCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pending-transfers",
+		Short: "Query pending transfers by sender or recipient",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var prefix []byte
+			if sender := viper.GetString("sender"); sender != "" {
+				prefix = types.PendingBySenderPrefix(sender)
+			} else if recp := viper.GetString("recp"); recp != "" {
+				prefix = types.PendingByRecipientPrefix(recp)
+			} else {
+				return errors.Errorf("Either --sender or --recp is required.")
+			}
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			kvs, err := cliCtx.QuerySubspace(prefix, storeName)
+			if err != nil {
+				return err
+			}
+
+			pendings := []*types.PendingTransfer{}
+			for _, kv := range kvs {
+				res, err := cliCtx.QueryStore(types.Id2StoreKey("pending:", string(kv.Value)), storeName)
+				if err != nil {
+					return err
+				}
+				pt := &types.PendingTransfer{}
+				err = cdc.UnmarshalBinaryBare(res, pt)
+				if err != nil {
+					return err
+				}
+				pendings = append(pendings, pt)
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, pendings)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("sender", "", "Id of the sender")
+	cmd.Flags().String("recp", "", "Id of the recipient")
+
+	return cmd
+}
+
 func BvsSendCmd(cdc *wire.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "send",
@@ -178,7 +297,7 @@ func BvsSendCmd(cdc *wire.Codec) *cobra.Command {
 			cliCtx := context.NewCLIContext().
 				WithCodec(cdc).
 				WithLogger(os.Stdout).
-				WithAccountDecoder(authcli.GetAccountDecoder(cdc))
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
 
 			if err := cliCtx.EnsureAccountExists(); err != nil {
 				return err
@@ -188,7 +307,7 @@ func BvsSendCmd(cdc *wire.Codec) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			sender := accAddress.String()
+			sender := types.UserId(accAddress)
 
 			// TODO: check if the recipient exists
 			recp := viper.GetString("recp")
@@ -199,7 +318,7 @@ func BvsSendCmd(cdc *wire.Codec) *cobra.Command {
 			if !types.IsOwner(sender, asset) {
 				return errors.Errorf("Can't send asset. Invalid ownership.")
 			}
-			msg := shop.BuildBvsMsg(accAddress, sender, recp, asset)
+			msg := shop.BuildPendingBvsMsg(accAddress, sender, recp, asset, viper.GetInt("pending-for"))
 
 			if len(asset.Coins) > 0 {
 				// ensure account has enough coins
@@ -219,6 +338,165 @@ func BvsSendCmd(cdc *wire.Codec) *cobra.Command {
 
 	cmd.Flags().String("recp", "", "Recipient address")
 	cmd.Flags().String("asset", "", "List of assets to send")
+	cmd.Flags().Int("pending-for", 0, "Number of blocks the recipient has to accept the assets, if sent in pending mode")
+
+	return cmd
+}
+
+func SpendVoucherCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spend [voucher] [amount]",
+		Short: "Spend part of the balance of a stored-value voucher",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			amount, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+			msg := shop.BuildSpendVoucherMsg(accAddress, types.UserId(accAddress), args[0], amount)
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func ReloadVoucherCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reload [voucher] [amount]",
+		Short: "Top up the balance of a stored-value voucher",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			amount, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+			msg := shop.BuildReloadVoucherMsg(accAddress, types.UserId(accAddress), args[0], amount)
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func ApproveTransferCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve-transfer [voucher] [recipient]",
+		Short: "Allow a voucher of an owned codex to be transferred to a user",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := shop.BuildApproveTransferMsg(accAddress, types.UserId(accAddress), args[0], args[1])
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func AcceptTransferCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "accept-transfer [pending]",
+		Short: "Accept the assets of a pending transfer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := shop.BuildAcceptTransferMsg(accAddress, types.UserId(accAddress), args[0])
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func ReclaimTransferCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reclaim-transfer [pending]",
+		Short: "Take back the assets of an unaccepted pending transfer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := shop.BuildReclaimTransferMsg(accAddress, types.UserId(accAddress), args[0])
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func SettleExpiredCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "settle-expired [voucher]",
+		Short: "Settle the proceeds and balance of an expired voucher",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := shop.BuildSettleExpiredMsg(accAddress, types.UserId(accAddress), args[0])
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
 
 	return cmd
 }
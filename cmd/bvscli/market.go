@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/market"
+)
+
+func GetListingCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "listing [id]",
+		Short: "Query a voucher listed for sale",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.ListingKey(id), storeName)
+			if err != nil {
+				return err
+			} else if len(res) == 0 {
+				return fmt.Errorf("No listing found with the id %s", id)
+			}
+
+			l := &types.Listing{}
+			err = cdc.UnmarshalBinaryBare(res, l)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, l)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+func GetListingsCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "listings",
+		Short: "Query listings by codex, or by price from the cheapest",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			var prefix []byte
+			if codex := viper.GetString("codex"); codex != "" {
+				prefix = types.ListingByCodexPrefix(codex)
+			} else if denom := viper.GetString("denom"); denom != "" {
+				prefix = types.ListingByPricePrefix(denom)
+			} else {
+				return errors.Errorf("Either --codex or --denom is required.")
+			}
+			kvs, err := cliCtx.QuerySubspace(prefix, storeName)
+			if err != nil {
+				return err
+			}
+
+			maxPrice := viper.GetInt64("max-price")
+			listings := []*types.Listing{}
+			for _, kv := range kvs {
+				res, err := cliCtx.QueryStore(types.ListingKey(string(kv.Value)), storeName)
+				if err != nil {
+					return err
+				}
+				l := &types.Listing{}
+				err = cdc.UnmarshalBinaryBare(res, l)
+				if err != nil {
+					return err
+				}
+				if maxPrice > 0 && l.Price.Amount.Int64() > maxPrice {
+					continue
+				}
+				listings = append(listings, l)
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, listings)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("codex", "", "Id of the codex")
+	cmd.Flags().String("denom", "", "Denomination of the price")
+	cmd.Flags().Int64("max-price", 0, "Highest price to show, if positive")
+
+	return cmd
+}
+
+func ListVoucherCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [voucher] [price]",
+		Short: "Put a voucher up for sale at a price in bvs or bvg",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			price, err := sdk.ParseCoin(args[1])
+			if err != nil {
+				return err
+			}
+			msg := &market.MsgList{
+				SellerAccount: accAddress,
+				Seller:        types.UserId(accAddress),
+				Voucher:       args[0],
+				Price:         price,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func BuyListingCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "buy [listing]",
+		Short: "Buy a listed voucher at its price",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := &market.MsgBuy{
+				BuyerAccount: accAddress,
+				Buyer:        types.UserId(accAddress),
+				Listing:      args[0],
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func DelistCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delist [listing]",
+		Short: "Take a listed voucher off the market",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := &market.MsgDelist{
+				SellerAccount: accAddress,
+				Seller:        types.UserId(accAddress),
+				Listing:       args[0],
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/multisig"
+)
+
+func GetMultisigCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "multisig [id]",
+		Short: "Query a multisig",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.MultisigKey(id), storeName)
+			if err != nil {
+				return err
+			} else if len(res) == 0 {
+				return fmt.Errorf("No multisig found with the id %s", id)
+			}
+
+			ms := &types.Multisig{}
+			err = cdc.UnmarshalBinaryBare(res, ms)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, ms)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+func GetProposalCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "proposal [id]",
+		Short: "Query an open proposal and its approvals",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.ProposalKey(id), storeName)
+			if err != nil {
+				return err
+			} else if len(res) == 0 {
+				return fmt.Errorf("No open proposal found with the id %s", id)
+			}
+
+			p := &types.Proposal{}
+			err = cdc.UnmarshalBinaryBare(res, p)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, p)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
+
+func GetProposalsCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proposals",
+		Short: "Query the open proposals of a multisig",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			ms := viper.GetString("multisig")
+			if ms == "" {
+				return errors.Errorf("--multisig is required.")
+			}
+			kvs, err := cliCtx.QuerySubspace(types.ProposalByMultisigPrefix(ms), storeName)
+			if err != nil {
+				return err
+			}
+
+			proposals := []*types.Proposal{}
+			for _, kv := range kvs {
+				res, err := cliCtx.QueryStore(types.ProposalKey(string(kv.Value)), storeName)
+				if err != nil {
+					return err
+				}
+				p := &types.Proposal{}
+				err = cdc.UnmarshalBinaryBare(res, p)
+				if err != nil {
+					return err
+				}
+				proposals = append(proposals, p)
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, proposals)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("multisig", "", "Id of the multisig")
+
+	return cmd
+}
+
+func CreateMultisigCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create-multisig [threshold] [members]",
+		Short: "Create a multisig out of a comma separated list of user ids",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			threshold, err := strconv.Atoi(args[0])
+			if err != nil {
+				return err
+			}
+			msg := &multisig.MsgCreateMultisig{
+				CreatorAccount: accAddress,
+				Creator:        types.UserId(accAddress),
+				Members:        splitIds(args[1]),
+				Threshold:      threshold,
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
+
+func ProposeCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "propose [multisig] [msg-file]",
+		Short: "Propose a message on behalf of a multisig, read as JSON from a file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			bz, err := ioutil.ReadFile(args[1])
+			if err != nil {
+				return err
+			}
+			var proposed sdk.Msg
+			if err := cdc.UnmarshalJSON(bz, &proposed); err != nil {
+				return err
+			}
+			msg := buildProposeMsg(accAddress, args[0], proposed)
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().Int("expire-after", 0, "Number of blocks the proposal stays open for approvals")
+
+	return cmd
+}
+
+func buildProposeMsg(proposerAccount sdk.AccAddress, ms string, proposed sdk.Msg) sdk.Msg {
+	return &multisig.MsgPropose{
+		ProposerAccount: proposerAccount,
+		Proposer:        types.UserId(proposerAccount),
+		Multisig:        ms,
+		Msg:             proposed,
+		ExpireAfter:     viper.GetInt("expire-after"),
+	}
+}
+
+func ApproveProposalCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve-proposal [proposal]",
+		Short: "Approve a proposal of a multisig you are a member of",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithLogger(os.Stdout).
+				WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+			accAddress, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			msg := &multisig.MsgApproveProposal{
+				ApproverAccount: accAddress,
+				Approver:        types.UserId(accAddress),
+				Proposal:        args[0],
+			}
+
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authctx "github.com/cosmos/cosmos-sdk/x/auth/client/context"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+)
+
+// ownerCmd builds a command sending an owner message of a codex. With
+// --multisig, the message is proposed on behalf of the multisig owning the
+// codex instead.
+func ownerCmd(cdc *wire.Codec, cmd *cobra.Command, build func(ownerAccount sdk.AccAddress, owner string, args []string) (sdk.Msg, error)) *cobra.Command {
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		txCtx := authctx.NewTxContextFromCLI().WithCodec(cdc)
+		cliCtx := context.NewCLIContext().
+			WithCodec(cdc).
+			WithLogger(os.Stdout).
+			WithAccountDecoder(types.GetAccountDecoder(cdc))
+
+		accAddress, err := cliCtx.GetFromAddress()
+		if err != nil {
+			return err
+		}
+		ms := viper.GetString("multisig")
+		if ms == "" {
+			msg, err := build(accAddress, types.UserId(accAddress), args)
+			if err != nil {
+				return err
+			}
+			return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+		}
+
+		proposed, err := build(nil, ms, args)
+		if err != nil {
+			return err
+		}
+		msg := buildProposeMsg(accAddress, ms, proposed)
+
+		return utils.SendTx(txCtx, cliCtx, []sdk.Msg{msg})
+	}
+
+	cmd.Flags().String("multisig", "", "Propose the message on behalf of this multisig")
+	cmd.Flags().Int("expire-after", 0, "Number of blocks the proposal stays open for approvals")
+
+	return cmd
+}
+
+func WithdrawCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "withdraw [codex] [amount] [recipient]",
+		Short: "Take coins out of an owned codex",
+		Args:  cobra.ExactArgs(3),
+	}
+	return ownerCmd(cdc, cmd, func(ownerAccount sdk.AccAddress, owner string, args []string) (sdk.Msg, error) {
+		amount, err := sdk.ParseCoins(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return shop.BuildWithdrawMsg(ownerAccount, owner, args[0], amount, args[2]), nil
+	})
+}
+
+func RestockCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restock [codex] [count]",
+		Short: "Make more vouchers of an owned codex available for sale",
+		Args:  cobra.ExactArgs(2),
+	}
+	return ownerCmd(cdc, cmd, func(ownerAccount sdk.AccAddress, owner string, args []string) (sdk.Msg, error) {
+		count, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return shop.BuildRestockMsg(ownerAccount, owner, args[0], count), nil
+	})
+}
+
+func TransferOwnershipCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer-ownership [codex] [new-owner]",
+		Short: "Hand an owned codex over to a user or a multisig",
+		Args:  cobra.ExactArgs(2),
+	}
+	return ownerCmd(cdc, cmd, func(ownerAccount sdk.AccAddress, owner string, args []string) (sdk.Msg, error) {
+		return shop.BuildTransferOwnershipMsg(ownerAccount, owner, args[0], args[1]), nil
+	})
+}
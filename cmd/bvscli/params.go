@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+func GetParamsCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "params",
+		Short: "Query the settings of the bvs modules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.BvsParamsKey, storeName)
+			if err != nil {
+				return err
+			}
+
+			params := types.DefaultBvsParams()
+			if len(res) > 0 {
+				params = &types.BvsParams{}
+				err = cdc.UnmarshalBinaryBare(res, params)
+				if err != nil {
+					return err
+				}
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, params)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
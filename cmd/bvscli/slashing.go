@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/wire"
+	slashingcli "github.com/cosmos/cosmos-sdk/x/slashing/client/cli"
+)
+
+// UnjailCmd sends the unrevoke message of the slashing module, which puts a
+// jailed validator back in the validator set once its jail time is over.
+func UnjailCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := slashingcli.GetCmdUnrevoke(cdc)
+	cmd.Use = "unjail"
+	cmd.Aliases = []string{"unrevoke"}
+	cmd.Short = "Unjail a validator jailed for downtime or double-signing"
+	return cmd
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+func GetUpgradeCmd(govStoreName string, mainStoreName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade",
+		Short: "Query the upgrade plan and the schema version of the stores",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryStore(types.UpgradePlanKey, govStoreName)
+			if err != nil {
+				return err
+			}
+			var plan *types.UpgradePlan
+			if len(res) > 0 {
+				plan = &types.UpgradePlan{}
+				err = cdc.UnmarshalBinaryBare(res, plan)
+				if err != nil {
+					return err
+				}
+			}
+
+			res, err = cliCtx.QueryStore(types.SchemaVersionKey, mainStoreName)
+			if err != nil {
+				return err
+			}
+			version := 1
+			if len(res) > 0 {
+				err = cdc.UnmarshalBinaryBare(res, &version)
+				if err != nil {
+					return err
+				}
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, struct {
+				Plan          *types.UpgradePlan `json:"plan"`
+				SchemaVersion int                `json:"schema-version"`
+			}{plan, version})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+
+			return nil
+		},
+	}
+}
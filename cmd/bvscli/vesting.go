@@ -0,0 +1,13 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func GetBalanceCmd() *cobra.Command {
+	return customQueryCmd(&cobra.Command{
+		Use:   "balance [address]",
+		Short: "Query the coins of an account, vested and locked",
+		Args:  cobra.ExactArgs(1),
+	}, func(args []string) string { return "custom/vesting/balance/" + args[0] })
+}
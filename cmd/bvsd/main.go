@@ -18,6 +18,11 @@ import (
 	"github.com/dcgraph/bvs-cosmos/app"
 )
 
+const (
+	flagHeight        = "height"
+	flagForZeroHeight = "for-zero-height"
+)
+
 func main() {
 	cdc := app.MakeCodec()
 	ctx := server.NewDefaultContext()
@@ -32,11 +37,20 @@ func main() {
 		server.ConstructAppCreator(newApp, "bvs"),
 		server.ConstructAppExporter(exportAppStateAndTMValidators, "bvs"))
 
+	rootCmd.AddCommand(ValidateGenesisCmd(ctx, cdc))
+
+	exportCmd, _, err := rootCmd.Find([]string{"export"})
+	if err != nil {
+		panic(err)
+	}
+	exportCmd.Flags().Int64(flagHeight, -1, "Export the state committed at this height instead of the latest")
+	exportCmd.Flags().Bool(flagForZeroHeight, false, "Rebase the heights in the state for a new chain starting from height zero")
+
 	// prepare and add flags
 	rootDir := os.ExpandEnv("$HOME/.bvsd")
 	executor := cli.PrepareBaseCmd(rootCmd, "BC", rootDir)
 
-	err := executor.Execute()
+	err = executor.Execute()
 	if err != nil {
 		// Note: Handle with #870
 		panic(err)
@@ -49,5 +63,10 @@ func newApp(logger log.Logger, db dbm.DB, storeTracer io.Writer) abci.Applicatio
 
 func exportAppStateAndTMValidators(logger log.Logger, db dbm.DB, storeTracer io.Writer) (json.RawMessage, []tmtypes.GenesisValidator, error) {
 	bapp := app.NewBvsApp(logger, db)
-	return bapp.ExportAppStateAndValidators()
+	if height := viper.GetInt64(flagHeight); height != -1 {
+		if err := bapp.LoadHeight(height); err != nil {
+			return nil, nil, err
+		}
+	}
+	return bapp.ExportAppStateAndValidators(viper.GetBool(flagForZeroHeight))
 }
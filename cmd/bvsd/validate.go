@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// ValidateGenesisCmd checks a genesis file, by default the one of the node,
+// as InitChain does before the chain starts from it.
+func ValidateGenesisCmd(ctx *server.Context, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate-genesis [genesis-file]",
+		Short: "Check the app state of a genesis file, listing every violation found",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file := ctx.Config.GenesisFile()
+			if len(args) == 1 {
+				file = args[0]
+			}
+			doc, err := tmtypes.GenesisDocFromFile(file)
+			if err != nil {
+				return err
+			}
+			genesisState := types.GenesisState{}
+			if err := cdc.UnmarshalJSON(doc.AppState, &genesisState); err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+			if err := genesisState.Validate(); err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+			fmt.Printf("%s is valid\n", file)
+			return nil
+		},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/tendermint/tendermint/crypto"
 )
 
 var _ auth.Account = (*UserAccount)(nil)
@@ -22,6 +23,15 @@ type UserAccount struct {
 func (acc UserAccount) GetId() string    { return acc.Id }
 func (acc *UserAccount) SetId(id string) { acc.Id = id }
 
+// AccountId returns the id of an account, which is empty unless it extends
+// UserAccount.
+func AccountId(acc auth.Account) string {
+	if acc, ok := acc.(interface{ GetId() string }); ok {
+		return acc.GetId()
+	}
+	return ""
+}
+
 // NewUserAccount returns a reference to a new UserAccount given an id and an
 // auth.BaseAccount.
 func NewUserAccount(id string, baseAcct auth.BaseAccount) *UserAccount {
@@ -36,7 +46,7 @@ func GetAccountDecoder(cdc *wire.Codec) auth.AccountDecoder {
 			return nil, sdk.ErrTxDecode("accBytes are empty")
 		}
 
-		acct := new(UserAccount)
+		var acct auth.Account
 		err := cdc.UnmarshalBinaryBare(accBytes, &acct)
 		if err != nil {
 			panic(err)
@@ -50,20 +60,30 @@ func GetAccountDecoder(cdc *wire.Codec) auth.AccountDecoder {
 // Handling genesis.json
 
 // GenesisAccount reflects a genesis account the application expects in it's
-// genesis state.
+// genesis state. An account with a Vesting schedule has its coins locked
+// until they vest. AccountNumber, Sequence and PubKey are those of an
+// exported account, and are left empty in a new genesis state.
 type GenesisAccount struct {
-	Id      string         `json:"id"`
-	Address sdk.AccAddress `json:"address"`
-	Coins   sdk.Coins      `json:"coins"`
+	Id            string           `json:"id"`
+	Address       sdk.AccAddress   `json:"address"`
+	Coins         sdk.Coins        `json:"coins"`
+	Vesting       *VestingSchedule `json:"vesting,omitempty"`
+	AccountNumber int64            `json:"account-number"`
+	Sequence      int64            `json:"sequence"`
+	PubKey        crypto.PubKey    `json:"pub-key"`
 }
 
 // NewGenesisAccount returns a reference to a new GenesisAccount given an
-// UserAccount.
-func NewGenesisAccount(aa *UserAccount) *GenesisAccount {
+// account, usually an UserAccount.
+func NewGenesisAccount(acc auth.Account) *GenesisAccount {
 	return &GenesisAccount{
-		Id:      aa.Id,
-		Address: aa.Address,
-		Coins:   aa.Coins.Sort(),
+		Id:            AccountId(acc),
+		Address:       acc.GetAddress(),
+		Coins:         acc.GetCoins().Sort(),
+		Vesting:       NewVestingSchedule(acc),
+		AccountNumber: acc.GetAccountNumber(),
+		Sequence:      acc.GetSequence(),
+		PubKey:        acc.GetPubKey(),
 	}
 }
 
@@ -72,8 +92,21 @@ func (ga *GenesisAccount) ToUserAccount() (acc *UserAccount, err error) {
 	return &UserAccount{
 		Id: ga.Id,
 		BaseAccount: auth.BaseAccount{
-			Address: ga.Address,
-			Coins:   ga.Coins.Sort(),
+			Address:       ga.Address,
+			Coins:         ga.Coins.Sort(),
+			PubKey:        ga.PubKey,
+			AccountNumber: ga.AccountNumber,
+			Sequence:      ga.Sequence,
 		},
 	}, nil
 }
+
+// ToAccount converts a GenesisAccount to an UserAccount, or to a vesting
+// account extending it if the account has a vesting schedule.
+func (ga *GenesisAccount) ToAccount() (auth.Account, error) {
+	acc, err := ga.ToUserAccount()
+	if err != nil || ga.Vesting == nil {
+		return acc, err
+	}
+	return ga.Vesting.toAccount(acc)
+}
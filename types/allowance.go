@@ -0,0 +1,42 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// An Allowance lets a spender move assets of an owner on the owner's behalf.
+// Coins is the remaining amount of coins the spender may move. Vouchers lists
+// individual vouchers the spender may move, while any voucher issued by one
+// of Codices may be moved. An allowance with zero ExpireOn never expires.
+type Allowance struct {
+	Owner    string    `json:"owner"`
+	Spender  string    `json:"spender"`
+	Coins    sdk.Coins `json:"coins"`
+	Vouchers []string  `json:"vouchers"`
+	Codices  []string  `json:"codices"`
+	ExpireOn int       `json:"expire-on"`
+}
+
+// IsExpired tells whether the allowance is no longer valid at the given
+// height.
+func (a *Allowance) IsExpired(height int64) bool {
+	return a.ExpireOn != 0 && height > int64(a.ExpireOn)
+}
+
+// AllowanceKey returns the store key of the allowance an owner granted to a
+// spender.
+func AllowanceKey(owner string, spender string) []byte {
+	return Id2StoreKey("allowance:", owner+"/"+spender)
+}
+
+// AllowanceByOwnerPrefix returns the store prefix of allowances granted by an
+// owner.
+func AllowanceByOwnerPrefix(owner string) []byte {
+	return Id2StoreKey("allowance:", owner+"/")
+}
+
+// AllowanceBySpenderPrefix returns the store prefix indexing allowances
+// granted to a spender.
+func AllowanceBySpenderPrefix(spender string) []byte {
+	return Id2StoreKey("allowance-spender:", spender+"/")
+}
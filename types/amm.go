@@ -0,0 +1,46 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// A Pool holds silver and gold reserves for swaps between the two, priced by
+// the constant product of the reserves. Providers of liquidity own Shares of
+// the reserves.
+type Pool struct {
+	Silver sdk.Int `json:"silver"`
+	Gold   sdk.Int `json:"gold"`
+	Shares sdk.Int `json:"shares"`
+}
+
+// NewPool returns an empty pool.
+func NewPool() *Pool {
+	return &Pool{Silver: sdk.ZeroInt(), Gold: sdk.ZeroInt(), Shares: sdk.ZeroInt()}
+}
+
+// IsEmpty tells whether the pool has no liquidity to swap against.
+func (p *Pool) IsEmpty() bool {
+	return p.Shares.IsZero()
+}
+
+// Reserve returns the reserve of the pool in a denomination.
+func (p *Pool) Reserve(denom string) sdk.Int {
+	if denom == DenomGold {
+		return p.Gold
+	}
+	return p.Silver
+}
+
+// A PoolShare is the part of the pool owned by a liquidity provider.
+type PoolShare struct {
+	Provider string  `json:"provider"`
+	Shares   sdk.Int `json:"shares"`
+}
+
+// PoolKey is the store key of the pool.
+var PoolKey = []byte("pool")
+
+// PoolShareKey returns the store key of the shares of a provider.
+func PoolShareKey(provider string) []byte {
+	return Id2StoreKey("pool-share:", provider)
+}
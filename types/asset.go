@@ -1,5 +1,11 @@
 package types
 
+// Denominations of the two base coins of BVS.
+const (
+	DenomSilver = "bvs"
+	DenomGold   = "bvg"
+)
+
 // An Asset is for representing an arbitrary asset including Silver, Gold and
 // Voucher.
 type Asset interface {
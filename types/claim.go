@@ -0,0 +1,69 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// A ClaimCampaign lets anyone holding one of the claim codes published by a
+// codex owner receive a voucher of the codex. A code is accepted if its
+// ClaimCodeHash is one of Hashes, or if a Merkle proof links the hash to one of
+// Roots. Each code can be used once. With RequireCommit set, a claim must be
+// preceded by a ClaimCommit made in an earlier block, so that a claim seen in
+// the mempool cannot be front-run.
+//
+// Only the campaign settings and Roots are kept in the campaign record; the
+// Hashes and Used lists are filled in when exporting genesis state.
+type ClaimCampaign struct {
+	Codex         string   `json:"codex"`
+	RequireCommit bool     `json:"require-commit"`
+	Roots         [][]byte `json:"roots"`
+	Hashes        [][]byte `json:"hashes"` // unused codes
+	Used          [][]byte `json:"used"`   // hashes of used codes
+}
+
+// A ClaimCommit binds a claimer to a code without revealing it. Commitment is
+// ClaimCommitment of the code and the claimer.
+type ClaimCommit struct {
+	Commitment []byte `json:"commitment"`
+	Claimer    string `json:"claimer"`
+	Height     int64  `json:"height"`
+}
+
+// Leaves and inner nodes of claim Merkle trees are hashed under different
+// prefixes, so that the two children of a node cannot pass for a claim code.
+const (
+	merkleLeafPrefix byte = 0x00
+	merkleNodePrefix byte = 0x01
+)
+
+// ClaimCodeHash returns the hash under which a claim code is published, which
+// is also its leaf hash in a Merkle tree.
+func ClaimCodeHash(code []byte) []byte {
+	h := sha256.Sum256(append([]byte{merkleLeafPrefix}, code...))
+	return h[:]
+}
+
+// ClaimCommitment returns the commitment of a claimer to a claim code.
+func ClaimCommitment(code []byte, claimer string) []byte {
+	h := sha256.Sum256(append(append([]byte{}, code...), []byte(claimer)...))
+	return h[:]
+}
+
+// MerkleRoot computes the root of a Merkle tree from a leaf and the sibling
+// hashes on the path to the root. Each pair of nodes is hashed in sorted
+// order, so the proof needs no left/right flags.
+func MerkleRoot(leaf []byte, proof [][]byte) []byte {
+	node := leaf
+	for _, sibling := range proof {
+		pair := []byte{merkleNodePrefix}
+		if bytes.Compare(node, sibling) <= 0 {
+			pair = append(append(pair, node...), sibling...)
+		} else {
+			pair = append(append(pair, sibling...), node...)
+		}
+		h := sha256.Sum256(pair)
+		node = h[:]
+	}
+	return node
+}
@@ -1,22 +1,120 @@
 package types
 
 import (
+	"fmt"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 // A Codex is a service account which is responsible for issueing a new voucher
 // according to the value description.
 type Codex struct {
-	Id          string    `json:"id"`
-	Owner       string    `json:"owner"`
-	Value       string    `json:"value"`
-	UnitPrice   int       `json:"unit-price"`
-	SaleType    string    `json:"sale-type"`
-	ExpireAfter int       `json:"expire-after"`
-	Deposit     int       `json:"deposit"` // synced to Coins
-	CountAvail  int       `json:"count-avail"`
-	CountLive   int       `json:"count-live"`
-	Coins       sdk.Coins `json:"coins"`
+	Id           string `json:"id"`
+	Owner        string `json:"owner"`
+	Value        string `json:"value"`
+	UnitPrice    int    `json:"unit-price"`
+	SaleType     string `json:"sale-type"`
+	ExpireAfter  int    `json:"expire-after"`
+	Deposit      int    `json:"deposit"` // synced to Coins
+	CountAvail   int    `json:"count-avail"`
+	CountLive    int    `json:"count-live"`
+	CountIssued  int    `json:"count-issued"`
+	StoredValue  bool   `json:"stored-value"`  // vouchers carry a balance
+	Escrowed     int    `json:"escrowed"`      // silver of the balances, not part of Deposit
+	Transfer     string `json:"transfer"`      // transferability policy
+	RefundWindow int    `json:"refund-window"` // blocks after issue a refund may be requested
+	Arbiter      string `json:"arbiter"`       // rules disputes over vouchers, if set
+	LastActive   int    `json:"last-active"`   // height of the last action of the owner
+	Status       string `json:"status"`
+	Share        int    `json:"share"` // silver owed per voucher once settled
+
+	// Royalty is the percentage of the price of a resold voucher owed to
+	// RoyaltyTo, or to the codex owner if RoyaltyTo is empty.
+	Royalty   int    `json:"royalty"`
+	RoyaltyTo string `json:"royalty-to"`
+
+	// With EscrowProceeds set, the price of a voucher is kept by the codex as
+	// unearned revenue until the voucher is redeemed. Unearned is not part
+	// of Deposit. Breakage tells where the proceeds of expired vouchers go.
+	EscrowProceeds bool   `json:"escrow-proceeds"`
+	Unearned       int    `json:"unearned"`
+	Breakage       string `json:"breakage"`
+
+	Coins sdk.Coins `json:"coins"`
+}
+
+// Statuses of a codex. Once a codex is settled for any of the reasons below,
+// it sells no more vouchers and holders of its vouchers may claim a share of
+// its deposit.
+const (
+	CodexActive    = ""
+	CodexClosed    = "closed"
+	CodexAbandoned = "abandoned"
+	CodexDefaulted = "defaulted"
+)
+
+// Breakage policies of a codex escrowing proceeds. An empty policy is the
+// same as BreakageMerchant.
+const (
+	BreakageMerchant = "merchant" // released to the codex owner
+	BreakageRefund   = "refund"   // refunded to the holder
+)
+
+// Transferability policies of vouchers issued by a codex. An empty policy is
+// the same as TransferFree.
+const (
+	TransferFree          = "free"
+	TransferOwnerApproved = "owner-approved"
+	TransferNone          = "non-transferable"
+)
+
+// TransferPolicy returns the effective transferability policy of the codex.
+func (cod *Codex) TransferPolicy() string {
+	if cod.Transfer == "" {
+		return TransferFree
+	}
+	return cod.Transfer
+}
+
+// SyncDeposit updates Deposit to the silver held in Coins, less the unearned
+// proceeds and the escrowed balances.
+func (cod *Codex) SyncDeposit() {
+	cod.Deposit = cod.DepositOf(cod.Coins)
+}
+
+// DepositOf returns the deposit the codex would have if it held the given
+// coins.
+func (cod *Codex) DepositOf(coins sdk.Coins) int {
+	return int(coins.AmountOf(DenomSilver).Int64()) - cod.Unearned - cod.Escrowed
+}
+
+// CheckRoyalty tells whether the royalty of the codex is a percentage from 0
+// to 100, owed to a user if RoyaltyTo is set.
+func (cod *Codex) CheckRoyalty() error {
+	if cod.Royalty < 0 || cod.Royalty > 100 {
+		return fmt.Errorf("the royalty of %d%% is not between 0 and 100", cod.Royalty)
+	}
+	if cod.RoyaltyTo != "" && IdKind(cod.RoyaltyTo) != KindUser {
+		return fmt.Errorf("the royalty is owed to %s, which is not a user", cod.RoyaltyTo)
+	}
+	return nil
+}
+
+// RoyaltyOf returns the royalty owed on a resale of a voucher at the given
+// price. Fractions are rounded down, and a royalty failing CheckRoyalty owes
+// nothing.
+func (cod *Codex) RoyaltyOf(price sdk.Coins) sdk.Coins {
+	var royalty sdk.Coins
+	if cod.Royalty <= 0 || cod.CheckRoyalty() != nil {
+		return royalty
+	}
+	for _, coin := range price {
+		amt := coin.Amount.MulRaw(int64(cod.Royalty)).DivRaw(100)
+		if !amt.IsZero() {
+			royalty = append(royalty, sdk.Coin{Denom: coin.Denom, Amount: amt})
+		}
+	}
+	return royalty
 }
 
 // CodexDef is a definition of a new codex to be created.
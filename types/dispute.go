@@ -0,0 +1,50 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Outcomes of a dispute.
+const (
+	DisputeOpen        = "open"
+	DisputeForHolder   = "holder"
+	DisputeForMerchant = "merchant"
+)
+
+// A Dispute is opened by the holder of a voucher the merchant refuses to
+// honor. While the dispute is open the voucher is held by the dispute's escrow
+// id. Closed disputes are kept as a record of the ruling.
+type Dispute struct {
+	Id           string    `json:"id"`
+	Voucher      string    `json:"voucher"`
+	Codex        string    `json:"codex"`
+	Holder       string    `json:"holder"`
+	Reason       string    `json:"reason"`
+	OpenedOn     int       `json:"opened-on"`
+	Outcome      string    `json:"outcome"`
+	Arbiter      string    `json:"arbiter"` // who ruled
+	Ruling       string    `json:"ruling"`
+	Compensation sdk.Coins `json:"compensation"` // paid to the holder
+	RuledOn      int       `json:"ruled-on"`
+}
+
+// DisputeKey returns the store key of a dispute.
+func DisputeKey(id string) []byte {
+	return Id2StoreKey("dispute:", id)
+}
+
+// DisputeByCodexPrefix returns the store prefix indexing disputes over the
+// vouchers of a codex.
+func DisputeByCodexPrefix(codex string) []byte {
+	return Id2StoreKey("dispute-codex:", codex+"/")
+}
+
+// DisputeByHolderPrefix returns the store prefix indexing disputes opened by
+// a holder.
+func DisputeByHolderPrefix(holder string) []byte {
+	return Id2StoreKey("dispute-holder:", holder+"/")
+}
+
+// ArbitersKey is the store key of the chain-wide arbiter set, which rules
+// disputes over vouchers of codices naming no arbiter.
+var ArbitersKey = []byte("arbiters")
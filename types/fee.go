@@ -0,0 +1,51 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// A FeePolicy rules over the fees of transactions and their distribution.
+// Every transaction pays at least MinFee silver. The fees collected in a
+// block go for CommunityShare percent to the community pool, and for the
+// rest to the validators in proportion to their power. Without Validators,
+// the fees go to the bonded validators of the stake module.
+type FeePolicy struct {
+	MinFee         int64           `json:"min-fee"`
+	CommunityShare int             `json:"community-share"`
+	Validators     []*FeeValidator `json:"validators"`
+}
+
+// A FeeValidator is paid its share of the fees at its Payee, a user id.
+type FeeValidator struct {
+	Payee string `json:"payee"`
+	Power int64  `json:"power"`
+}
+
+// NewFeePolicy returns a policy with no minimum fee, which pays all the fees
+// to the bonded validators.
+func NewFeePolicy() *FeePolicy {
+	return &FeePolicy{Validators: []*FeeValidator{}}
+}
+
+// BlockFees are the fees collected in a block, and the part of them that
+// went to the community pool.
+type BlockFees struct {
+	Height    int64     `json:"height"`
+	Collected sdk.Coins `json:"collected"`
+	Community sdk.Coins `json:"community"`
+}
+
+var (
+	// FeePolicyKey is the store key of the fee policy.
+	FeePolicyKey = []byte("fee-policy")
+
+	// CommunityPoolKey is the store key of the community pool.
+	CommunityPoolKey = []byte("community-pool")
+)
+
+// BlockFeesKey returns the store key of the fees collected at a height.
+func BlockFeesKey(height int64) []byte {
+	return []byte(fmt.Sprintf("block-fees:%020d", height))
+}
@@ -1,8 +1,291 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+)
+
 // GenesisState reflects the genesis state of the application.
 type GenesisState struct {
-	Accounts []*GenesisAccount `json:"accounts"`
-	Codices  []*Codex          `json:"codices"`
-	Vouchers []*Voucher        `json:"vouchers"`
+	Params *BvsParams `json:"params"`
+
+	Accounts []*GenesisAccount  `json:"accounts"`
+	Codices  []*Codex           `json:"codices"`
+	Vouchers []*Voucher         `json:"vouchers"`
+	Pendings []*PendingTransfer `json:"pending-transfers"`
+
+	Allowances     []*Allowance     `json:"allowances"`
+	ClaimCampaigns []*ClaimCampaign `json:"claim-campaigns"`
+	ClaimCommits   []*ClaimCommit   `json:"claim-commits"`
+	HTLCs          []*HTLC          `json:"htlcs"`
+	RefundRequests []*RefundRequest `json:"refund-requests"`
+	Disputes       []*Dispute       `json:"disputes"`
+	Arbiters       []string         `json:"arbiters"`
+	Multisigs      []*Multisig      `json:"multisigs"`
+	Proposals      []*Proposal      `json:"proposals"`
+	Listings       []*Listing       `json:"listings"`
+	Orders         []*Order         `json:"orders"`
+	Pool           *Pool            `json:"pool"`
+	PoolShares     []*PoolShare     `json:"pool-shares"`
+	FeePolicy      *FeePolicy       `json:"fee-policy"`
+	CommunityPool  sdk.Coins        `json:"community-pool"`
+	BlockFees      []*BlockFees     `json:"block-fees"`
+	GovParams      *GovParams       `json:"gov-params"`
+	GovProposals   []*GovProposal   `json:"gov-proposals"`
+	GovVotes       []*GovVote       `json:"gov-votes"`
+	UpgradePlan    *UpgradePlan     `json:"upgrade-plan"`
+
+	StakeData *stake.GenesisState `json:"stake"`
+
+	// Stores holds the records the genesis states above leave out: the
+	// stores of the cosmos-sdk modules which have no genesis state of their
+	// own, and the id sequences of ours.
+	Stores []*StoreDump `json:"stores"`
+}
+
+// A StoreDump holds all the records of a store.
+type StoreDump struct {
+	Store string    `json:"store"`
+	Pairs []*KVPair `json:"pairs"`
+}
+
+// A KVPair is a record of a store.
+type KVPair struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// GenesisErrors lists the violations found in a genesis state.
+type GenesisErrors []error
+
+func (errs GenesisErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("%d violations in the genesis state:\n%s", len(errs), strings.Join(lines, "\n"))
+}
+
+// Validate checks that the records of the genesis state are consistent with
+// one another, and returns the GenesisErrors listing every violation found:
+// ids taken twice, vouchers of codices missing from the state, codices whose
+// CountLive or Deposit disagree with their vouchers or their Coins, codices
+// with unknown policies, royalties or arbiters not acting for a user, prices
+// too large for the market indexes, and accounts that cannot be set up as
+// given.
+func (gs *GenesisState) Validate() error {
+	var errs GenesisErrors
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	seen := map[string]map[string]bool{}
+	unique := func(what string, id string) {
+		if seen[what] == nil {
+			seen[what] = map[string]bool{}
+		}
+		if seen[what][id] {
+			fail("%s %s is given more than once", what, id)
+		}
+		seen[what][id] = true
+	}
+
+	if gs.Params != nil {
+		if err := gs.Params.Validate(); err != nil {
+			fail("params: %v", err)
+		}
+	}
+
+	for _, gacc := range gs.Accounts {
+		unique("account", gacc.Id)
+		unique("address", gacc.Address.String())
+		if _, err := gacc.ToAccount(); err != nil {
+			fail("account %s: %v", gacc.Id, err)
+		}
+	}
+
+	live, escrowed := map[string]int{}, map[string]int{}
+	for _, vou := range gs.Vouchers {
+		unique("voucher", vou.Id)
+		live[vou.Origin]++
+		escrowed[vou.Origin] += int(vou.Balance.AmountOf(DenomSilver).Int64())
+	}
+	codices := map[string]bool{}
+	for _, cod := range gs.Codices {
+		unique("codex", cod.Id)
+		codices[cod.Id] = true
+		if cod.CountLive != live[cod.Id] {
+			fail("codex %s counts %d live vouchers, but %d are given", cod.Id, cod.CountLive, live[cod.Id])
+		}
+		if cod.Escrowed != escrowed[cod.Id] {
+			fail("codex %s escrows %d for balances, but its vouchers carry %d", cod.Id, cod.Escrowed, escrowed[cod.Id])
+		}
+		if err := cod.CheckRoyalty(); err != nil {
+			fail("codex %s: %v", cod.Id, err)
+		}
+		switch cod.Transfer {
+		case "", TransferFree, TransferOwnerApproved, TransferNone:
+		default:
+			fail("codex %s has the unknown transfer policy %s", cod.Id, cod.Transfer)
+		}
+		switch cod.Breakage {
+		case "", BreakageMerchant, BreakageRefund:
+		default:
+			fail("codex %s has the unknown breakage policy %s", cod.Id, cod.Breakage)
+		}
+		if cod.Arbiter != "" && IdKind(cod.Arbiter) != KindUser {
+			fail("codex %s is arbitrated by %s, which is not a user", cod.Id, cod.Arbiter)
+		}
+		synced := *cod
+		synced.SyncDeposit()
+		if cod.Deposit != synced.Deposit {
+			fail("codex %s has a deposit of %d, but holds %s with %d unearned and %d escrowed",
+				cod.Id, cod.Deposit, cod.Coins, cod.Unearned, cod.Escrowed)
+		}
+	}
+	for _, vou := range gs.Vouchers {
+		if IdKind(vou.Origin) == KindCodex && !codices[vou.Origin] {
+			fail("voucher %s is of the missing codex %s", vou.Id, vou.Origin)
+		}
+	}
+
+	for _, pt := range gs.Pendings {
+		unique("pending transfer", pt.Id)
+	}
+	for _, c := range gs.ClaimCampaigns {
+		unique("claim campaign", c.Codex)
+	}
+	for _, h := range gs.HTLCs {
+		unique("htlc", h.Id)
+	}
+	for _, r := range gs.RefundRequests {
+		unique("refund request", r.Id)
+	}
+	for _, d := range gs.Disputes {
+		unique("dispute", d.Id)
+	}
+	for _, m := range gs.Multisigs {
+		unique("multisig", m.Id)
+	}
+	for _, p := range gs.Proposals {
+		unique("proposal", p.Id)
+	}
+	for _, l := range gs.Listings {
+		unique("listing", l.Id)
+		if !l.Price.Amount.IsInt64() {
+			fail("listing %s is priced at %s, more than the price index takes", l.Id, l.Price)
+		}
+	}
+	for _, o := range gs.Orders {
+		unique("order", o.Id)
+		if !o.Price.Amount.IsInt64() {
+			fail("order %s is priced at %s, more than the order book takes", o.Id, o.Price)
+		}
+	}
+	for _, p := range gs.GovProposals {
+		unique("gov proposal", p.Id)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ForZeroHeight rebases the heights in the genesis state of a chain exported
+// at a height, for a new chain starting over from height zero. Heights gone
+// by keep their distance to the new start and so turn negative, deadlines
+// passed move to height zero, so that whatever was due before the export is
+// due from the first block, and expiries passed are set to -1, as zero
+// stands for no expiry. The stake module, which orders validators by the
+// heights they bonded at, has them all reset to zero. The history of the
+// fees collected, which is of the old chain, is left out.
+func (gs *GenesisState) ForZeroHeight(height int64) {
+	since := func(h int64) int64 { return h - height }
+	until := func(h int64) int64 {
+		if h <= height {
+			return 0
+		}
+		return h - height
+	}
+	expiry := func(h int) int {
+		switch {
+		case h == 0:
+			return 0
+		case int64(h) <= height:
+			return -1
+		}
+		return h - int(height)
+	}
+
+	for _, gacc := range gs.Accounts {
+		if vs := gacc.Vesting; vs != nil {
+			vs.StartHeight, vs.EndHeight = since(vs.StartHeight), since(vs.EndHeight)
+		}
+	}
+	for _, cod := range gs.Codices {
+		cod.LastActive = int(since(int64(cod.LastActive)))
+	}
+	for _, vou := range gs.Vouchers {
+		vou.IssuedOn = int(since(int64(vou.IssuedOn)))
+		vou.ExpireOn = expiry(vou.ExpireOn)
+	}
+	for _, pt := range gs.Pendings {
+		pt.Deadline = int(until(int64(pt.Deadline)))
+	}
+	for _, a := range gs.Allowances {
+		a.ExpireOn = expiry(a.ExpireOn)
+	}
+	for _, c := range gs.ClaimCommits {
+		c.Height = since(c.Height)
+	}
+	for _, h := range gs.HTLCs {
+		h.Timeout = until(h.Timeout)
+	}
+	for _, r := range gs.RefundRequests {
+		r.Deadline = int(until(int64(r.Deadline)))
+	}
+	for _, d := range gs.Disputes {
+		d.OpenedOn = int(since(int64(d.OpenedOn)))
+		if d.RuledOn != 0 {
+			d.RuledOn = int(since(int64(d.RuledOn)))
+		}
+	}
+	for _, p := range gs.Proposals {
+		p.ExpireOn = int(until(int64(p.ExpireOn)))
+	}
+	for _, l := range gs.Listings {
+		l.ListedOn = int(since(int64(l.ListedOn)))
+	}
+	for _, o := range gs.Orders {
+		o.PlacedOn = int(since(int64(o.PlacedOn)))
+		o.ExpireOn = int(until(int64(o.ExpireOn)))
+	}
+	for _, p := range gs.GovProposals {
+		p.DepositEnd = int(until(int64(p.DepositEnd)))
+		p.VotingEnd = int(until(int64(p.VotingEnd)))
+	}
+	if gs.UpgradePlan != nil {
+		gs.UpgradePlan.Height = until(gs.UpgradePlan.Height)
+	}
+	gs.BlockFees = []*BlockFees{}
+
+	if gs.StakeData != nil {
+		for i := range gs.StakeData.Validators {
+			gs.StakeData.Validators[i].BondHeight = 0
+		}
+		for i := range gs.StakeData.Bonds {
+			gs.StakeData.Bonds[i].Height = 0
+		}
+	}
+}
+
+// DefaultStakeGenesis returns the stake state of a chain without validators
+// yet, bonding silver of which the accounts hold a total of loose.
+func DefaultStakeGenesis(loose sdk.Int) *stake.GenesisState {
+	data := stake.DefaultGenesisState()
+	data.Params.BondDenom = DenomSilver
+	data.Pool.LooseTokens = sdk.NewRatFromInt(loose)
+	return &data
 }
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Kinds of governance proposals.
+const (
+	ProposalText            = "text"
+	ProposalParamChange     = "param-change"
+	ProposalSoftwareUpgrade = "software-upgrade"
+)
+
+// Statuses of governance proposals.
+const (
+	ProposalDepositPeriod = "deposit-period"
+	ProposalVotingPeriod  = "voting-period"
+	ProposalPassed        = "passed"
+	ProposalRejected      = "rejected"
+	ProposalFailed        = "failed" // passed, but its changes could not apply
+)
+
+// Options of governance votes.
+const (
+	VoteYes        = "yes"
+	VoteNo         = "no"
+	VoteAbstain    = "abstain"
+	VoteNoWithVeto = "no-with-veto"
+)
+
+// GovParams rule over the governance. A proposal is voted on once its
+// deposits reach MinDeposit within DepositPeriod blocks, for VotingPeriod
+// blocks. It passes when the stake having voted is at least Quorum percent of
+// the bonded stake, Threshold percent of the stake voting other than abstain
+// votes yes, and less than Veto percent of the stake having voted vetoes it.
+type GovParams struct {
+	MinDeposit    sdk.Coins `json:"min-deposit"`
+	DepositPeriod int       `json:"deposit-period"`
+	VotingPeriod  int       `json:"voting-period"`
+	Quorum        int       `json:"quorum"`
+	Threshold     int       `json:"threshold"`
+	Veto          int       `json:"veto"`
+}
+
+// DefaultGovParams returns the governance rules of a new chain.
+func DefaultGovParams() *GovParams {
+	return &GovParams{
+		MinDeposit:    sdk.Coins{sdk.NewInt64Coin(DenomSilver, 1000)},
+		DepositPeriod: 10000,
+		VotingPeriod:  10000,
+		Quorum:        33,
+		Threshold:     50,
+		Veto:          33,
+	}
+}
+
+// A ParamChange sets the parameter Key to Value, encoded in JSON.
+type ParamChange struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// An UpgradePlan schedules a switch to the software Name at Height.
+type UpgradePlan struct {
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+}
+
+// A GovProposal is put to the vote of the stakers once enough is deposited on
+// it. Deposits are refunded when the vote is over, unless the proposal is
+// vetoed.
+type GovProposal struct {
+	Id          string        `json:"id"`
+	Kind        string        `json:"kind"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Changes     []ParamChange `json:"changes,omitempty"`
+	Upgrade     *UpgradePlan  `json:"upgrade,omitempty"`
+
+	Proposer string        `json:"proposer"`
+	Status   string        `json:"status"`
+	Deposits []*GovDeposit `json:"deposits"`
+	Tally    *TallyResult  `json:"tally,omitempty"`
+
+	// DepositEnd is the height the deposit period ends on, and VotingEnd the
+	// one the voting period ends on once it has started.
+	DepositEnd int `json:"deposit-end"`
+	VotingEnd  int `json:"voting-end"`
+}
+
+// TotalDeposit returns the sum of the deposits on the proposal.
+func (p *GovProposal) TotalDeposit() sdk.Coins {
+	total := sdk.Coins{}
+	for _, d := range p.Deposits {
+		total = total.Plus(d.Amount)
+	}
+	return total
+}
+
+// A GovDeposit is what a user deposited on a proposal.
+type GovDeposit struct {
+	Depositor string    `json:"depositor"`
+	Amount    sdk.Coins `json:"amount"`
+}
+
+// A GovVote is the vote of a staker on a proposal.
+type GovVote struct {
+	Proposal string `json:"proposal"`
+	Voter    string `json:"voter"`
+	Option   string `json:"option"`
+}
+
+// A TallyResult is the stake having voted each option on a proposal.
+type TallyResult struct {
+	Yes        sdk.Rat `json:"yes"`
+	No         sdk.Rat `json:"no"`
+	Abstain    sdk.Rat `json:"abstain"`
+	NoWithVeto sdk.Rat `json:"no-with-veto"`
+}
+
+var (
+	// GovParamsKey is the store key of the governance rules.
+	GovParamsKey = []byte("gov-params")
+
+	// UpgradePlanKey is the store key of the upgrade plan last passed.
+	UpgradePlanKey = []byte("upgrade-plan")
+)
+
+// GovProposalKey returns the store key of a governance proposal.
+func GovProposalKey(id string) []byte {
+	return Id2StoreKey("gov-proposal:", id)
+}
+
+// GovVotePrefix returns the store prefix of the votes on a proposal.
+func GovVotePrefix(proposal string) []byte {
+	return Id2StoreKey("gov-vote:", proposal+"/")
+}
+
+// GovVoteKey returns the store key of the vote of a staker on a proposal.
+func GovVoteKey(proposal string, voter string) []byte {
+	return append(GovVotePrefix(proposal), []byte(voter)...)
+}
+
+// GovProposalByEndPrefix returns the store prefix indexing proposals by the
+// height their current period ends on.
+func GovProposalByEndPrefix(end int) []byte {
+	return []byte(fmt.Sprintf("gov-proposal-end:%020d/", end))
+}
@@ -0,0 +1,23 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// An HTLC is a hash time-locked contract holding assets until the recipient
+// reveals the preimage of HashLock, or until Timeout when the sender may take
+// them back. Vouchers in an HTLC are held by the contract's escrow id.
+type HTLC struct {
+	Id        string    `json:"id"`
+	Sender    string    `json:"sender"`
+	Recipient string    `json:"recipient"`
+	Coins     sdk.Coins `json:"coins"`
+	Vouchers  []string  `json:"vouchers"`
+	HashLock  []byte    `json:"hash-lock"` // SHA-256 of the preimage
+	Timeout   int64     `json:"timeout"`   // first height at which a refund is allowed
+}
+
+// HTLCKey returns the store key of an HTLC.
+func HTLCKey(id string) []byte {
+	return Id2StoreKey("htlc:", id)
+}
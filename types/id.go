@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Every BVS entity is identified by a string id of the form
+// "<zone>:<kind>:<name>", e.g. "0:u:cosmosaccaddr1...", "0:c:codex0" or
+// "0:v:codex0:0".
+const (
+	KindUser     = "u"
+	KindCodex    = "c"
+	KindVoucher  = "v"
+	KindEscrow   = "e" // assets held on behalf of users by a module
+	KindMultisig = "m" // a threshold of users acting as one
+
+	DefaultZone = "0"
+)
+
+// SplitId splits an id into its zone, kind and name. It returns empty strings
+// when the id is malformed.
+func SplitId(id string) (zone string, kind string, name string) {
+	chunks := strings.SplitN(id, ":", 3)
+	if len(chunks) != 3 {
+		return "", "", ""
+	}
+	return chunks[0], chunks[1], chunks[2]
+}
+
+// IdKind returns the kind part of an id.
+func IdKind(id string) string {
+	_, kind, _ := SplitId(id)
+	return kind
+}
+
+// UserId returns the id of the user account associated with an address.
+func UserId(addr sdk.AccAddress) string {
+	return DefaultZone + ":" + KindUser + ":" + addr.String()
+}
+
+// AddressFromUserId returns the address embedded in a user id.
+func AddressFromUserId(id string) (sdk.AccAddress, error) {
+	_, kind, name := SplitId(id)
+	if kind != KindUser {
+		return nil, fmt.Errorf("%s is not a user id", id)
+	}
+	return sdk.AccAddressFromBech32(name)
+}
+
+// VoucherId returns the id of the serial-th voucher issued by a codex.
+func VoucherId(codexId string, serial int) string {
+	zone, _, name := SplitId(codexId)
+	return fmt.Sprintf("%s:%s:%s:%d", zone, KindVoucher, name, serial)
+}
+
+// MultisigId returns the id of the seq-th multisig.
+func MultisigId(seq int64) string {
+	return fmt.Sprintf("%s:%s:%d", DefaultZone, KindMultisig, seq)
+}
+
+// EscrowId returns the id of the seq-th escrow record kept by a module.
+func EscrowId(module string, seq int64) string {
+	return fmt.Sprintf("%s:%s:%s:%d", DefaultZone, KindEscrow, module, seq)
+}
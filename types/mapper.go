@@ -109,6 +109,11 @@ func (vm VoucherMapper) SetVoucher(ctx sdk.Context, voucher *Voucher) {
 	store.Set(Id2StoreKey("voucher:", voucher.Id), bz)
 }
 
+func (vm VoucherMapper) DeleteVoucher(ctx sdk.Context, id string) {
+	store := ctx.KVStore(vm.key)
+	store.Delete(Id2StoreKey("voucher:", id))
+}
+
 func (vm VoucherMapper) IterateVouchers(ctx sdk.Context, process func(*Voucher) (stop bool)) {
 	store := ctx.KVStore(vm.key)
 	iter := sdk.KVStorePrefixIterator(store, []byte("voucher:"))
@@ -126,6 +131,26 @@ func (vm VoucherMapper) IterateVouchers(ctx sdk.Context, process func(*Voucher)
 	}
 }
 
+// IterateCodexVouchers iterates over the vouchers issued by a codex only,
+// whose ids share the prefix derived from the codex id.
+func (vm VoucherMapper) IterateCodexVouchers(ctx sdk.Context, codexId string, process func(*Voucher) (stop bool)) {
+	zone, _, name := SplitId(codexId)
+	prefix := Id2StoreKey("voucher:", zone+":"+KindVoucher+":"+name+":")
+	store := ctx.KVStore(vm.key)
+	iter := sdk.KVStorePrefixIterator(store, prefix)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		voucher := vm.decodeVoucher(iter.Value())
+		if voucher.Origin != codexId {
+			// a codex whose name extends this one's
+			continue
+		}
+		if process(voucher) {
+			return
+		}
+	}
+}
+
 func (vm VoucherMapper) encodeVoucher(voucher *Voucher) []byte {
 	bz, err := vm.cdc.MarshalBinaryBare(voucher)
 	if err != nil {
@@ -142,3 +167,100 @@ func (vm VoucherMapper) decodeVoucher(bz []byte) (voucher *Voucher) {
 	}
 	return
 }
+
+//////////////////////////////////////////////////////////////////
+// PendingMapper
+
+type PendingMapper struct {
+	key   sdk.StoreKey
+	proto func() *PendingTransfer
+	cdc   *wire.Codec
+}
+
+func NewPendingMapper(cdc *wire.Codec, key sdk.StoreKey, proto func() *PendingTransfer) PendingMapper {
+	return PendingMapper{
+		key:   key,
+		proto: proto,
+		cdc:   cdc,
+	}
+}
+
+var pendingSeqKey = []byte("pending-seq")
+
+// NextPendingId returns a fresh id for a new pending transfer. Ids taken by
+// pending transfers loaded from genesis are skipped.
+func (pm PendingMapper) NextPendingId(ctx sdk.Context) string {
+	store := ctx.KVStore(pm.key)
+	var seq int64
+	bz := store.Get(pendingSeqKey)
+	if bz != nil {
+		pm.cdc.MustUnmarshalBinaryBare(bz, &seq)
+	}
+	for {
+		id := EscrowId("pending", seq)
+		seq++
+		if !store.Has(Id2StoreKey("pending:", id)) {
+			store.Set(pendingSeqKey, pm.cdc.MustMarshalBinaryBare(seq))
+			return id
+		}
+	}
+}
+
+func (pm PendingMapper) GetPending(ctx sdk.Context, id string) *PendingTransfer {
+	store := ctx.KVStore(pm.key)
+	bz := store.Get(Id2StoreKey("pending:", id))
+	if bz == nil {
+		return nil
+	}
+	return pm.decodePending(bz)
+}
+
+// SetPending stores a pending transfer along with its sender and recipient
+// indices.
+func (pm PendingMapper) SetPending(ctx sdk.Context, pt *PendingTransfer) {
+	store := ctx.KVStore(pm.key)
+	store.Set(Id2StoreKey("pending:", pt.Id), pm.encodePending(pt))
+	store.Set(append(PendingBySenderPrefix(pt.Sender), []byte(pt.Id)...), []byte(pt.Id))
+	store.Set(append(PendingByRecipientPrefix(pt.Recipient), []byte(pt.Id)...), []byte(pt.Id))
+}
+
+func (pm PendingMapper) DeletePending(ctx sdk.Context, pt *PendingTransfer) {
+	store := ctx.KVStore(pm.key)
+	store.Delete(Id2StoreKey("pending:", pt.Id))
+	store.Delete(append(PendingBySenderPrefix(pt.Sender), []byte(pt.Id)...))
+	store.Delete(append(PendingByRecipientPrefix(pt.Recipient), []byte(pt.Id)...))
+}
+
+func (pm PendingMapper) IteratePendings(ctx sdk.Context, process func(*PendingTransfer) (stop bool)) {
+	store := ctx.KVStore(pm.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("pending:"))
+	defer iter.Close()
+	for {
+		if !iter.Valid() {
+			return
+		}
+		val := iter.Value()
+		pt := pm.decodePending(val)
+		if process(pt) {
+			return
+		}
+		iter.Next()
+	}
+}
+
+func (pm PendingMapper) encodePending(pt *PendingTransfer) []byte {
+	bz, err := pm.cdc.MarshalBinaryBare(pt)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func (pm PendingMapper) decodePending(bz []byte) (pt *PendingTransfer) {
+	pt = &PendingTransfer{}
+	err := pm.cdc.UnmarshalBinaryBare(bz, pt)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// A Listing offers a voucher for sale at a price in silver or gold. The
+// voucher is held by the listing's escrow id until it is bought or delisted.
+type Listing struct {
+	Id       string   `json:"id"`
+	Voucher  string   `json:"voucher"`
+	Codex    string   `json:"codex"` // origin of the voucher
+	Seller   string   `json:"seller"`
+	Price    sdk.Coin `json:"price"`
+	ListedOn int      `json:"listed-on"`
+}
+
+// ListingKey returns the store key of a listing.
+func ListingKey(id string) []byte {
+	return Id2StoreKey("listing:", id)
+}
+
+// ListingByCodexPrefix returns the store prefix indexing the listings of
+// the vouchers of a codex.
+func ListingByCodexPrefix(codex string) []byte {
+	return Id2StoreKey("listing-codex:", codex+"/")
+}
+
+// ListingByPricePrefix returns the store prefix indexing listings priced in
+// a denomination. Amounts are zero padded so that the index sorts by price.
+func ListingByPricePrefix(denom string) []byte {
+	return []byte(fmt.Sprintf("listing-price:%s:", denom))
+}
+
+// ListingByPriceKey returns the store key of a listing in the price index.
+// The price must fit in an int64.
+func ListingByPriceKey(l *Listing) []byte {
+	key := append(ListingByPricePrefix(l.Price.Denom), []byte(fmt.Sprintf("%020d/", l.Price.Amount.Int64()))...)
+	return append(key, []byte(l.Id)...)
+}
+
+// Sides of an order.
+const (
+	OrderBid = "bid"
+	OrderAsk = "ask"
+)
+
+// An Order is a bid or an ask for vouchers of a codex at a price per voucher.
+// An ask holds the vouchers it sells in escrow and a bid the coins it pays
+// with, both under the order's escrow id. Orders of the same codex and
+// denomination are matched at the end of every block by price, then by Seq.
+type Order struct {
+	Id       string    `json:"id"`
+	Seq      int64     `json:"seq"`
+	Codex    string    `json:"codex"`
+	Side     string    `json:"side"`
+	Trader   string    `json:"trader"`
+	Price    sdk.Coin  `json:"price"`
+	Quantity int       `json:"quantity"` // vouchers left to buy or sell
+	Filled   int       `json:"filled"`
+	Vouchers []string  `json:"vouchers"` // left to sell, for an ask
+	Escrow   sdk.Coins `json:"escrow"`   // left to pay with, for a bid
+	PlacedOn int       `json:"placed-on"`
+	ExpireOn int       `json:"expire-on"`
+}
+
+// OrderKey returns the store key of an order.
+func OrderKey(id string) []byte {
+	return Id2StoreKey("order:", id)
+}
+
+// OrderBookPrefix returns the store prefix indexing one side of the order
+// book of a codex in a denomination. The index sorts the best order first:
+// asks by ascending and bids by descending price, then both by Seq.
+func OrderBookPrefix(codex string, side string, denom string) []byte {
+	return []byte(fmt.Sprintf("order-%s:%s/%s:", side, codex, denom))
+}
+
+// OrderBookKey returns the store key of an order in the order book. The price
+// must fit in an int64.
+func OrderBookKey(o *Order) []byte {
+	price := o.Price.Amount.Int64()
+	if o.Side == OrderBid {
+		price = math.MaxInt64 - price
+	}
+	return append(OrderBookPrefix(o.Codex, o.Side, o.Price.Denom), []byte(fmt.Sprintf("%020d/%020d", price, o.Seq))...)
+}
+
+// OrderByTraderPrefix returns the store prefix indexing the orders of a
+// trader.
+func OrderByTraderPrefix(trader string) []byte {
+	return Id2StoreKey("order-trader:", trader+"/")
+}
+
+// OrderByExpiryPrefix returns the store prefix indexing orders by the height
+// they expire on. Heights are zero padded so that the index sorts by height.
+func OrderByExpiryPrefix(expireOn int) []byte {
+	return []byte(fmt.Sprintf("order-expiry:%020d/", expireOn))
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// A Multisig lets Threshold out of its Members act together as one owner. A
+// multisig acts only through proposals approved by enough members.
+type Multisig struct {
+	Id        string   `json:"id"`
+	Members   []string `json:"members"`
+	Threshold int      `json:"threshold"`
+}
+
+// IsMember tells whether a user is one of the members of the multisig.
+func (ms *Multisig) IsMember(user string) bool {
+	for _, member := range ms.Members {
+		if member == user {
+			return true
+		}
+	}
+	return false
+}
+
+// A Proposal is a message to be sent on behalf of a multisig once Threshold
+// members approve it. Approvals are void once ExpireOn has passed.
+type Proposal struct {
+	Id        string   `json:"id"`
+	Multisig  string   `json:"multisig"`
+	Msg       sdk.Msg  `json:"msg"`
+	Approvals []string `json:"approvals"`
+	ExpireOn  int      `json:"expire-on"`
+}
+
+// An OwnerMsg is a privileged message sent by the owner of a codex. When the
+// owner is a multisig, such a message can be sent only through a proposal.
+// ValidateFields checks the message like ValidateBasic, but for its signer.
+type OwnerMsg interface {
+	sdk.Msg
+	GetOwner() string
+	ValidateFields() sdk.Error
+}
+
+// MultisigKey returns the store key of a multisig.
+func MultisigKey(id string) []byte {
+	return Id2StoreKey("multisig:", id)
+}
+
+// ProposalKey returns the store key of a proposal.
+func ProposalKey(id string) []byte {
+	return Id2StoreKey("proposal:", id)
+}
+
+// ProposalByMultisigPrefix returns the store prefix indexing the proposals of
+// a multisig.
+func ProposalByMultisigPrefix(multisig string) []byte {
+	return Id2StoreKey("proposal-multisig:", multisig+"/")
+}
+
+// ProposalByExpiryPrefix returns the store prefix indexing proposals by the
+// height they expire on. Heights are zero padded so that the index sorts by
+// height.
+func ProposalByExpiryPrefix(expireOn int) []byte {
+	return []byte(fmt.Sprintf("proposal-expiry:%020d/", expireOn))
+}
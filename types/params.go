@@ -0,0 +1,132 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+)
+
+// BvsParams are the settings of the bvs modules. They are loaded from genesis
+// and may only be changed afterwards by passed parameter-change proposals.
+type BvsParams struct {
+	Shop   ShopParams   `json:"shop"`
+	Refund RefundParams `json:"refund"`
+	Market MarketParams `json:"market"`
+	AMM    AMMParams    `json:"amm"`
+}
+
+// ShopParams rule over codices. A codex must keep a deposit of at least
+// MinDepositPerVoucher silver for each voucher it has available or live, and
+// its vouchers expire within MaxExpireAfter blocks of their issue whatever
+// the codex says. A zero MaxExpireAfter sets no limit.
+type ShopParams struct {
+	MinDepositPerVoucher int `json:"min-deposit-per-voucher"`
+	MaxExpireAfter       int `json:"max-expire-after"`
+}
+
+// RefundParams bound the refund windows of codices: a window shorter than
+// MinWindow is extended to it, and a longer one than MaxWindow is cut to it.
+// A zero MaxWindow sets no upper bound.
+type RefundParams struct {
+	MinWindow int `json:"min-window"`
+	MaxWindow int `json:"max-window"`
+}
+
+// MarketParams list the denominations vouchers may be priced in on the
+// market.
+type MarketParams struct {
+	AllowedDenoms []string `json:"allowed-denoms"`
+}
+
+// AMMParams set the fee of a swap, in thousandths of the offered coins.
+type AMMParams struct {
+	SwapFee int64 `json:"swap-fee"`
+}
+
+// DefaultBvsParams returns the settings of a new chain.
+func DefaultBvsParams() *BvsParams {
+	return &BvsParams{
+		Market: MarketParams{AllowedDenoms: []string{DenomSilver, DenomGold}},
+		AMM:    AMMParams{SwapFee: 3},
+	}
+}
+
+// Validate tells why the settings are invalid, if they are.
+func (p *BvsParams) Validate() error {
+	if p.Shop.MinDepositPerVoucher < 0 {
+		return fmt.Errorf("negative minimum deposit per voucher")
+	}
+	if p.Shop.MaxExpireAfter < 0 {
+		return fmt.Errorf("negative maximum expire-after")
+	}
+	if p.Refund.MinWindow < 0 || p.Refund.MaxWindow < 0 {
+		return fmt.Errorf("negative refund window bound")
+	}
+	if p.Refund.MaxWindow > 0 && p.Refund.MinWindow > p.Refund.MaxWindow {
+		return fmt.Errorf("minimum refund window %d above the maximum %d", p.Refund.MinWindow, p.Refund.MaxWindow)
+	}
+	seen := map[string]bool{}
+	for _, denom := range p.Market.AllowedDenoms {
+		if denom != DenomSilver && denom != DenomGold {
+			return fmt.Errorf("vouchers are sold for %s or %s, not %s", DenomSilver, DenomGold, denom)
+		}
+		if seen[denom] {
+			return fmt.Errorf("%s allowed twice", denom)
+		}
+		seen[denom] = true
+	}
+	if p.AMM.SwapFee < 0 || p.AMM.SwapFee >= 1000 {
+		return fmt.Errorf("swap fee of %d thousandths", p.AMM.SwapFee)
+	}
+	return nil
+}
+
+// IsDenomAllowed tells whether vouchers may be priced in denom on the market.
+func (p *BvsParams) IsDenomAllowed(denom string) bool {
+	for _, d := range p.Market.AllowedDenoms {
+		if d == denom {
+			return true
+		}
+	}
+	return false
+}
+
+// BvsParamsKey is the store key of the settings of the bvs modules.
+var BvsParamsKey = []byte("bvs-params")
+
+//////////////////////////////////////////////////////////////////
+// ParamsMapper
+
+// ParamsMapper keeps the settings of the bvs modules in the main store.
+type ParamsMapper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+}
+
+func NewParamsMapper(cdc *wire.Codec, key sdk.StoreKey) ParamsMapper {
+	return ParamsMapper{
+		key: key,
+		cdc: cdc,
+	}
+}
+
+// GetParams returns the settings, or the default ones if none are set.
+func (pm ParamsMapper) GetParams(ctx sdk.Context) *BvsParams {
+	bz := ctx.KVStore(pm.key).Get(BvsParamsKey)
+	if bz == nil {
+		return DefaultBvsParams()
+	}
+	p := &BvsParams{}
+	pm.cdc.MustUnmarshalBinaryBare(bz, p)
+	return p
+}
+
+// SetParams checks and sets the settings.
+func (pm ParamsMapper) SetParams(ctx sdk.Context, p *BvsParams) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	ctx.KVStore(pm.key).Set(BvsParamsKey, pm.cdc.MustMarshalBinaryBare(p))
+	return nil
+}
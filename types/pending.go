@@ -0,0 +1,30 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// A PendingTransfer holds assets sent through MsgBvs in pending mode until the
+// recipient accepts them. Once Deadline passes without acceptance the sender
+// may reclaim them. Vouchers in a pending transfer are held by the transfer's
+// escrow id.
+type PendingTransfer struct {
+	Id        string    `json:"id"`
+	Sender    string    `json:"sender"`
+	Recipient string    `json:"recipient"`
+	Coins     sdk.Coins `json:"coins"`
+	Vouchers  []string  `json:"vouchers"`
+	Deadline  int       `json:"deadline"`
+}
+
+// PendingBySenderPrefix returns the store prefix indexing pending transfers
+// made by a sender.
+func PendingBySenderPrefix(sender string) []byte {
+	return Id2StoreKey("pending-sender:", sender+"/")
+}
+
+// PendingByRecipientPrefix returns the store prefix indexing pending
+// transfers awaiting a recipient.
+func PendingByRecipientPrefix(recipient string) []byte {
+	return Id2StoreKey("pending-recipient:", recipient+"/")
+}
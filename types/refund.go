@@ -0,0 +1,26 @@
+package types
+
+import (
+	"fmt"
+)
+
+// A RefundRequest is made by the holder of a voucher within the refund window
+// of its codex. The voucher is held by the request's escrow id until the codex
+// owner answers, or until Deadline when the request is approved by default.
+type RefundRequest struct {
+	Id       string `json:"id"`
+	Voucher  string `json:"voucher"`
+	Holder   string `json:"holder"`
+	Deadline int    `json:"deadline"`
+}
+
+// RefundKey returns the store key of a refund request.
+func RefundKey(id string) []byte {
+	return Id2StoreKey("refund:", id)
+}
+
+// RefundByDeadlinePrefix returns the store prefix indexing refund requests
+// by deadline. Heights are zero padded so that the index sorts by height.
+func RefundByDeadlinePrefix(deadline int) []byte {
+	return []byte(fmt.Sprintf("refund-deadline:%020d/", deadline))
+}
@@ -0,0 +1,6 @@
+package types
+
+// SchemaVersionKey is the main store key of the version of the schema the
+// records of the stores are encoded in. Migrations run by software upgrades
+// bump it.
+var SchemaVersionKey = []byte("schema-version")
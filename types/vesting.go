@@ -0,0 +1,152 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+// Kinds of vesting schedules.
+const (
+	VestingContinuous = "continuous"
+	VestingDelayed    = "delayed"
+)
+
+// A VestingAccount is a user account part of whose coins are locked until
+// they vest. Locked coins cannot be sent, but still count in the coins of
+// the account.
+type VestingAccount interface {
+	auth.Account
+
+	GetOriginalVesting() sdk.Coins
+	VestedCoins(height int64) sdk.Coins
+}
+
+var _ VestingAccount = (*ContinuousVestingAccount)(nil)
+var _ VestingAccount = (*DelayedVestingAccount)(nil)
+
+// ContinuousVestingAccount vests its OriginalVesting coins linearly from
+// StartHeight to EndHeight.
+type ContinuousVestingAccount struct {
+	UserAccount
+
+	OriginalVesting sdk.Coins `json:"original-vesting"`
+	StartHeight     int64     `json:"start-height"`
+	EndHeight       int64     `json:"end-height"`
+}
+
+func (acc ContinuousVestingAccount) GetOriginalVesting() sdk.Coins { return acc.OriginalVesting }
+
+// VestedCoins returns the coins vested at a height, rounded down.
+func (acc ContinuousVestingAccount) VestedCoins(height int64) sdk.Coins {
+	switch {
+	case height <= acc.StartHeight:
+		return sdk.Coins{}
+	case height >= acc.EndHeight:
+		return acc.OriginalVesting
+	}
+	var vested sdk.Coins
+	for _, coin := range acc.OriginalVesting {
+		amt := coin.Amount.MulRaw(height - acc.StartHeight).DivRaw(acc.EndHeight - acc.StartHeight)
+		if !amt.IsZero() {
+			vested = append(vested, sdk.Coin{Denom: coin.Denom, Amount: amt})
+		}
+	}
+	return vested
+}
+
+// DelayedVestingAccount vests all its OriginalVesting coins at EndHeight.
+type DelayedVestingAccount struct {
+	UserAccount
+
+	OriginalVesting sdk.Coins `json:"original-vesting"`
+	EndHeight       int64     `json:"end-height"`
+}
+
+func (acc DelayedVestingAccount) GetOriginalVesting() sdk.Coins { return acc.OriginalVesting }
+
+// VestedCoins returns the coins vested at a height.
+func (acc DelayedVestingAccount) VestedCoins(height int64) sdk.Coins {
+	if height >= acc.EndHeight {
+		return acc.OriginalVesting
+	}
+	return sdk.Coins{}
+}
+
+// LockedCoins returns the coins of an account which have not vested yet at
+// a height. An account without a vesting schedule has none.
+func LockedCoins(acc auth.Account, height int64) sdk.Coins {
+	vacc, ok := acc.(VestingAccount)
+	if !ok {
+		return sdk.Coins{}
+	}
+	return vacc.GetOriginalVesting().Minus(vacc.VestedCoins(height))
+}
+
+// SpendableCoins returns the coins of an account which are not locked at a
+// height.
+func SpendableCoins(acc auth.Account, height int64) sdk.Coins {
+	var spendable sdk.Coins
+	locked := LockedCoins(acc, height)
+	for _, coin := range acc.GetCoins() {
+		amt := coin.Amount.Sub(locked.AmountOf(coin.Denom))
+		if amt.Sign() > 0 {
+			spendable = append(spendable, sdk.Coin{Denom: coin.Denom, Amount: amt})
+		}
+	}
+	return spendable
+}
+
+// A VestingSchedule in the genesis state locks the Original coins of an
+// account until they vest, either continuously from StartHeight to
+// EndHeight, or all at once at EndHeight.
+type VestingSchedule struct {
+	Kind        string    `json:"kind"`
+	Original    sdk.Coins `json:"original"`
+	StartHeight int64     `json:"start-height"`
+	EndHeight   int64     `json:"end-height"`
+}
+
+// toAccount returns a vesting account extending acc on the schedule.
+func (vs *VestingSchedule) toAccount(acc *UserAccount) (auth.Account, error) {
+	if !acc.Coins.IsGTE(vs.Original) {
+		return nil, fmt.Errorf("%s vests %s out of %s", acc.Id, vs.Original, acc.Coins)
+	}
+	switch vs.Kind {
+	case VestingContinuous:
+		if vs.EndHeight <= vs.StartHeight {
+			return nil, fmt.Errorf("%s vests from %d to %d", acc.Id, vs.StartHeight, vs.EndHeight)
+		}
+		return &ContinuousVestingAccount{UserAccount: *acc, OriginalVesting: vs.Original.Sort(),
+			StartHeight: vs.StartHeight, EndHeight: vs.EndHeight}, nil
+	case VestingDelayed:
+		return &DelayedVestingAccount{UserAccount: *acc, OriginalVesting: vs.Original.Sort(),
+			EndHeight: vs.EndHeight}, nil
+	}
+	return nil, fmt.Errorf("unknown vesting kind %s", vs.Kind)
+}
+
+// NewVestingSchedule returns the vesting schedule of an account, or nil if
+// it has none.
+func NewVestingSchedule(acc auth.Account) *VestingSchedule {
+	switch acc := acc.(type) {
+	case *ContinuousVestingAccount:
+		return &VestingSchedule{Kind: VestingContinuous, Original: acc.OriginalVesting,
+			StartHeight: acc.StartHeight, EndHeight: acc.EndHeight}
+	case *DelayedVestingAccount:
+		return &VestingSchedule{Kind: VestingDelayed, Original: acc.OriginalVesting,
+			EndHeight: acc.EndHeight}
+	}
+	return nil
+}
+
+// A VestingBalance splits the coins of an account into the coins vested and
+// locked at a height, and tells those it can spend.
+type VestingBalance struct {
+	Address   sdk.AccAddress `json:"address"`
+	Coins     sdk.Coins      `json:"coins"`
+	Vested    sdk.Coins      `json:"vested"`
+	Locked    sdk.Coins      `json:"locked"`
+	Spendable sdk.Coins      `json:"spendable"`
+}
@@ -8,10 +8,23 @@ import (
 
 // A Voucher is an asset representing a value guaranteed by a voucher issuer.
 type Voucher struct {
-	Id       string `json:"id"`
-	Origin   string `json:"origin"` // may be a Codex or a Dealer
-	Holder   string `json:"holder"`
-	ExpireOn int    `json:"expire-on"`
+	Id       string    `json:"id"`
+	Origin   string    `json:"origin"` // may be a Codex or a Dealer
+	Holder   string    `json:"holder"`
+	ExpireOn int       `json:"expire-on"`
+	IssuedOn int       `json:"issued-on"`
+	Balance  sdk.Coins `json:"balance"`  // escrowed stored value, if any
+	Proceeds int       `json:"proceeds"` // silver of the price escrowed by the codex
+
+	// ApprovedTo is the user the codex owner allowed the voucher to be
+	// transferred to, under the owner-approved transfer policy.
+	ApprovedTo string `json:"approved-to"`
+}
+
+// IsExpired tells whether the voucher is no longer valid at the given height.
+// A voucher with zero ExpireOn never expires.
+func (v *Voucher) IsExpired(height int64) bool {
+	return v.ExpireOn != 0 && height > int64(v.ExpireOn)
 }
 
 type BvsAsset struct {
@@ -49,14 +62,15 @@ func ParseBvsAsset(str string) (asset *BvsAsset) {
 		}
 	}
 
+	asset.Silver = sdk.NewInt(silver)
+	asset.Gold = sdk.NewInt(gold)
 	if silver > 0 {
-		asset.Silver.AddRaw(silver)
-		asset.Coins = append(asset.Coins, sdk.NewCoin("bvs", asset.Silver))
+		asset.Coins = append(asset.Coins, sdk.NewCoin(DenomSilver, asset.Silver))
 	}
 	if gold > 0 {
-		asset.Gold.AddRaw(silver)
-		asset.Coins = append(asset.Coins, sdk.NewCoin("bvg", asset.Silver))
+		asset.Coins = append(asset.Coins, sdk.NewCoin(DenomGold, asset.Gold))
 	}
+	asset.Coins = asset.Coins.Sort()
 
 	return
 }
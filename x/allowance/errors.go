@@ -0,0 +1,33 @@
+package allowance
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Allowance errors reserve 200 ~ 299.
+const (
+	DefaultCodespace sdk.CodespaceType = 12
+
+	CodeNoAllowance      sdk.CodeType = 201
+	CodeExceedsAllowance sdk.CodeType = 202
+	CodeAllowanceExpired sdk.CodeType = 203
+	CodeInvalidAllowance sdk.CodeType = 204
+)
+
+func ErrNoAllowance(codespace sdk.CodespaceType, owner string, spender string) sdk.Error {
+	return sdk.NewError(codespace, CodeNoAllowance, fmt.Sprintf("%s granted no allowance to %s", owner, spender))
+}
+
+func ErrExceedsAllowance(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeExceedsAllowance, msg)
+}
+
+func ErrAllowanceExpired(codespace sdk.CodespaceType, owner string, spender string) sdk.Error {
+	return sdk.NewError(codespace, CodeAllowanceExpired, fmt.Sprintf("the allowance from %s to %s has expired", owner, spender))
+}
+
+func ErrInvalidAllowance(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidAllowance, msg)
+}
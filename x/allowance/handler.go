@@ -0,0 +1,58 @@
+package allowance
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// NewHandler returns a handler for "allowance" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgApprove:
+			return handleMsgApprove(ctx, k, msg)
+		case *MsgRevoke:
+			return handleMsgRevoke(ctx, k, msg)
+		case *MsgTransferFrom:
+			return handleMsgTransferFrom(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized allowance Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgApprove(ctx sdk.Context, k Keeper, msg *MsgApprove) sdk.Result {
+	if msg.ExpireOn > 0 && int64(msg.ExpireOn) <= ctx.BlockHeight() {
+		return ErrInvalidAllowance(k.codespace, "the allowance would expire right away").Result()
+	}
+	k.SetAllowance(ctx, &types.Allowance{
+		Owner:    msg.Owner,
+		Spender:  msg.Spender,
+		Coins:    msg.Coins,
+		Vouchers: msg.Vouchers,
+		Codices:  msg.Codices,
+		ExpireOn: msg.ExpireOn,
+	})
+	return sdk.Result{Tags: sdk.NewTags("action", []byte("approve"), "spender", []byte(msg.Spender))}
+}
+
+func handleMsgRevoke(ctx sdk.Context, k Keeper, msg *MsgRevoke) sdk.Result {
+	if k.GetAllowance(ctx, msg.Owner, msg.Spender) == nil {
+		return ErrNoAllowance(k.codespace, msg.Owner, msg.Spender).Result()
+	}
+	k.DeleteAllowance(ctx, msg.Owner, msg.Spender)
+	return sdk.Result{Tags: sdk.NewTags("action", []byte("revoke"), "spender", []byte(msg.Spender))}
+}
+
+func handleMsgTransferFrom(ctx sdk.Context, k Keeper, msg *MsgTransferFrom) sdk.Result {
+	tags, err := k.TransferFrom(ctx, msg.Spender, msg.Owner, msg.Recipient, msg.Asset)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
@@ -0,0 +1,134 @@
+package allowance
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+)
+
+// Keeper manages allowances and moves assets under them through the shop
+// keeper, so the usual ownership and transfer policy checks still apply.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	sk  shop.Keeper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, sk shop.Keeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, sk: sk, codespace: codespace}
+}
+
+func (k Keeper) GetAllowance(ctx sdk.Context, owner string, spender string) *types.Allowance {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.AllowanceKey(owner, spender))
+	if bz == nil {
+		return nil
+	}
+	a := &types.Allowance{}
+	k.cdc.MustUnmarshalBinaryBare(bz, a)
+	return a
+}
+
+// SetAllowance stores an allowance, replacing any allowance the owner granted
+// to the same spender before.
+func (k Keeper) SetAllowance(ctx sdk.Context, a *types.Allowance) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.AllowanceKey(a.Owner, a.Spender), k.cdc.MustMarshalBinaryBare(a))
+	store.Set(append(types.AllowanceBySpenderPrefix(a.Spender), []byte(a.Owner)...), []byte(a.Owner))
+}
+
+func (k Keeper) DeleteAllowance(ctx sdk.Context, owner string, spender string) {
+	store := ctx.KVStore(k.key)
+	store.Delete(types.AllowanceKey(owner, spender))
+	store.Delete(append(types.AllowanceBySpenderPrefix(spender), []byte(owner)...))
+}
+
+func (k Keeper) IterateAllowances(ctx sdk.Context, process func(*types.Allowance) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("allowance:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		a := &types.Allowance{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), a)
+		if process(a) {
+			return
+		}
+	}
+}
+
+// TransferFrom moves an asset of the owner to the recipient on behalf of the
+// spender, using up the allowance the owner granted to the spender.
+func (k Keeper) TransferFrom(ctx sdk.Context, spender string, owner string, recipient string, asset types.BvsAsset) (sdk.Tags, sdk.Error) {
+	a := k.GetAllowance(ctx, owner, spender)
+	if a == nil {
+		return nil, ErrNoAllowance(k.codespace, owner, spender)
+	}
+	if a.IsExpired(ctx.BlockHeight()) {
+		return nil, ErrAllowanceExpired(k.codespace, owner, spender)
+	}
+
+	if !asset.Coins.IsZero() {
+		remaining := a.Coins.Minus(asset.Coins)
+		if !remaining.IsNotNegative() {
+			return nil, ErrExceedsAllowance(k.codespace,
+				fmt.Sprintf("%s exceeds the allowance of %s", asset.Coins, a.Coins))
+		}
+		a.Coins = remaining
+	}
+	for _, id := range asset.Vouchers {
+		if i := indexOf(a.Vouchers, id); i >= 0 {
+			a.Vouchers = append(a.Vouchers[:i], a.Vouchers[i+1:]...)
+			continue
+		}
+		vou := k.sk.VoucherMapper().GetVoucher(ctx, id)
+		if vou == nil || indexOf(a.Codices, vou.Origin) < 0 {
+			return nil, ErrExceedsAllowance(k.codespace,
+				fmt.Sprintf("the voucher %s is not covered by the allowance", id))
+		}
+	}
+
+	tags, err := k.sk.SendAsset(ctx, owner, recipient, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Coins.IsZero() && len(a.Vouchers) == 0 && len(a.Codices) == 0 {
+		k.DeleteAllowance(ctx, owner, spender)
+	} else {
+		k.SetAllowance(ctx, a)
+	}
+	return tags.AppendTags(sdk.NewTags("action", []byte("transfer-from"), "spender", []byte(spender))), nil
+}
+
+func indexOf(ids []string, id string) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// InitGenesis loads the allowances of the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, allowances []*types.Allowance) {
+	for _, a := range allowances {
+		k.SetAllowance(ctx, a)
+	}
+}
+
+// WriteGenesis returns all the allowances for the genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) []*types.Allowance {
+	allowances := []*types.Allowance{}
+	k.IterateAllowances(ctx, func(a *types.Allowance) bool {
+		allowances = append(allowances, a)
+		return false
+	})
+	return allowances
+}
@@ -0,0 +1,135 @@
+package allowance
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgApprove grants a spender an allowance over the assets of the owner,
+// replacing any allowance granted to the same spender before.
+type MsgApprove struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Spender      string         `json:"spender"`
+	Coins        sdk.Coins      `json:"coins"`
+	Vouchers     []string       `json:"vouchers"`
+	Codices      []string       `json:"codices"`
+	ExpireOn     int            `json:"expire-on"`
+}
+
+var _ sdk.Msg = MsgApprove{}
+
+// Implements sdk.Msg
+func (msg MsgApprove) Type() string { return "allowance" }
+
+// Implements sdk.Msg
+func (msg MsgApprove) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	if types.IdKind(msg.Spender) != types.KindUser || msg.Spender == msg.Owner {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("invalid spender %s", msg.Spender))
+	}
+	if !msg.Coins.IsValid() || !msg.Coins.IsNotNegative() {
+		return sdk.ErrInvalidCoins(msg.Coins.String())
+	}
+	if msg.ExpireOn < 0 {
+		return sdk.ErrUnknownRequest("negative expire-on")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgApprove) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgApprove) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// MsgRevoke withdraws the allowance an owner granted to a spender.
+type MsgRevoke struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Spender      string         `json:"spender"`
+}
+
+var _ sdk.Msg = MsgRevoke{}
+
+// Implements sdk.Msg
+func (msg MsgRevoke) Type() string { return "allowance" }
+
+// Implements sdk.Msg
+func (msg MsgRevoke) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgRevoke) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgRevoke) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// MsgTransferFrom moves an asset of the owner to the recipient on behalf of
+// the spender, within the allowance the owner granted.
+type MsgTransferFrom struct {
+	SpenderAccount sdk.AccAddress `json:"spender-account"`
+	Spender        string         `json:"spender"`
+	Owner          string         `json:"owner"`
+	Recipient      string         `json:"recipient"`
+	Asset          types.BvsAsset `json:"asset"`
+}
+
+var _ sdk.Msg = MsgTransferFrom{}
+
+// Implements sdk.Msg
+func (msg MsgTransferFrom) Type() string { return "allowance" }
+
+// Implements sdk.Msg
+func (msg MsgTransferFrom) ValidateBasic() sdk.Error {
+	if msg.Spender != types.UserId(msg.SpenderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Spender))
+	}
+	if types.IdKind(msg.Recipient) != types.KindUser {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("%s is not a user id", msg.Recipient))
+	}
+	if !msg.Asset.Coins.IsValid() || !msg.Asset.Coins.IsNotNegative() {
+		return sdk.ErrInvalidCoins(msg.Asset.Coins.String())
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgTransferFrom) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgTransferFrom) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.SpenderAccount}
+}
@@ -0,0 +1,28 @@
+package amm
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// AMM errors reserve 1000 ~ 1099.
+const (
+	DefaultCodespace sdk.CodespaceType = 20
+
+	CodeEmptyPool          sdk.CodeType = 1001
+	CodeSlippage           sdk.CodeType = 1002
+	CodeInsufficientShares sdk.CodeType = 1003
+)
+
+func ErrEmptyPool(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeEmptyPool, "the pool has no liquidity")
+}
+
+func ErrSlippage(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeSlippage, msg)
+}
+
+func ErrInsufficientShares(codespace sdk.CodespaceType, provider string, shares sdk.Int) sdk.Error {
+	return sdk.NewError(codespace, CodeInsufficientShares, fmt.Sprintf("%s owns only %s shares", provider, shares))
+}
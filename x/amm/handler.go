@@ -0,0 +1,49 @@
+package amm
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "amm" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgAddLiquidity:
+			return handleMsgAddLiquidity(ctx, k, msg)
+		case *MsgRemoveLiquidity:
+			return handleMsgRemoveLiquidity(ctx, k, msg)
+		case *MsgSwap:
+			return handleMsgSwap(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized amm Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgAddLiquidity(ctx sdk.Context, k Keeper, msg *MsgAddLiquidity) sdk.Result {
+	shares, tags, err := k.AddLiquidity(ctx, msg.Provider, msg.Silver, msg.MaxGold)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(shares.String()), Tags: tags}
+}
+
+func handleMsgRemoveLiquidity(ctx sdk.Context, k Keeper, msg *MsgRemoveLiquidity) sdk.Result {
+	tags, err := k.RemoveLiquidity(ctx, msg.Provider, msg.Shares, msg.MinSilver, msg.MinGold)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgSwap(ctx sdk.Context, k Keeper, msg *MsgSwap) sdk.Result {
+	out, tags, err := k.Swap(ctx, msg.Trader, msg.Offer, msg.MinReceive)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(out.String()), Tags: tags}
+}
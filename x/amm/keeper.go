@@ -0,0 +1,239 @@
+package amm
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/vesting"
+)
+
+// The fee of a swap is taken out of the offered coins and left in the pool,
+// to the benefit of the liquidity providers. It is set in thousandths by the
+// amm params.
+const FeeDenominator = 1000
+
+// Keeper manages the silver/gold pool. Coins of the pool are taken out of the
+// accounts of its users and kept as reserves by the pool.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	ck  bank.Keeper
+	vk  vesting.Keeper
+	am  types.ParamsMapper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, ck bank.Keeper, vk vesting.Keeper, am types.ParamsMapper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, ck: ck, vk: vk, am: am, codespace: codespace}
+}
+
+// SwapFee returns the fee of a swap, in thousandths of the offered coins.
+func (k Keeper) SwapFee(ctx sdk.Context) int64 {
+	return k.am.GetParams(ctx).AMM.SwapFee
+}
+
+// GetPool returns the pool, which is empty until liquidity is first added.
+func (k Keeper) GetPool(ctx sdk.Context) *types.Pool {
+	bz := ctx.KVStore(k.key).Get(types.PoolKey)
+	if bz == nil {
+		return types.NewPool()
+	}
+	p := &types.Pool{}
+	k.cdc.MustUnmarshalBinaryBare(bz, p)
+	return p
+}
+
+func (k Keeper) SetPool(ctx sdk.Context, p *types.Pool) {
+	ctx.KVStore(k.key).Set(types.PoolKey, k.cdc.MustMarshalBinaryBare(p))
+}
+
+// GetShares returns the shares of the pool owned by a provider.
+func (k Keeper) GetShares(ctx sdk.Context, provider string) sdk.Int {
+	bz := ctx.KVStore(k.key).Get(types.PoolShareKey(provider))
+	if bz == nil {
+		return sdk.ZeroInt()
+	}
+	var shares sdk.Int
+	k.cdc.MustUnmarshalBinaryBare(bz, &shares)
+	return shares
+}
+
+func (k Keeper) SetShares(ctx sdk.Context, provider string, shares sdk.Int) {
+	store := ctx.KVStore(k.key)
+	if shares.IsZero() {
+		store.Delete(types.PoolShareKey(provider))
+		return
+	}
+	store.Set(types.PoolShareKey(provider), k.cdc.MustMarshalBinaryBare(shares))
+}
+
+func (k Keeper) IterateShares(ctx sdk.Context, process func(*types.PoolShare) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("pool-share:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		ps := &types.PoolShare{Provider: string(iter.Key()[len("pool-share:"):])}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), &ps.Shares)
+		if process(ps) {
+			return
+		}
+	}
+}
+
+func (k Keeper) subtractCoins(ctx sdk.Context, user string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	addr, err := types.AddressFromUserId(user)
+	if err != nil {
+		return nil, sdk.ErrInvalidAddress(user)
+	}
+	if err := k.vk.CheckSpend(ctx, addr, amt); err != nil {
+		return nil, err
+	}
+	_, tags, sdkErr := k.ck.SubtractCoins(ctx, addr, amt)
+	return tags, sdkErr
+}
+
+func (k Keeper) addCoins(ctx sdk.Context, user string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	addr, err := types.AddressFromUserId(user)
+	if err != nil {
+		return nil, sdk.ErrInvalidAddress(user)
+	}
+	_, tags, sdkErr := k.ck.AddCoins(ctx, addr, amt)
+	return tags, sdkErr
+}
+
+func coins(silver sdk.Int, gold sdk.Int) sdk.Coins {
+	var amt sdk.Coins
+	if !gold.IsZero() {
+		amt = append(amt, sdk.Coin{Denom: types.DenomGold, Amount: gold})
+	}
+	if !silver.IsZero() {
+		amt = append(amt, sdk.Coin{Denom: types.DenomSilver, Amount: silver})
+	}
+	return amt
+}
+
+// AddLiquidity puts silver into the pool along with the gold it is worth at
+// the pool price, which may not exceed maxGold. The first provider sets the
+// price by putting in maxGold, and gets one share per silver.
+func (k Keeper) AddLiquidity(ctx sdk.Context, provider string, silver sdk.Int, maxGold sdk.Int) (sdk.Int, sdk.Tags, sdk.Error) {
+	p := k.GetPool(ctx)
+	gold, shares := maxGold, silver
+	if !p.IsEmpty() {
+		// round the gold up, so the pool never loses on a deposit
+		gold = silver.Mul(p.Gold).Div(p.Silver).AddRaw(1)
+		shares = silver.Mul(p.Shares).Div(p.Silver)
+		if gold.GT(maxGold) {
+			return sdk.Int{}, nil, ErrSlippage(k.codespace, fmt.Sprintf("%s%s needed, at most %s%s offered", gold, types.DenomGold, maxGold, types.DenomGold))
+		}
+	}
+	if shares.IsZero() {
+		return sdk.Int{}, nil, ErrSlippage(k.codespace, "too little liquidity for a share")
+	}
+
+	tags, err := k.subtractCoins(ctx, provider, coins(silver, gold))
+	if err != nil {
+		return sdk.Int{}, nil, err
+	}
+	p.Silver = p.Silver.Add(silver)
+	p.Gold = p.Gold.Add(gold)
+	p.Shares = p.Shares.Add(shares)
+	k.SetPool(ctx, p)
+	k.SetShares(ctx, provider, k.GetShares(ctx, provider).Add(shares))
+	return shares, tags.AppendTags(sdk.NewTags("action", []byte("add-liquidity"), "shares", []byte(shares.String()))), nil
+}
+
+// RemoveLiquidity burns shares of a provider for their part of the reserves,
+// which must come to at least minSilver and minGold.
+func (k Keeper) RemoveLiquidity(ctx sdk.Context, provider string, shares sdk.Int, minSilver sdk.Int, minGold sdk.Int) (sdk.Tags, sdk.Error) {
+	owned := k.GetShares(ctx, provider)
+	if owned.LT(shares) {
+		return nil, ErrInsufficientShares(k.codespace, provider, owned)
+	}
+	p := k.GetPool(ctx)
+	silver := shares.Mul(p.Silver).Div(p.Shares)
+	gold := shares.Mul(p.Gold).Div(p.Shares)
+	if silver.LT(minSilver) || gold.LT(minGold) {
+		return nil, ErrSlippage(k.codespace, fmt.Sprintf("only %s%s and %s%s to withdraw", silver, types.DenomSilver, gold, types.DenomGold))
+	}
+
+	p.Silver = p.Silver.Sub(silver)
+	p.Gold = p.Gold.Sub(gold)
+	p.Shares = p.Shares.Sub(shares)
+	k.SetPool(ctx, p)
+	k.SetShares(ctx, provider, owned.Sub(shares))
+	tags, err := k.addCoins(ctx, provider, coins(silver, gold))
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTags(sdk.NewTags("action", []byte("remove-liquidity"), "shares", []byte(shares.String()))), nil
+}
+
+// SwapOut returns what the pool gives for offered coins, after a fee in
+// thousandths.
+func SwapOut(p *types.Pool, offer sdk.Coin, fee int64) sdk.Coin {
+	askDenom := types.DenomGold
+	if offer.Denom == types.DenomGold {
+		askDenom = types.DenomSilver
+	}
+	in := offer.Amount.MulRaw(FeeDenominator - fee)
+	out := in.Mul(p.Reserve(askDenom)).Div(p.Reserve(offer.Denom).MulRaw(FeeDenominator).Add(in))
+	return sdk.Coin{Denom: askDenom, Amount: out}
+}
+
+// Swap trades offered silver for gold or the other way around. The trade
+// fails if it would yield less than minReceive.
+func (k Keeper) Swap(ctx sdk.Context, trader string, offer sdk.Coin, minReceive sdk.Int) (sdk.Coin, sdk.Tags, sdk.Error) {
+	p := k.GetPool(ctx)
+	if p.IsEmpty() {
+		return sdk.Coin{}, nil, ErrEmptyPool(k.codespace)
+	}
+	out := SwapOut(p, offer, k.SwapFee(ctx))
+	if out.Amount.IsZero() || out.Amount.LT(minReceive) {
+		return sdk.Coin{}, nil, ErrSlippage(k.codespace, fmt.Sprintf("%s offered for %s, at least %s%s wanted", offer, out, minReceive, out.Denom))
+	}
+
+	subTags, err := k.subtractCoins(ctx, trader, sdk.Coins{offer})
+	if err != nil {
+		return sdk.Coin{}, nil, err
+	}
+	if offer.Denom == types.DenomSilver {
+		p.Silver = p.Silver.Add(offer.Amount)
+		p.Gold = p.Gold.Sub(out.Amount)
+	} else {
+		p.Gold = p.Gold.Add(offer.Amount)
+		p.Silver = p.Silver.Sub(out.Amount)
+	}
+	k.SetPool(ctx, p)
+	addTags, err := k.addCoins(ctx, trader, sdk.Coins{out})
+	if err != nil {
+		return sdk.Coin{}, nil, err
+	}
+	tags := subTags.AppendTags(addTags)
+	return out, tags.AppendTags(sdk.NewTags("action", []byte("swap"), "received", []byte(out.String()))), nil
+}
+
+// InitGenesis loads the pool and its shares from the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, p *types.Pool, shares []*types.PoolShare) {
+	if p != nil {
+		k.SetPool(ctx, p)
+	}
+	for _, ps := range shares {
+		k.SetShares(ctx, ps.Provider, ps.Shares)
+	}
+}
+
+// WriteGenesis returns the pool and its shares for the genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) (*types.Pool, []*types.PoolShare) {
+	shares := []*types.PoolShare{}
+	k.IterateShares(ctx, func(ps *types.PoolShare) bool {
+		shares = append(shares, ps)
+		return false
+	})
+	return k.GetPool(ctx), shares
+}
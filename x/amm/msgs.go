@@ -0,0 +1,134 @@
+package amm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgAddLiquidity puts silver, and at most MaxGold gold, into the pool for
+// shares of it.
+type MsgAddLiquidity struct {
+	ProviderAccount sdk.AccAddress `json:"provider-account"`
+	Provider        string         `json:"provider"`
+	Silver          sdk.Int        `json:"silver"`
+	MaxGold         sdk.Int        `json:"max-gold"`
+}
+
+var _ sdk.Msg = MsgAddLiquidity{}
+
+// Implements sdk.Msg
+func (msg MsgAddLiquidity) Type() string { return "amm" }
+
+// Implements sdk.Msg
+func (msg MsgAddLiquidity) ValidateBasic() sdk.Error {
+	if msg.Provider != types.UserId(msg.ProviderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Provider))
+	}
+	if msg.Silver.Sign() <= 0 || msg.MaxGold.Sign() <= 0 {
+		return sdk.ErrInvalidCoins("liquidity must be positive")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgAddLiquidity) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgAddLiquidity) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ProviderAccount}
+}
+
+// MsgRemoveLiquidity gives back shares of the pool for at least MinSilver and
+// MinGold.
+type MsgRemoveLiquidity struct {
+	ProviderAccount sdk.AccAddress `json:"provider-account"`
+	Provider        string         `json:"provider"`
+	Shares          sdk.Int        `json:"shares"`
+	MinSilver       sdk.Int        `json:"min-silver"`
+	MinGold         sdk.Int        `json:"min-gold"`
+}
+
+var _ sdk.Msg = MsgRemoveLiquidity{}
+
+// Implements sdk.Msg
+func (msg MsgRemoveLiquidity) Type() string { return "amm" }
+
+// Implements sdk.Msg
+func (msg MsgRemoveLiquidity) ValidateBasic() sdk.Error {
+	if msg.Provider != types.UserId(msg.ProviderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Provider))
+	}
+	if msg.Shares.Sign() <= 0 || msg.MinSilver.Sign() < 0 || msg.MinGold.Sign() < 0 {
+		return sdk.ErrUnknownRequest("shares must be positive and minimums not negative")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgRemoveLiquidity) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgRemoveLiquidity) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ProviderAccount}
+}
+
+// MsgSwap trades silver for gold or gold for silver through the pool. The
+// swap fails if it would yield less than MinReceive.
+type MsgSwap struct {
+	TraderAccount sdk.AccAddress `json:"trader-account"`
+	Trader        string         `json:"trader"`
+	Offer         sdk.Coin       `json:"offer"`
+	MinReceive    sdk.Int        `json:"min-receive"`
+}
+
+var _ sdk.Msg = MsgSwap{}
+
+// Implements sdk.Msg
+func (msg MsgSwap) Type() string { return "amm" }
+
+// Implements sdk.Msg
+func (msg MsgSwap) ValidateBasic() sdk.Error {
+	if msg.Trader != types.UserId(msg.TraderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Trader))
+	}
+	if msg.Offer.Denom != types.DenomSilver && msg.Offer.Denom != types.DenomGold {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("the pool swaps %s and %s only", types.DenomSilver, types.DenomGold))
+	}
+	if !msg.Offer.IsPositive() {
+		return sdk.ErrInvalidCoins(msg.Offer.String())
+	}
+	if msg.MinReceive.Sign() < 0 {
+		return sdk.ErrUnknownRequest("negative min-receive")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgSwap) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgSwap) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.TraderAccount}
+}
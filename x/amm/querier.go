@@ -0,0 +1,44 @@
+package amm
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// Query answers the custom queries of the module, returning JSON:
+//
+//	custom/amm/pool           the reserves and shares of the pool
+//	custom/amm/shares/<user>  the shares owned by a provider
+//	custom/amm/quote/<coin>   what the pool gives for offered coins
+func Query(ctx sdk.Context, k Keeper, path []string) ([]byte, sdk.Error) {
+	if len(path) == 0 {
+		return nil, sdk.ErrUnknownRequest("no amm query given")
+	}
+	var res interface{}
+	switch {
+	case path[0] == "pool" && len(path) == 1:
+		res = k.GetPool(ctx)
+	case path[0] == "shares" && len(path) == 2:
+		res = &types.PoolShare{Provider: path[1], Shares: k.GetShares(ctx, path[1])}
+	case path[0] == "quote" && len(path) == 2:
+		offer, err := sdk.ParseCoin(path[1])
+		if err != nil || (offer.Denom != types.DenomSilver && offer.Denom != types.DenomGold) {
+			return nil, sdk.ErrInvalidCoins(path[1])
+		}
+		p := k.GetPool(ctx)
+		if p.IsEmpty() {
+			return nil, ErrEmptyPool(k.codespace)
+		}
+		res = SwapOut(p, offer, k.SwapFee(ctx))
+	default:
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("unknown amm query %v", path))
+	}
+	bz, err := k.cdc.MarshalJSON(res)
+	if err != nil {
+		return nil, sdk.ErrInternal(err.Error())
+	}
+	return bz, nil
+}
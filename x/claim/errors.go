@@ -0,0 +1,33 @@
+package claim
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Claim errors reserve 300 ~ 399.
+const (
+	DefaultCodespace sdk.CodespaceType = 13
+
+	CodeUnknownCampaign sdk.CodeType = 301
+	CodeInvalidCode     sdk.CodeType = 302
+	CodeCodeUsed        sdk.CodeType = 303
+	CodeNoCommit        sdk.CodeType = 304
+)
+
+func ErrUnknownCampaign(codespace sdk.CodespaceType, codex string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownCampaign, fmt.Sprintf("the codex %s has published no claim codes", codex))
+}
+
+func ErrInvalidCode(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCode, "invalid claim code")
+}
+
+func ErrCodeUsed(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeCodeUsed, "the claim code has already been used")
+}
+
+func ErrNoCommit(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeNoCommit, "the claim must be committed in an earlier block")
+}
@@ -0,0 +1,49 @@
+package claim
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "claim" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgPublishCodes:
+			return handleMsgPublishCodes(ctx, k, msg)
+		case *MsgCommitClaim:
+			return handleMsgCommitClaim(ctx, k, msg)
+		case *MsgClaimWithCode:
+			return handleMsgClaimWithCode(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized claim Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgPublishCodes(ctx sdk.Context, k Keeper, msg *MsgPublishCodes) sdk.Result {
+	err := k.Publish(ctx, msg.Owner, msg.Codex, msg.Hashes, msg.Roots, msg.RequireCommit)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: sdk.NewTags("action", []byte("publish-codes"), "codex", []byte(msg.Codex))}
+}
+
+func handleMsgCommitClaim(ctx sdk.Context, k Keeper, msg *MsgCommitClaim) sdk.Result {
+	k.Commit(ctx, msg.Claimer, msg.Commitment)
+	return sdk.Result{}
+}
+
+func handleMsgClaimWithCode(ctx sdk.Context, k Keeper, msg *MsgClaimWithCode) sdk.Result {
+	vou, err := k.Claim(ctx, msg.Claimer, msg.Codex, msg.Code, msg.Proof)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Data: []byte(vou.Id),
+		Tags: sdk.NewTags("action", []byte("claim"), "codex", []byte(msg.Codex), "voucher", []byte(vou.Id)),
+	}
+}
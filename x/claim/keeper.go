@@ -0,0 +1,213 @@
+package claim
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+)
+
+// Keeper manages claim campaigns and issues vouchers for valid claim codes
+// through the shop keeper.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	sk  shop.Keeper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, sk shop.Keeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, sk: sk, codespace: codespace}
+}
+
+func campaignKey(codex string) []byte {
+	return types.Id2StoreKey("campaign:", codex)
+}
+
+func hashKey(codex string, hash []byte) []byte {
+	return types.Id2StoreKey("hash:", codex+"/"+hex.EncodeToString(hash))
+}
+
+func usedKey(codex string, hash []byte) []byte {
+	return types.Id2StoreKey("used:", codex+"/"+hex.EncodeToString(hash))
+}
+
+func commitKey(commitment []byte) []byte {
+	return types.Id2StoreKey("commit:", hex.EncodeToString(commitment))
+}
+
+func (k Keeper) GetCampaign(ctx sdk.Context, codex string) *types.ClaimCampaign {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(campaignKey(codex))
+	if bz == nil {
+		return nil
+	}
+	campaign := &types.ClaimCampaign{}
+	k.cdc.MustUnmarshalBinaryBare(bz, campaign)
+	return campaign
+}
+
+// SetCampaign stores a campaign. Hashes and Used of the campaign are stored
+// as separate entries so that a claim touches only its own code.
+func (k Keeper) SetCampaign(ctx sdk.Context, campaign *types.ClaimCampaign) {
+	store := ctx.KVStore(k.key)
+	for _, hash := range campaign.Hashes {
+		store.Set(hashKey(campaign.Codex, hash), []byte{1})
+	}
+	for _, hash := range campaign.Used {
+		store.Set(usedKey(campaign.Codex, hash), []byte{1})
+	}
+	record := *campaign
+	record.Hashes = nil
+	record.Used = nil
+	store.Set(campaignKey(campaign.Codex), k.cdc.MustMarshalBinaryBare(&record))
+}
+
+// Publish adds claim code hashes and Merkle roots to the campaign of a codex,
+// creating the campaign if needed.
+func (k Keeper) Publish(ctx sdk.Context, owner string, codexId string, hashes [][]byte, roots [][]byte, requireCommit bool) sdk.Error {
+	cod := k.sk.CodexMapper().GetCodex(ctx, codexId)
+	if cod == nil {
+		return shop.ErrUnknownCodex(k.sk.Codespace(), codexId)
+	}
+	if cod.Owner != owner {
+		return shop.ErrNotCodexOwner(k.sk.Codespace(), owner, codexId)
+	}
+	k.sk.TouchCodex(ctx, codexId)
+	campaign := k.GetCampaign(ctx, codexId)
+	if campaign == nil {
+		campaign = &types.ClaimCampaign{Codex: codexId}
+	}
+	campaign.RequireCommit = requireCommit
+	campaign.Roots = append(campaign.Roots, roots...)
+	campaign.Hashes = hashes
+	k.SetCampaign(ctx, campaign)
+	return nil
+}
+
+// Commit records the commitment of a claimer to a code at the current height.
+func (k Keeper) Commit(ctx sdk.Context, claimer string, commitment []byte) {
+	store := ctx.KVStore(k.key)
+	c := &types.ClaimCommit{Commitment: commitment, Claimer: claimer, Height: ctx.BlockHeight()}
+	store.Set(commitKey(commitment), k.cdc.MustMarshalBinaryBare(c))
+}
+
+func (k Keeper) getCommit(ctx sdk.Context, commitment []byte) *types.ClaimCommit {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(commitKey(commitment))
+	if bz == nil {
+		return nil
+	}
+	c := &types.ClaimCommit{}
+	k.cdc.MustUnmarshalBinaryBare(bz, c)
+	return c
+}
+
+// Claim issues a voucher of a codex to the claimer presenting a valid, unused
+// claim code. Codes covered by a Merkle root need the proof as well.
+func (k Keeper) Claim(ctx sdk.Context, claimer string, codexId string, code []byte, proof [][]byte) (*types.Voucher, sdk.Error) {
+	store := ctx.KVStore(k.key)
+	campaign := k.GetCampaign(ctx, codexId)
+	if campaign == nil {
+		return nil, ErrUnknownCampaign(k.codespace, codexId)
+	}
+
+	hash := types.ClaimCodeHash(code)
+	if store.Has(usedKey(codexId, hash)) {
+		return nil, ErrCodeUsed(k.codespace)
+	}
+	if !store.Has(hashKey(codexId, hash)) && !k.provenByRoot(campaign, hash, proof) {
+		return nil, ErrInvalidCode(k.codespace)
+	}
+
+	if campaign.RequireCommit {
+		commitment := types.ClaimCommitment(code, claimer)
+		c := k.getCommit(ctx, commitment)
+		if c == nil || c.Claimer != claimer || c.Height >= ctx.BlockHeight() {
+			return nil, ErrNoCommit(k.codespace)
+		}
+		store.Delete(commitKey(commitment))
+	}
+
+	store.Delete(hashKey(codexId, hash))
+	store.Set(usedKey(codexId, hash), []byte{1})
+	return k.sk.IssueVoucher(ctx, codexId, claimer)
+}
+
+func (k Keeper) provenByRoot(campaign *types.ClaimCampaign, hash []byte, proof [][]byte) bool {
+	if len(proof) == 0 {
+		return false
+	}
+	root := types.MerkleRoot(hash, proof)
+	for _, r := range campaign.Roots {
+		if bytes.Equal(r, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// iterateHashes calls process with the hex encoded hashes stored under a
+// prefix for a codex.
+func (k Keeper) iterateHashes(ctx sdk.Context, prefix string, codex string, process func(hash []byte)) {
+	store := ctx.KVStore(k.key)
+	p := types.Id2StoreKey(prefix, codex+"/")
+	iter := sdk.KVStorePrefixIterator(store, p)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		hash, err := hex.DecodeString(string(iter.Key()[len(p):]))
+		if err != nil {
+			panic(err)
+		}
+		process(hash)
+	}
+}
+
+// InitGenesis loads the claim campaigns and commits of the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, campaigns []*types.ClaimCampaign, commits []*types.ClaimCommit) {
+	store := ctx.KVStore(k.key)
+	for _, campaign := range campaigns {
+		k.SetCampaign(ctx, campaign)
+	}
+	for _, c := range commits {
+		store.Set(commitKey(c.Commitment), k.cdc.MustMarshalBinaryBare(c))
+	}
+}
+
+// WriteGenesis returns the claim campaigns and commits for the genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) (campaigns []*types.ClaimCampaign, commits []*types.ClaimCommit) {
+	store := ctx.KVStore(k.key)
+	campaigns = []*types.ClaimCampaign{}
+	commits = []*types.ClaimCommit{}
+
+	iter := sdk.KVStorePrefixIterator(store, []byte("campaign:"))
+	for ; iter.Valid(); iter.Next() {
+		campaign := &types.ClaimCampaign{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), campaign)
+		campaigns = append(campaigns, campaign)
+	}
+	iter.Close()
+	for _, campaign := range campaigns {
+		k.iterateHashes(ctx, "hash:", campaign.Codex, func(hash []byte) {
+			campaign.Hashes = append(campaign.Hashes, hash)
+		})
+		k.iterateHashes(ctx, "used:", campaign.Codex, func(hash []byte) {
+			campaign.Used = append(campaign.Used, hash)
+		})
+	}
+
+	iter = sdk.KVStorePrefixIterator(store, []byte("commit:"))
+	for ; iter.Valid(); iter.Next() {
+		c := &types.ClaimCommit{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), c)
+		commits = append(commits, c)
+	}
+	iter.Close()
+	return
+}
@@ -0,0 +1,139 @@
+package claim
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgPublishCodes is sent by a codex owner to publish hashes of claim codes
+// and Merkle roots of sets of them.
+type MsgPublishCodes struct {
+	OwnerAccount  sdk.AccAddress `json:"owner-account"`
+	Owner         string         `json:"owner"`
+	Codex         string         `json:"codex"`
+	Hashes        [][]byte       `json:"hashes"`
+	Roots         [][]byte       `json:"roots"`
+	RequireCommit bool           `json:"require-commit"`
+}
+
+var _ types.OwnerMsg = MsgPublishCodes{}
+
+// Implements sdk.Msg
+func (msg MsgPublishCodes) Type() string { return "claim" }
+
+// Implements sdk.Msg
+func (msg MsgPublishCodes) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return msg.ValidateFields()
+}
+
+// Implements types.OwnerMsg
+func (msg MsgPublishCodes) ValidateFields() sdk.Error {
+	for _, h := range append(append([][]byte{}, msg.Hashes...), msg.Roots...) {
+		if len(h) != 32 {
+			return sdk.ErrUnknownRequest("hashes and roots must be SHA-256 digests")
+		}
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgPublishCodes) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgPublishCodes) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// Implements types.OwnerMsg
+func (msg MsgPublishCodes) GetOwner() string { return msg.Owner }
+
+// MsgCommitClaim commits a claimer to a code without revealing it. The
+// commitment is types.ClaimCommitment of the code and the claimer id.
+type MsgCommitClaim struct {
+	ClaimerAccount sdk.AccAddress `json:"claimer-account"`
+	Claimer        string         `json:"claimer"`
+	Commitment     []byte         `json:"commitment"`
+}
+
+var _ sdk.Msg = MsgCommitClaim{}
+
+// Implements sdk.Msg
+func (msg MsgCommitClaim) Type() string { return "claim" }
+
+// Implements sdk.Msg
+func (msg MsgCommitClaim) ValidateBasic() sdk.Error {
+	if msg.Claimer != types.UserId(msg.ClaimerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Claimer))
+	}
+	if len(msg.Commitment) != 32 {
+		return sdk.ErrUnknownRequest("the commitment must be a SHA-256 digest")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgCommitClaim) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgCommitClaim) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ClaimerAccount}
+}
+
+// MsgClaimWithCode reveals a claim code to receive a voucher of the codex.
+// Proof is needed only for codes published under a Merkle root.
+type MsgClaimWithCode struct {
+	ClaimerAccount sdk.AccAddress `json:"claimer-account"`
+	Claimer        string         `json:"claimer"`
+	Codex          string         `json:"codex"`
+	Code           []byte         `json:"code"`
+	Proof          [][]byte       `json:"proof"`
+}
+
+var _ sdk.Msg = MsgClaimWithCode{}
+
+// Implements sdk.Msg
+func (msg MsgClaimWithCode) Type() string { return "claim" }
+
+// Implements sdk.Msg
+func (msg MsgClaimWithCode) ValidateBasic() sdk.Error {
+	if msg.Claimer != types.UserId(msg.ClaimerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Claimer))
+	}
+	if len(msg.Code) == 0 {
+		return sdk.ErrUnknownRequest("empty claim code")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgClaimWithCode) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgClaimWithCode) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ClaimerAccount}
+}
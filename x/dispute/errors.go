@@ -0,0 +1,38 @@
+package dispute
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Dispute errors reserve 600 ~ 699.
+const (
+	DefaultCodespace sdk.CodespaceType = 16
+
+	CodeUnknownDispute sdk.CodeType = 601
+	CodeNotArbiter     sdk.CodeType = 602
+	CodeClosed         sdk.CodeType = 603
+	CodeInvalidRuling  sdk.CodeType = 604
+	CodeNoArbiter      sdk.CodeType = 605
+)
+
+func ErrUnknownDispute(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownDispute, fmt.Sprintf("no dispute found with the id %s", id))
+}
+
+func ErrNotArbiter(codespace sdk.CodespaceType, arbiter string, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotArbiter, fmt.Sprintf("%s may not rule the dispute %s", arbiter, id))
+}
+
+func ErrClosed(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeClosed, fmt.Sprintf("the dispute %s is already closed", id))
+}
+
+func ErrInvalidRuling(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidRuling, msg)
+}
+
+func ErrNoArbiter(codespace sdk.CodespaceType, codex string) sdk.Error {
+	return sdk.NewError(codespace, CodeNoArbiter, fmt.Sprintf("nobody may rule disputes over the vouchers of %s", codex))
+}
@@ -0,0 +1,39 @@
+package dispute
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "dispute" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgOpenDispute:
+			return handleMsgOpenDispute(ctx, k, msg)
+		case *MsgRuleDispute:
+			return handleMsgRuleDispute(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized dispute Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgOpenDispute(ctx sdk.Context, k Keeper, msg *MsgOpenDispute) sdk.Result {
+	d, tags, err := k.OpenDispute(ctx, msg.Holder, msg.Voucher, msg.Reason)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(d.Id), Tags: tags}
+}
+
+func handleMsgRuleDispute(ctx sdk.Context, k Keeper, msg *MsgRuleDispute) sdk.Result {
+	tags, err := k.RuleDispute(ctx, msg.Arbiter, msg.Dispute, msg.ForHolder, msg.Compensation, msg.Ruling)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
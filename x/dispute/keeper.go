@@ -0,0 +1,234 @@
+package dispute
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+)
+
+// Keeper manages disputes over vouchers and the chain-wide arbiter set.
+// Vouchers under dispute are frozen and settled through the shop keeper.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	sk  shop.Keeper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, sk shop.Keeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, sk: sk, codespace: codespace}
+}
+
+func (k Keeper) Codespace() sdk.CodespaceType { return k.codespace }
+
+var disputeSeqKey = []byte("dispute-seq")
+
+// nextDisputeId returns a fresh id for a new dispute. Ids taken by disputes
+// loaded from genesis are skipped.
+func (k Keeper) nextDisputeId(ctx sdk.Context) string {
+	store := ctx.KVStore(k.key)
+	var seq int64
+	bz := store.Get(disputeSeqKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &seq)
+	}
+	for {
+		id := types.EscrowId("dispute", seq)
+		seq++
+		if !store.Has(types.DisputeKey(id)) {
+			store.Set(disputeSeqKey, k.cdc.MustMarshalBinaryBare(seq))
+			return id
+		}
+	}
+}
+
+func (k Keeper) GetDispute(ctx sdk.Context, id string) *types.Dispute {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.DisputeKey(id))
+	if bz == nil {
+		return nil
+	}
+	d := &types.Dispute{}
+	k.cdc.MustUnmarshalBinaryBare(bz, d)
+	return d
+}
+
+// SetDispute stores a dispute along with its codex and holder indices.
+func (k Keeper) SetDispute(ctx sdk.Context, d *types.Dispute) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.DisputeKey(d.Id), k.cdc.MustMarshalBinaryBare(d))
+	store.Set(append(types.DisputeByCodexPrefix(d.Codex), []byte(d.Id)...), []byte(d.Id))
+	store.Set(append(types.DisputeByHolderPrefix(d.Holder), []byte(d.Id)...), []byte(d.Id))
+}
+
+func (k Keeper) IterateDisputes(ctx sdk.Context, process func(*types.Dispute) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("dispute:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		d := &types.Dispute{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), d)
+		if process(d) {
+			return
+		}
+	}
+}
+
+// GetArbiters returns the chain-wide arbiter set.
+func (k Keeper) GetArbiters(ctx sdk.Context) []string {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.ArbitersKey)
+	if bz == nil {
+		return nil
+	}
+	var arbiters []string
+	k.cdc.MustUnmarshalBinaryBare(bz, &arbiters)
+	return arbiters
+}
+
+func (k Keeper) SetArbiters(ctx sdk.Context, arbiters []string) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.ArbitersKey, k.cdc.MustMarshalBinaryBare(arbiters))
+}
+
+// IsArbiter tells whether a user may rule disputes over the vouchers of a
+// codex: the arbiter named on the codex if any, or else any member of the
+// chain-wide arbiter set.
+func (k Keeper) IsArbiter(ctx sdk.Context, cod *types.Codex, user string) bool {
+	if cod.Arbiter != "" {
+		return cod.Arbiter == user
+	}
+	for _, arbiter := range k.GetArbiters(ctx) {
+		if arbiter == user {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenDispute freezes a voucher and opens a dispute over it. A voucher which
+// nobody may rule on is not frozen, as it could never be let go again.
+func (k Keeper) OpenDispute(ctx sdk.Context, holder string, voucherId string, reason string) (*types.Dispute, sdk.Tags, sdk.Error) {
+	vou, err := k.sk.GetLiveVoucher(ctx, voucherId)
+	if err != nil {
+		return nil, nil, err
+	}
+	cod := k.sk.CodexMapper().GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, nil, shop.ErrUnknownCodex(k.sk.Codespace(), vou.Origin)
+	}
+	if cod.Arbiter == "" && len(k.GetArbiters(ctx)) == 0 {
+		return nil, nil, ErrNoArbiter(k.codespace, cod.Id)
+	}
+	d := &types.Dispute{
+		Id:       k.nextDisputeId(ctx),
+		Voucher:  voucherId,
+		Codex:    vou.Origin,
+		Holder:   holder,
+		Reason:   reason,
+		OpenedOn: int(ctx.BlockHeight()),
+		Outcome:  types.DisputeOpen,
+	}
+	if err := k.sk.FreezeVoucher(ctx, holder, d.Id, voucherId); err != nil {
+		return nil, nil, err
+	}
+	k.SetDispute(ctx, d)
+	return d, sdk.NewTags("action", []byte("open-dispute"), "voucher", []byte(voucherId), "dispute", []byte(d.Id)), nil
+}
+
+// RuleDispute closes a dispute. A ruling for the holder compensates the holder
+// out of the codex deposit, up to what is left of it, and burns the voucher
+// with its remaining balance and escrowed proceeds paid back. A ruling for the
+// merchant returns the voucher to the holder.
+func (k Keeper) RuleDispute(ctx sdk.Context, arbiter string, id string, forHolder bool, compensation sdk.Coins, ruling string) (sdk.Tags, sdk.Error) {
+	d := k.GetDispute(ctx, id)
+	if d == nil {
+		return nil, ErrUnknownDispute(k.codespace, id)
+	}
+	if d.Outcome != types.DisputeOpen {
+		return nil, ErrClosed(k.codespace, id)
+	}
+	cod := k.sk.CodexMapper().GetCodex(ctx, d.Codex)
+	if cod == nil {
+		return nil, shop.ErrUnknownCodex(k.sk.Codespace(), d.Codex)
+	}
+	if !k.IsArbiter(ctx, cod, arbiter) {
+		return nil, ErrNotArbiter(k.codespace, arbiter, id)
+	}
+
+	tags := sdk.EmptyTags()
+	if forHolder {
+		if !compensation.IsZero() && (len(compensation) != 1 || compensation[0].Denom != types.DenomSilver) {
+			return nil, sdk.ErrInvalidCoins("compensation is paid in silver only")
+		}
+		if compensation.AmountOf(types.DenomSilver).GT(sdk.NewInt(int64(cod.Deposit))) {
+			compensation = nil
+			if cod.Deposit > 0 {
+				compensation = sdk.Coins{sdk.NewInt64Coin(types.DenomSilver, int64(cod.Deposit))}
+			}
+		}
+		if !compensation.IsZero() {
+			sendTags, err := k.sk.SendCoins(ctx, cod.Id, d.Holder, compensation)
+			if err != nil {
+				return nil, err
+			}
+			tags = tags.AppendTags(sendTags)
+		}
+		vou := k.sk.VoucherMapper().GetVoucher(ctx, d.Voucher)
+		if vou == nil {
+			return nil, shop.ErrUnknownVoucher(k.sk.Codespace(), d.Voucher)
+		}
+		releaseTags, err := k.sk.ReleaseProceeds(ctx, vou, d.Holder)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(releaseTags)
+		balanceTags, err := k.sk.ReleaseBalance(ctx, vou, d.Holder, vou.Balance)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(balanceTags)
+		if err := k.sk.BurnVoucher(ctx, d.Id, d.Voucher); err != nil {
+			return nil, err
+		}
+		d.Outcome = types.DisputeForHolder
+		d.Compensation = compensation
+	} else {
+		if err := k.sk.ReleaseVoucher(ctx, d.Id, d.Holder, d.Voucher); err != nil {
+			return nil, err
+		}
+		d.Outcome = types.DisputeForMerchant
+	}
+	d.Arbiter = arbiter
+	d.Ruling = ruling
+	d.RuledOn = int(ctx.BlockHeight())
+	k.SetDispute(ctx, d)
+
+	return tags.AppendTags(sdk.NewTags(
+		"action", []byte("rule-dispute"),
+		"dispute", []byte(id),
+		"outcome", []byte(d.Outcome),
+	)), nil
+}
+
+// InitGenesis loads the disputes and the arbiter set of the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, disputes []*types.Dispute, arbiters []string) {
+	for _, d := range disputes {
+		k.SetDispute(ctx, d)
+	}
+	k.SetArbiters(ctx, arbiters)
+}
+
+// WriteGenesis returns the disputes and the arbiter set for the genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) (disputes []*types.Dispute, arbiters []string) {
+	disputes = []*types.Dispute{}
+	k.IterateDisputes(ctx, func(d *types.Dispute) bool {
+		disputes = append(disputes, d)
+		return false
+	})
+	return disputes, k.GetArbiters(ctx)
+}
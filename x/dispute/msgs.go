@@ -0,0 +1,94 @@
+package dispute
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgOpenDispute is sent by the holder of a voucher the merchant refuses to
+// honor.
+type MsgOpenDispute struct {
+	HolderAccount sdk.AccAddress `json:"holder-account"`
+	Holder        string         `json:"holder"`
+	Voucher       string         `json:"voucher"`
+	Reason        string         `json:"reason"`
+}
+
+var _ sdk.Msg = MsgOpenDispute{}
+
+// Implements sdk.Msg
+func (msg MsgOpenDispute) Type() string { return "dispute" }
+
+// Implements sdk.Msg
+func (msg MsgOpenDispute) ValidateBasic() sdk.Error {
+	if msg.Holder != types.UserId(msg.HolderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Holder))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgOpenDispute) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgOpenDispute) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.HolderAccount}
+}
+
+// MsgRuleDispute is sent by an arbiter to close a dispute.
+type MsgRuleDispute struct {
+	ArbiterAccount sdk.AccAddress `json:"arbiter-account"`
+	Arbiter        string         `json:"arbiter"`
+	Dispute        string         `json:"dispute"`
+	ForHolder      bool           `json:"for-holder"`
+	Compensation   sdk.Coins      `json:"compensation"`
+	Ruling         string         `json:"ruling"`
+}
+
+var _ sdk.Msg = MsgRuleDispute{}
+
+// Implements sdk.Msg
+func (msg MsgRuleDispute) Type() string { return "dispute" }
+
+// Implements sdk.Msg
+func (msg MsgRuleDispute) ValidateBasic() sdk.Error {
+	if msg.Arbiter != types.UserId(msg.ArbiterAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Arbiter))
+	}
+	if !msg.Compensation.IsValid() || !msg.Compensation.IsNotNegative() {
+		return sdk.ErrInvalidCoins(msg.Compensation.String())
+	}
+	if !msg.Compensation.IsZero() {
+		if !msg.ForHolder {
+			return sdk.ErrUnknownRequest("compensation is paid only on rulings for the holder")
+		}
+		if len(msg.Compensation) != 1 || msg.Compensation[0].Denom != types.DenomSilver {
+			return sdk.ErrInvalidCoins("compensation is paid in silver only")
+		}
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgRuleDispute) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgRuleDispute) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ArbiterAccount}
+}
@@ -0,0 +1,26 @@
+package fee
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// NewAnteHandler returns an ante handler that refuses the transactions
+// paying less silver than the minimum fee of the policy, and hands the
+// others over to the next ante handler.
+func NewAnteHandler(k Keeper, next sdk.AnteHandler) sdk.AnteHandler {
+	return func(ctx sdk.Context, tx sdk.Tx) (sdk.Context, sdk.Result, bool) {
+		stdTx, ok := tx.(auth.StdTx)
+		if !ok {
+			return ctx, sdk.ErrInternal("tx must be StdTx").Result(), true
+		}
+		min := k.GetPolicy(ctx).MinFee
+		paid := stdTx.Fee.Amount.AmountOf(types.DenomSilver)
+		if paid.LT(sdk.NewInt(min)) {
+			return ctx, ErrInsufficientFee(k.codespace, paid, min).Result(), true
+		}
+		return next(ctx, tx)
+	}
+}
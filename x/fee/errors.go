@@ -0,0 +1,25 @@
+package fee
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// Fee errors reserve 1100 ~ 1199.
+const (
+	DefaultCodespace sdk.CodespaceType = 21
+
+	CodeInsufficientFee sdk.CodeType = 1101
+	CodeInvalidPolicy   sdk.CodeType = 1102
+)
+
+func ErrInsufficientFee(codespace sdk.CodespaceType, paid sdk.Int, min int64) sdk.Error {
+	return sdk.NewError(codespace, CodeInsufficientFee, fmt.Sprintf("a fee of %s%s is below the minimum of %d%s", paid, types.DenomSilver, min, types.DenomSilver))
+}
+
+func ErrInvalidPolicy(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidPolicy, msg)
+}
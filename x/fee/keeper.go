@@ -0,0 +1,213 @@
+package fee
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// FeeHistory is the number of blocks for which the collected fees are kept.
+const FeeHistory = 100000
+
+// Keeper distributes the fees collected by the ante handler, and keeps the
+// fee policy, the community pool and the fees of recent blocks.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	fck auth.FeeCollectionKeeper
+	ck  bank.Keeper
+	vs  sdk.ValidatorSet
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, fck auth.FeeCollectionKeeper, ck bank.Keeper, vs sdk.ValidatorSet, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, fck: fck, ck: ck, vs: vs, codespace: codespace}
+}
+
+// GetPolicy returns the fee policy.
+func (k Keeper) GetPolicy(ctx sdk.Context) *types.FeePolicy {
+	bz := ctx.KVStore(k.key).Get(types.FeePolicyKey)
+	if bz == nil {
+		return types.NewFeePolicy()
+	}
+	p := &types.FeePolicy{}
+	k.cdc.MustUnmarshalBinaryBare(bz, p)
+	return p
+}
+
+// SetPolicy checks and sets the fee policy.
+func (k Keeper) SetPolicy(ctx sdk.Context, p *types.FeePolicy) sdk.Error {
+	if p.MinFee < 0 {
+		return ErrInvalidPolicy(k.codespace, "negative minimum fee")
+	}
+	if p.CommunityShare < 0 || p.CommunityShare > 100 {
+		return ErrInvalidPolicy(k.codespace, fmt.Sprintf("community share of %d%%", p.CommunityShare))
+	}
+	for _, v := range p.Validators {
+		if _, err := types.AddressFromUserId(v.Payee); err != nil {
+			return ErrInvalidPolicy(k.codespace, fmt.Sprintf("invalid payee %s", v.Payee))
+		}
+		if v.Power <= 0 {
+			return ErrInvalidPolicy(k.codespace, fmt.Sprintf("power %d of %s", v.Power, v.Payee))
+		}
+	}
+	ctx.KVStore(k.key).Set(types.FeePolicyKey, k.cdc.MustMarshalBinaryBare(p))
+	return nil
+}
+
+// GetCommunityPool returns the coins of the community pool.
+func (k Keeper) GetCommunityPool(ctx sdk.Context) sdk.Coins {
+	bz := ctx.KVStore(k.key).Get(types.CommunityPoolKey)
+	if bz == nil {
+		return sdk.Coins{}
+	}
+	var pool sdk.Coins
+	k.cdc.MustUnmarshalBinaryBare(bz, &pool)
+	return pool
+}
+
+func (k Keeper) SetCommunityPool(ctx sdk.Context, pool sdk.Coins) {
+	ctx.KVStore(k.key).Set(types.CommunityPoolKey, k.cdc.MustMarshalBinaryBare(pool))
+}
+
+// GetBlockFees returns the fees collected at a height, or nil when none
+// were or the height is out of the history.
+func (k Keeper) GetBlockFees(ctx sdk.Context, height int64) *types.BlockFees {
+	bz := ctx.KVStore(k.key).Get(types.BlockFeesKey(height))
+	if bz == nil {
+		return nil
+	}
+	bf := &types.BlockFees{}
+	k.cdc.MustUnmarshalBinaryBare(bz, bf)
+	return bf
+}
+
+func (k Keeper) setBlockFees(ctx sdk.Context, bf *types.BlockFees) {
+	ctx.KVStore(k.key).Set(types.BlockFeesKey(bf.Height), k.cdc.MustMarshalBinaryBare(bf))
+}
+
+func (k Keeper) iterateBlockFees(ctx sdk.Context, process func(*types.BlockFees) (stop bool)) {
+	iter := sdk.KVStorePrefixIterator(ctx.KVStore(k.key), []byte("block-fees:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		bf := &types.BlockFees{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), bf)
+		if process(bf) {
+			return
+		}
+	}
+}
+
+// Validators returns the validators paid out of the fees: those of the
+// policy if it sets any, else the bonded validators paid at their owners.
+func (k Keeper) Validators(ctx sdk.Context, p *types.FeePolicy) []*types.FeeValidator {
+	if len(p.Validators) > 0 {
+		return p.Validators
+	}
+	vals := []*types.FeeValidator{}
+	k.vs.IterateValidatorsBonded(ctx, func(_ int64, v sdk.Validator) bool {
+		if power := v.GetPower().RoundInt64(); power > 0 {
+			vals = append(vals, &types.FeeValidator{Payee: types.UserId(v.GetOwner()), Power: power})
+		}
+		return false
+	})
+	return vals
+}
+
+// Distribute pays the fees collected in the block to the validators and the
+// community pool, as the fee policy says. What cannot be split evenly among
+// the validators goes to the community pool too.
+func (k Keeper) Distribute(ctx sdk.Context) sdk.Tags {
+	store := ctx.KVStore(k.key)
+	store.Delete(types.BlockFeesKey(ctx.BlockHeight() - FeeHistory))
+
+	collected := k.fck.GetCollectedFees(ctx)
+	if collected.IsZero() {
+		return sdk.EmptyTags()
+	}
+	k.fck.ClearCollectedFees(ctx)
+
+	p := k.GetPolicy(ctx)
+	vals := k.Validators(ctx, p)
+	var total int64
+	for _, v := range vals {
+		total += v.Power
+	}
+
+	paid := make([]sdk.Coins, len(vals))
+	var community sdk.Coins
+	for _, coin := range collected {
+		left := coin.Amount
+		if total > 0 {
+			share := coin.Amount.MulRaw(int64(100 - p.CommunityShare)).DivRaw(100)
+			for i, v := range vals {
+				amt := share.MulRaw(v.Power).DivRaw(total)
+				if !amt.IsZero() {
+					paid[i] = append(paid[i], sdk.Coin{Denom: coin.Denom, Amount: amt})
+					left = left.Sub(amt)
+				}
+			}
+		}
+		if !left.IsZero() {
+			community = append(community, sdk.Coin{Denom: coin.Denom, Amount: left})
+		}
+	}
+
+	tags := sdk.NewTags("fees", []byte(collected.String()))
+	for i, v := range vals {
+		if paid[i] == nil {
+			continue
+		}
+		addr, _ := types.AddressFromUserId(v.Payee)
+		if _, addTags, err := k.ck.AddCoins(ctx, addr, paid[i]); err != nil {
+			community = community.Plus(paid[i])
+		} else {
+			tags = tags.AppendTags(addTags)
+		}
+	}
+	if community != nil {
+		k.SetCommunityPool(ctx, k.GetCommunityPool(ctx).Plus(community))
+	}
+
+	k.setBlockFees(ctx, &types.BlockFees{Height: ctx.BlockHeight(), Collected: collected, Community: community})
+	return tags
+}
+
+// EndBlocker distributes the fees collected in the block.
+func EndBlocker(ctx sdk.Context, k Keeper) sdk.Tags {
+	return k.Distribute(ctx)
+}
+
+// InitGenesis loads the fee policy, the community pool and the history of
+// the fees collected from the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, p *types.FeePolicy, pool sdk.Coins, history []*types.BlockFees) {
+	if p != nil {
+		if err := k.SetPolicy(ctx, p); err != nil {
+			panic(err)
+		}
+	}
+	if pool != nil {
+		k.SetCommunityPool(ctx, pool)
+	}
+	for _, bf := range history {
+		k.setBlockFees(ctx, bf)
+	}
+}
+
+// WriteGenesis returns the fee policy, the community pool and the history of
+// the fees collected for the genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) (*types.FeePolicy, sdk.Coins, []*types.BlockFees) {
+	history := []*types.BlockFees{}
+	k.iterateBlockFees(ctx, func(bf *types.BlockFees) bool {
+		history = append(history, bf)
+		return false
+	})
+	return k.GetPolicy(ctx), k.GetCommunityPool(ctx), history
+}
@@ -0,0 +1,45 @@
+package fee
+
+import (
+	"fmt"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// Query answers the custom queries of the module, returning JSON:
+//
+//	custom/fee/policy          the fee policy
+//	custom/fee/community-pool  the coins of the community pool
+//	custom/fee/block/<height>  the fees collected at a recent height
+func Query(ctx sdk.Context, k Keeper, path []string) ([]byte, sdk.Error) {
+	if len(path) == 0 {
+		return nil, sdk.ErrUnknownRequest("no fee query given")
+	}
+	var res interface{}
+	switch {
+	case path[0] == "policy" && len(path) == 1:
+		res = k.GetPolicy(ctx)
+	case path[0] == "community-pool" && len(path) == 1:
+		res = k.GetCommunityPool(ctx)
+	case path[0] == "block" && len(path) == 2:
+		height, err := strconv.ParseInt(path[1], 10, 64)
+		if err != nil {
+			return nil, sdk.ErrUnknownRequest(fmt.Sprintf("invalid height %s", path[1]))
+		}
+		bf := k.GetBlockFees(ctx, height)
+		if bf == nil {
+			bf = &types.BlockFees{Height: height, Collected: sdk.Coins{}}
+		}
+		res = bf
+	default:
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("unknown fee query %v", path))
+	}
+	bz, err := k.cdc.MarshalJSON(res)
+	if err != nil {
+		return nil, sdk.ErrInternal(err.Error())
+	}
+	return bz, nil
+}
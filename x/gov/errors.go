@@ -0,0 +1,33 @@
+package gov
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Gov errors reserve 1200 ~ 1299.
+const (
+	DefaultCodespace sdk.CodespaceType = 22
+
+	CodeUnknownProposal sdk.CodeType = 1201
+	CodeInactive        sdk.CodeType = 1202
+	CodeInvalidChange   sdk.CodeType = 1203
+	CodeNoStake         sdk.CodeType = 1204
+)
+
+func ErrUnknownProposal(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownProposal, fmt.Sprintf("no proposal found with the id %s", id))
+}
+
+func ErrInactive(codespace sdk.CodespaceType, id string, status string) sdk.Error {
+	return sdk.NewError(codespace, CodeInactive, fmt.Sprintf("the proposal %s is in status %s", id, status))
+}
+
+func ErrInvalidChange(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidChange, msg)
+}
+
+func ErrNoStake(codespace sdk.CodespaceType, voter string) sdk.Error {
+	return sdk.NewError(codespace, CodeNoStake, fmt.Sprintf("%s has no bonded stake to vote with", voter))
+}
@@ -0,0 +1,58 @@
+package gov
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// NewHandler returns a handler for "gov" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgSubmitProposal:
+			return handleMsgSubmitProposal(ctx, k, msg)
+		case *MsgDeposit:
+			return handleMsgDeposit(ctx, k, msg)
+		case *MsgVote:
+			return handleMsgVote(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized gov Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgSubmitProposal(ctx sdk.Context, k Keeper, msg *MsgSubmitProposal) sdk.Result {
+	p := &types.GovProposal{
+		Kind:        msg.Kind,
+		Title:       msg.Title,
+		Description: msg.Description,
+		Changes:     msg.Changes,
+		Upgrade:     msg.Upgrade,
+	}
+	id, tags, err := k.Submit(ctx, msg.Proposer, p, msg.Deposit)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(id), Tags: tags}
+}
+
+func handleMsgDeposit(ctx sdk.Context, k Keeper, msg *MsgDeposit) sdk.Result {
+	tags, err := k.Deposit(ctx, msg.Depositor, msg.Proposal, msg.Amount)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgVote(ctx sdk.Context, k Keeper, msg *MsgVote) sdk.Result {
+	tags, err := k.Vote(ctx, msg.Voter, msg.Proposal, msg.Option)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
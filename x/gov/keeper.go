@@ -0,0 +1,472 @@
+package gov
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// A ParamStore takes the parameter changes of the proposals that pass.
+type ParamStore interface {
+	// ValidateParam checks that a parameter exists and can take a value.
+	ValidateParam(ctx sdk.Context, key string, value string) sdk.Error
+
+	// SetParam sets a parameter to a value.
+	SetParam(ctx sdk.Context, key string, value string) sdk.Error
+}
+
+// Keeper manages the governance proposals and the votes of the stakers on
+// them. Deposits are taken out of the accounts of their depositors and kept
+// by the proposals.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	ck  bank.Keeper
+	ds  sdk.DelegationSet
+	ps  ParamStore
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, ck bank.Keeper, ds sdk.DelegationSet, ps ParamStore, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, ck: ck, ds: ds, ps: ps, codespace: codespace}
+}
+
+var proposalSeqKey = []byte("gov-proposal-seq")
+
+// nextSeq returns the next value of a sequence for which id is not taken yet.
+// Ids taken by records loaded from genesis are skipped.
+func (k Keeper) nextSeq(ctx sdk.Context, seqKey []byte, idOf func(int64) string, keyOf func(string) []byte) string {
+	store := ctx.KVStore(k.key)
+	var seq int64
+	bz := store.Get(seqKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &seq)
+	}
+	for {
+		id := idOf(seq)
+		seq++
+		if !store.Has(keyOf(id)) {
+			store.Set(seqKey, k.cdc.MustMarshalBinaryBare(seq))
+			return id
+		}
+	}
+}
+
+// GetParams returns the governance rules.
+func (k Keeper) GetParams(ctx sdk.Context) *types.GovParams {
+	bz := ctx.KVStore(k.key).Get(types.GovParamsKey)
+	if bz == nil {
+		return types.DefaultGovParams()
+	}
+	params := &types.GovParams{}
+	k.cdc.MustUnmarshalBinaryBare(bz, params)
+	return params
+}
+
+// SetParams checks and sets the governance rules.
+func (k Keeper) SetParams(ctx sdk.Context, params *types.GovParams) sdk.Error {
+	if !params.MinDeposit.IsValid() || !params.MinDeposit.IsNotNegative() {
+		return ErrInvalidChange(k.codespace, fmt.Sprintf("invalid minimum deposit %s", params.MinDeposit))
+	}
+	if params.DepositPeriod <= 0 || params.VotingPeriod <= 0 {
+		return ErrInvalidChange(k.codespace, "periods must be positive")
+	}
+	for _, pct := range []int{params.Quorum, params.Threshold, params.Veto} {
+		if pct < 0 || pct > 100 {
+			return ErrInvalidChange(k.codespace, fmt.Sprintf("%d is not a percentage", pct))
+		}
+	}
+	ctx.KVStore(k.key).Set(types.GovParamsKey, k.cdc.MustMarshalBinaryBare(params))
+	return nil
+}
+
+// GetUpgradePlan returns the upgrade plan last passed, if any.
+func (k Keeper) GetUpgradePlan(ctx sdk.Context) *types.UpgradePlan {
+	bz := ctx.KVStore(k.key).Get(types.UpgradePlanKey)
+	if bz == nil {
+		return nil
+	}
+	plan := &types.UpgradePlan{}
+	k.cdc.MustUnmarshalBinaryBare(bz, plan)
+	return plan
+}
+
+func (k Keeper) SetUpgradePlan(ctx sdk.Context, plan *types.UpgradePlan) {
+	ctx.KVStore(k.key).Set(types.UpgradePlanKey, k.cdc.MustMarshalBinaryBare(plan))
+}
+
+// ClearUpgradePlan forgets the upgrade plan once it is carried out.
+func (k Keeper) ClearUpgradePlan(ctx sdk.Context) {
+	ctx.KVStore(k.key).Delete(types.UpgradePlanKey)
+}
+
+func (k Keeper) GetProposal(ctx sdk.Context, id string) *types.GovProposal {
+	bz := ctx.KVStore(k.key).Get(types.GovProposalKey(id))
+	if bz == nil {
+		return nil
+	}
+	p := &types.GovProposal{}
+	k.cdc.MustUnmarshalBinaryBare(bz, p)
+	return p
+}
+
+// endKey returns the key indexing a proposal by the end of its current
+// period, or nil once the vote is over.
+func endKey(p *types.GovProposal) []byte {
+	switch p.Status {
+	case types.ProposalDepositPeriod:
+		return append(types.GovProposalByEndPrefix(p.DepositEnd), []byte(p.Id)...)
+	case types.ProposalVotingPeriod:
+		return append(types.GovProposalByEndPrefix(p.VotingEnd), []byte(p.Id)...)
+	}
+	return nil
+}
+
+// SetProposal stores a proposal along with its end index.
+func (k Keeper) SetProposal(ctx sdk.Context, p *types.GovProposal) {
+	store := ctx.KVStore(k.key)
+	if old := k.GetProposal(ctx, p.Id); old != nil {
+		if key := endKey(old); key != nil {
+			store.Delete(key)
+		}
+	}
+	store.Set(types.GovProposalKey(p.Id), k.cdc.MustMarshalBinaryBare(p))
+	if key := endKey(p); key != nil {
+		store.Set(key, []byte(p.Id))
+	}
+}
+
+// DeleteProposal deletes a proposal along with its votes.
+func (k Keeper) DeleteProposal(ctx sdk.Context, p *types.GovProposal) {
+	store := ctx.KVStore(k.key)
+	if key := endKey(p); key != nil {
+		store.Delete(key)
+	}
+	store.Delete(types.GovProposalKey(p.Id))
+	for _, v := range k.getVotes(ctx, p.Id) {
+		store.Delete(types.GovVoteKey(v.Proposal, v.Voter))
+	}
+}
+
+func (k Keeper) IterateProposals(ctx sdk.Context, process func(*types.GovProposal) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("gov-proposal:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		p := &types.GovProposal{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), p)
+		if process(p) {
+			return
+		}
+	}
+}
+
+func (k Keeper) SetVote(ctx sdk.Context, v *types.GovVote) {
+	ctx.KVStore(k.key).Set(types.GovVoteKey(v.Proposal, v.Voter), k.cdc.MustMarshalBinaryBare(v))
+}
+
+// IterateVotes iterates over the votes on all the proposals, or on one
+// proposal only when it is given.
+func (k Keeper) IterateVotes(ctx sdk.Context, proposal string, process func(*types.GovVote) (stop bool)) {
+	prefix := []byte("gov-vote:")
+	if proposal != "" {
+		prefix = types.GovVotePrefix(proposal)
+	}
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, prefix)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		v := &types.GovVote{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), v)
+		if process(v) {
+			return
+		}
+	}
+}
+
+func (k Keeper) getVotes(ctx sdk.Context, proposal string) []*types.GovVote {
+	votes := []*types.GovVote{}
+	k.IterateVotes(ctx, proposal, func(v *types.GovVote) bool {
+		votes = append(votes, v)
+		return false
+	})
+	return votes
+}
+
+func (k Keeper) subtractCoins(ctx sdk.Context, user string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	addr, err := types.AddressFromUserId(user)
+	if err != nil {
+		return nil, sdk.ErrInvalidAddress(user)
+	}
+	_, tags, sdkErr := k.ck.SubtractCoins(ctx, addr, amt)
+	return tags, sdkErr
+}
+
+func (k Keeper) addCoins(ctx sdk.Context, user string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	addr, err := types.AddressFromUserId(user)
+	if err != nil {
+		return nil, sdk.ErrInvalidAddress(user)
+	}
+	_, tags, sdkErr := k.ck.AddCoins(ctx, addr, amt)
+	return tags, sdkErr
+}
+
+// Submit opens a proposal for deposits, with a first deposit by its
+// proposer. The parameter changes of the proposal must be valid now.
+func (k Keeper) Submit(ctx sdk.Context, proposer string, p *types.GovProposal, deposit sdk.Coins) (string, sdk.Tags, sdk.Error) {
+	for _, change := range p.Changes {
+		if err := k.ps.ValidateParam(ctx, change.Key, change.Value); err != nil {
+			return "", nil, err
+		}
+	}
+	if p.Upgrade != nil && p.Upgrade.Height <= ctx.BlockHeight() {
+		return "", nil, ErrInvalidChange(k.codespace, fmt.Sprintf("the upgrade height %d has passed", p.Upgrade.Height))
+	}
+
+	p.Id = k.nextSeq(ctx, proposalSeqKey, func(seq int64) string { return types.EscrowId("gov", seq) }, types.GovProposalKey)
+	p.Proposer = proposer
+	p.Status = types.ProposalDepositPeriod
+	p.Deposits = []*types.GovDeposit{}
+	p.DepositEnd = int(ctx.BlockHeight()) + k.GetParams(ctx).DepositPeriod
+
+	tags := sdk.NewTags("proposal", []byte(p.Id))
+	if !deposit.IsZero() {
+		depositTags, err := k.addDeposit(ctx, p, proposer, deposit)
+		if err != nil {
+			return "", nil, err
+		}
+		tags = tags.AppendTags(depositTags)
+	}
+	k.SetProposal(ctx, p)
+	return p.Id, tags, nil
+}
+
+// Deposit adds to the deposits on a proposal. The voting period starts once
+// the deposits reach the minimum deposit.
+func (k Keeper) Deposit(ctx sdk.Context, depositor string, id string, amount sdk.Coins) (sdk.Tags, sdk.Error) {
+	p := k.GetProposal(ctx, id)
+	if p == nil {
+		return nil, ErrUnknownProposal(k.codespace, id)
+	}
+	if p.Status != types.ProposalDepositPeriod && p.Status != types.ProposalVotingPeriod {
+		return nil, ErrInactive(k.codespace, id, p.Status)
+	}
+	tags, err := k.addDeposit(ctx, p, depositor, amount)
+	if err != nil {
+		return nil, err
+	}
+	k.SetProposal(ctx, p)
+	return tags, nil
+}
+
+func (k Keeper) addDeposit(ctx sdk.Context, p *types.GovProposal, depositor string, amount sdk.Coins) (sdk.Tags, sdk.Error) {
+	tags, err := k.subtractCoins(ctx, depositor, amount)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, d := range p.Deposits {
+		if d.Depositor == depositor {
+			d.Amount = d.Amount.Plus(amount)
+			found = true
+		}
+	}
+	if !found {
+		p.Deposits = append(p.Deposits, &types.GovDeposit{Depositor: depositor, Amount: amount})
+	}
+
+	params := k.GetParams(ctx)
+	if p.Status == types.ProposalDepositPeriod && p.TotalDeposit().IsGTE(params.MinDeposit) {
+		p.Status = types.ProposalVotingPeriod
+		p.VotingEnd = int(ctx.BlockHeight()) + params.VotingPeriod
+		tags = tags.AppendTag("voting-period", []byte(p.Id))
+	}
+	return tags, nil
+}
+
+// VotingPower returns the stake a user has bonded to the bonded validators.
+func (k Keeper) VotingPower(ctx sdk.Context, voter string) sdk.Rat {
+	power := sdk.ZeroRat()
+	addr, err := types.AddressFromUserId(voter)
+	if err != nil {
+		return power
+	}
+	vs := k.ds.GetValidatorSet()
+	k.ds.IterateDelegations(ctx, addr, func(_ int64, d sdk.Delegation) bool {
+		v := vs.Validator(ctx, d.GetValidator())
+		if v == nil || v.GetStatus() != sdk.Bonded || v.GetDelegatorShares().IsZero() {
+			return false
+		}
+		power = power.Add(d.GetBondShares().Mul(v.GetTokens()).Quo(v.GetDelegatorShares()))
+		return false
+	})
+	return power
+}
+
+// Vote records the vote of a staker on a proposal in its voting period. A
+// staker may change its vote until the voting period ends.
+func (k Keeper) Vote(ctx sdk.Context, voter string, id string, option string) (sdk.Tags, sdk.Error) {
+	p := k.GetProposal(ctx, id)
+	if p == nil {
+		return nil, ErrUnknownProposal(k.codespace, id)
+	}
+	if p.Status != types.ProposalVotingPeriod {
+		return nil, ErrInactive(k.codespace, id, p.Status)
+	}
+	if k.VotingPower(ctx, voter).IsZero() {
+		return nil, ErrNoStake(k.codespace, voter)
+	}
+	k.SetVote(ctx, &types.GovVote{Proposal: id, Voter: voter, Option: option})
+	return sdk.NewTags("proposal", []byte(id), "voter", []byte(voter)), nil
+}
+
+// Tally counts the votes on a proposal with the current stake of the voters.
+func (k Keeper) Tally(ctx sdk.Context, id string) *types.TallyResult {
+	res := &types.TallyResult{Yes: sdk.ZeroRat(), No: sdk.ZeroRat(), Abstain: sdk.ZeroRat(), NoWithVeto: sdk.ZeroRat()}
+	for _, v := range k.getVotes(ctx, id) {
+		power := k.VotingPower(ctx, v.Voter)
+		switch v.Option {
+		case types.VoteYes:
+			res.Yes = res.Yes.Add(power)
+		case types.VoteNo:
+			res.No = res.No.Add(power)
+		case types.VoteAbstain:
+			res.Abstain = res.Abstain.Add(power)
+		case types.VoteNoWithVeto:
+			res.NoWithVeto = res.NoWithVeto.Add(power)
+		}
+	}
+	return res
+}
+
+// passes tells whether a tally passes a proposal under the governance
+// rules, and whether it vetoes it.
+func passes(res *types.TallyResult, params *types.GovParams, bonded sdk.Rat) (passed bool, vetoed bool) {
+	voted := res.Yes.Add(res.No).Add(res.Abstain).Add(res.NoWithVeto)
+	if voted.IsZero() || voted.LT(bonded.Mul(sdk.NewRat(int64(params.Quorum), 100))) {
+		return false, false
+	}
+	if !res.NoWithVeto.LT(voted.Mul(sdk.NewRat(int64(params.Veto), 100))) {
+		return false, true
+	}
+	against := res.Yes.Add(res.No).Add(res.NoWithVeto)
+	return !against.IsZero() && res.Yes.GT(against.Mul(sdk.NewRat(int64(params.Threshold), 100))), false
+}
+
+// execute applies the changes of a proposal, all of them or none.
+func (k Keeper) execute(ctx sdk.Context, p *types.GovProposal) sdk.Error {
+	switch p.Kind {
+	case types.ProposalParamChange:
+		cacheCtx, write := ctx.CacheContext()
+		for _, change := range p.Changes {
+			if err := k.ps.SetParam(cacheCtx, change.Key, change.Value); err != nil {
+				return err
+			}
+		}
+		write()
+	case types.ProposalSoftwareUpgrade:
+		if p.Upgrade.Height <= ctx.BlockHeight() {
+			return ErrInvalidChange(k.codespace, fmt.Sprintf("the upgrade height %d has passed", p.Upgrade.Height))
+		}
+		k.SetUpgradePlan(ctx, p.Upgrade)
+	}
+	return nil
+}
+
+// refund gives the deposits on a proposal back to their depositors.
+func (k Keeper) refund(ctx sdk.Context, p *types.GovProposal) sdk.Tags {
+	tags := sdk.EmptyTags()
+	for _, d := range p.Deposits {
+		addTags, err := k.addCoins(ctx, d.Depositor, d.Amount)
+		if err != nil {
+			panic(err)
+		}
+		tags = tags.AppendTags(addTags)
+	}
+	return tags
+}
+
+// EndBlocker drops the proposals whose deposit period ended short of the
+// minimum deposit, and closes the vote on those whose voting period ended.
+// Passed proposals take effect right away.
+func EndBlocker(ctx sdk.Context, k Keeper) sdk.Tags {
+	store := ctx.KVStore(k.key)
+	start := []byte("gov-proposal-end:")
+	end := types.GovProposalByEndPrefix(int(ctx.BlockHeight()) + 1)
+	iter := store.Iterator(start, end)
+	var ended []string
+	for ; iter.Valid(); iter.Next() {
+		ended = append(ended, string(iter.Value()))
+	}
+	iter.Close()
+
+	tags := sdk.EmptyTags()
+	params := k.GetParams(ctx)
+	for _, id := range ended {
+		p := k.GetProposal(ctx, id)
+		if p.Status == types.ProposalDepositPeriod {
+			tags = tags.AppendTags(k.refund(ctx, p))
+			k.DeleteProposal(ctx, p)
+			tags = tags.AppendTags(sdk.NewTags("proposal", []byte(id), "result", []byte("dropped")))
+			continue
+		}
+
+		p.Tally = k.Tally(ctx, id)
+		passed, vetoed := passes(p.Tally, params, k.ds.GetValidatorSet().TotalPower(ctx))
+		switch {
+		case passed:
+			p.Status = types.ProposalPassed
+			if err := k.execute(ctx, p); err != nil {
+				p.Status = types.ProposalFailed
+				ctx.Logger().With("module", "x/gov").Error(fmt.Sprintf("proposal %s failed: %s", id, err.Error()))
+			}
+		default:
+			p.Status = types.ProposalRejected
+		}
+		if !vetoed {
+			tags = tags.AppendTags(k.refund(ctx, p))
+		}
+		for _, v := range k.getVotes(ctx, id) {
+			store.Delete(types.GovVoteKey(v.Proposal, v.Voter))
+		}
+		k.SetProposal(ctx, p)
+		tags = tags.AppendTags(sdk.NewTags("proposal", []byte(id), "result", []byte(p.Status)))
+	}
+	return tags
+}
+
+// InitGenesis loads the governance rules, proposals and votes from the
+// genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, params *types.GovParams, proposals []*types.GovProposal, votes []*types.GovVote, plan *types.UpgradePlan) {
+	if params != nil {
+		if err := k.SetParams(ctx, params); err != nil {
+			panic(err)
+		}
+	}
+	for _, p := range proposals {
+		k.SetProposal(ctx, p)
+	}
+	for _, v := range votes {
+		k.SetVote(ctx, v)
+	}
+	if plan != nil {
+		k.SetUpgradePlan(ctx, plan)
+	}
+}
+
+// WriteGenesis returns the governance rules, proposals and votes for the
+// genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) (*types.GovParams, []*types.GovProposal, []*types.GovVote, *types.UpgradePlan) {
+	proposals := []*types.GovProposal{}
+	k.IterateProposals(ctx, func(p *types.GovProposal) bool {
+		proposals = append(proposals, p)
+		return false
+	})
+	return k.GetParams(ctx), proposals, k.getVotes(ctx, ""), k.GetUpgradePlan(ctx)
+}
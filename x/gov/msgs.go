@@ -0,0 +1,150 @@
+package gov
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgSubmitProposal submits a proposal of some kind, with a first deposit.
+// Parameter-change proposals carry their Changes, and software-upgrade
+// proposals their Upgrade plan.
+type MsgSubmitProposal struct {
+	ProposerAccount sdk.AccAddress      `json:"proposer-account"`
+	Proposer        string              `json:"proposer"`
+	Kind            string              `json:"kind"`
+	Title           string              `json:"title"`
+	Description     string              `json:"description"`
+	Changes         []types.ParamChange `json:"changes"`
+	Upgrade         *types.UpgradePlan  `json:"upgrade"`
+	Deposit         sdk.Coins           `json:"deposit"`
+}
+
+var _ sdk.Msg = MsgSubmitProposal{}
+
+// Implements sdk.Msg
+func (msg MsgSubmitProposal) Type() string { return "gov" }
+
+// Implements sdk.Msg
+func (msg MsgSubmitProposal) ValidateBasic() sdk.Error {
+	if msg.Proposer != types.UserId(msg.ProposerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Proposer))
+	}
+	if msg.Title == "" {
+		return sdk.ErrUnknownRequest("no title given")
+	}
+	switch msg.Kind {
+	case types.ProposalText:
+		if len(msg.Changes) > 0 || msg.Upgrade != nil {
+			return sdk.ErrUnknownRequest("a text proposal changes nothing")
+		}
+	case types.ProposalParamChange:
+		if len(msg.Changes) == 0 || msg.Upgrade != nil {
+			return sdk.ErrUnknownRequest("a parameter-change proposal changes parameters only")
+		}
+	case types.ProposalSoftwareUpgrade:
+		if len(msg.Changes) > 0 || msg.Upgrade == nil || msg.Upgrade.Name == "" {
+			return sdk.ErrUnknownRequest("a software-upgrade proposal needs a named upgrade plan only")
+		}
+	default:
+		return sdk.ErrUnknownRequest(fmt.Sprintf("unknown proposal kind %s", msg.Kind))
+	}
+	if !msg.Deposit.IsValid() || !msg.Deposit.IsNotNegative() {
+		return sdk.ErrInvalidCoins(msg.Deposit.String())
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgSubmitProposal) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgSubmitProposal) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ProposerAccount}
+}
+
+// MsgDeposit adds to the deposits on a proposal.
+type MsgDeposit struct {
+	DepositorAccount sdk.AccAddress `json:"depositor-account"`
+	Depositor        string         `json:"depositor"`
+	Proposal         string         `json:"proposal"`
+	Amount           sdk.Coins      `json:"amount"`
+}
+
+var _ sdk.Msg = MsgDeposit{}
+
+// Implements sdk.Msg
+func (msg MsgDeposit) Type() string { return "gov" }
+
+// Implements sdk.Msg
+func (msg MsgDeposit) ValidateBasic() sdk.Error {
+	if msg.Depositor != types.UserId(msg.DepositorAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Depositor))
+	}
+	if !msg.Amount.IsValid() || !msg.Amount.IsPositive() {
+		return sdk.ErrInvalidCoins(msg.Amount.String())
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgDeposit) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgDeposit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.DepositorAccount}
+}
+
+// MsgVote casts the vote of a staker on a proposal in its voting period.
+type MsgVote struct {
+	VoterAccount sdk.AccAddress `json:"voter-account"`
+	Voter        string         `json:"voter"`
+	Proposal     string         `json:"proposal"`
+	Option       string         `json:"option"`
+}
+
+var _ sdk.Msg = MsgVote{}
+
+// Implements sdk.Msg
+func (msg MsgVote) Type() string { return "gov" }
+
+// Implements sdk.Msg
+func (msg MsgVote) ValidateBasic() sdk.Error {
+	if msg.Voter != types.UserId(msg.VoterAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Voter))
+	}
+	switch msg.Option {
+	case types.VoteYes, types.VoteNo, types.VoteAbstain, types.VoteNoWithVeto:
+		return nil
+	}
+	return sdk.ErrUnknownRequest(fmt.Sprintf("unknown vote option %s", msg.Option))
+}
+
+// Implements sdk.Msg
+func (msg MsgVote) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgVote) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.VoterAccount}
+}
@@ -0,0 +1,23 @@
+package guarantee
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Guarantee errors reserve 700 ~ 799.
+const (
+	DefaultCodespace sdk.CodespaceType = 17
+
+	CodeNotSettled     sdk.CodeType = 701
+	CodeAlreadySettled sdk.CodeType = 702
+)
+
+func ErrNotSettled(codespace sdk.CodespaceType, codex string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotSettled, fmt.Sprintf("the codex %s is still active", codex))
+}
+
+func ErrAlreadySettled(codespace sdk.CodespaceType, codex string, status string) sdk.Error {
+	return sdk.NewError(codespace, CodeAlreadySettled, fmt.Sprintf("the codex %s is already %s", codex, status))
+}
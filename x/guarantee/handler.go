@@ -0,0 +1,49 @@
+package guarantee
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "guarantee" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgCloseCodex:
+			return handleMsgCloseCodex(ctx, k, msg)
+		case *MsgDeclareDefault:
+			return handleMsgDeclareDefault(ctx, k, msg)
+		case *MsgClaimCompensation:
+			return handleMsgClaimCompensation(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized guarantee Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgCloseCodex(ctx sdk.Context, k Keeper, msg *MsgCloseCodex) sdk.Result {
+	tags, err := k.CloseCodex(ctx, msg.Owner, msg.Codex)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgDeclareDefault(ctx sdk.Context, k Keeper, msg *MsgDeclareDefault) sdk.Result {
+	tags, err := k.DeclareDefault(ctx, msg.Arbiter, msg.Codex)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgClaimCompensation(ctx sdk.Context, k Keeper, msg *MsgClaimCompensation) sdk.Result {
+	tags, err := k.ClaimCompensation(ctx, msg.Holder, msg.Voucher)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
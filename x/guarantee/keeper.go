@@ -0,0 +1,148 @@
+package guarantee
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/dispute"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+)
+
+// AbandonPeriod is the number of blocks without any action of its owner after
+// which a codex is considered abandoned.
+const AbandonPeriod = 100000
+
+// Keeper turns the deposit of a codex into a guarantee for the holders of its
+// vouchers. A codex is settled when its owner closes it, when an arbiter rules
+// it in default, or when it is found abandoned. Settling splits the deposit
+// evenly among the outstanding vouchers, and each holder may then trade a
+// voucher for its share.
+type Keeper struct {
+	sk shop.Keeper
+	dk dispute.Keeper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(sk shop.Keeper, dk dispute.Keeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{sk: sk, dk: dk, codespace: codespace}
+}
+
+// IsAbandoned tells whether the owner of an active codex has not acted for
+// longer than AbandonPeriod.
+func IsAbandoned(ctx sdk.Context, cod *types.Codex) bool {
+	return cod.Status == types.CodexActive && ctx.BlockHeight()-int64(cod.LastActive) > AbandonPeriod
+}
+
+// settle stops the sales of a codex and fixes the share of its deposit owed
+// to each outstanding voucher. Expired vouchers are owed nothing. Vouchers
+// held in escrow, e.g. under a pending transfer, a refund request, a dispute,
+// an HTLC or a market order, are outstanding too: their share is kept for
+// whoever holds them once the escrow lets go of them. The remainder of the
+// division stays with the codex.
+func (k Keeper) settle(ctx sdk.Context, cod *types.Codex, status string) sdk.Tags {
+	outstanding := 0
+	k.sk.VoucherMapper().IterateCodexVouchers(ctx, cod.Id, func(vou *types.Voucher) bool {
+		if !vou.IsExpired(ctx.BlockHeight()) {
+			outstanding++
+		}
+		return false
+	})
+	cod.Status = status
+	cod.CountAvail = 0
+	if outstanding > 0 {
+		cod.Share = cod.Deposit / outstanding
+	}
+	k.sk.CodexMapper().SetCodex(ctx, cod)
+	return sdk.NewTags("action", []byte("settle"), "codex", []byte(cod.Id), "status", []byte(status))
+}
+
+// CloseCodex settles a codex at the request of its owner.
+func (k Keeper) CloseCodex(ctx sdk.Context, owner string, codexId string) (sdk.Tags, sdk.Error) {
+	cod := k.sk.CodexMapper().GetCodex(ctx, codexId)
+	if cod == nil {
+		return nil, shop.ErrUnknownCodex(k.sk.Codespace(), codexId)
+	}
+	if cod.Owner != owner {
+		return nil, shop.ErrNotCodexOwner(k.sk.Codespace(), owner, codexId)
+	}
+	if cod.Status != types.CodexActive {
+		return nil, ErrAlreadySettled(k.codespace, codexId, cod.Status)
+	}
+	return k.settle(ctx, cod, types.CodexClosed), nil
+}
+
+// DeclareDefault settles a codex on the ruling of an arbiter.
+func (k Keeper) DeclareDefault(ctx sdk.Context, arbiter string, codexId string) (sdk.Tags, sdk.Error) {
+	cod := k.sk.CodexMapper().GetCodex(ctx, codexId)
+	if cod == nil {
+		return nil, shop.ErrUnknownCodex(k.sk.Codespace(), codexId)
+	}
+	if !k.dk.IsArbiter(ctx, cod, arbiter) {
+		return nil, dispute.ErrNotArbiter(k.dk.Codespace(), arbiter, codexId)
+	}
+	if cod.Status != types.CodexActive {
+		return nil, ErrAlreadySettled(k.codespace, codexId, cod.Status)
+	}
+	return k.settle(ctx, cod, types.CodexDefaulted), nil
+}
+
+// ClaimCompensation burns a voucher of a settled codex and pays its holder
+// the voucher's share of the deposit, along with any balance left on it and
+// its escrowed proceeds. A codex found abandoned is settled first.
+func (k Keeper) ClaimCompensation(ctx sdk.Context, holder string, voucherId string) (sdk.Tags, sdk.Error) {
+	vou, err := k.sk.GetLiveVoucher(ctx, voucherId)
+	if err != nil {
+		return nil, err
+	}
+	if vou.Holder != holder {
+		return nil, shop.ErrNotHolder(k.sk.Codespace(), holder, voucherId)
+	}
+	cod := k.sk.CodexMapper().GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, shop.ErrUnknownCodex(k.sk.Codespace(), vou.Origin)
+	}
+
+	tags := sdk.EmptyTags()
+	if IsAbandoned(ctx, cod) {
+		tags = tags.AppendTags(k.settle(ctx, cod, types.CodexAbandoned))
+	}
+	if cod.Status == types.CodexActive {
+		return nil, ErrNotSettled(k.codespace, cod.Id)
+	}
+
+	// the deposit may have shrunk since settling, e.g. by dispute rulings
+	share := cod.Share
+	if cod.Deposit < share {
+		share = cod.Deposit
+	}
+	if share > 0 {
+		sendTags, err := k.sk.SendCoins(ctx, cod.Id, holder,
+			sdk.Coins{sdk.NewInt64Coin(types.DenomSilver, int64(share))})
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(sendTags)
+	}
+	releaseTags, err := k.sk.ReleaseProceeds(ctx, vou, holder)
+	if err != nil {
+		return nil, err
+	}
+	tags = tags.AppendTags(releaseTags)
+	balanceTags, err := k.sk.ReleaseBalance(ctx, vou, holder, vou.Balance)
+	if err != nil {
+		return nil, err
+	}
+	tags = tags.AppendTags(balanceTags)
+	if err := k.sk.BurnVoucher(ctx, holder, voucherId); err != nil {
+		return nil, err
+	}
+	return tags.AppendTags(sdk.NewTags(
+		"action", []byte("claim-compensation"),
+		"voucher", []byte(voucherId),
+		"share", []byte(fmt.Sprintf("%d", share)),
+	)), nil
+}
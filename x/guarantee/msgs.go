@@ -0,0 +1,122 @@
+package guarantee
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgCloseCodex is sent by the owner of a codex to stop its business and
+// settle its deposit among the holders of its vouchers.
+type MsgCloseCodex struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Codex        string         `json:"codex"`
+}
+
+var _ types.OwnerMsg = MsgCloseCodex{}
+
+// Implements sdk.Msg
+func (msg MsgCloseCodex) Type() string { return "guarantee" }
+
+// Implements sdk.Msg
+func (msg MsgCloseCodex) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return msg.ValidateFields()
+}
+
+// Implements types.OwnerMsg
+func (msg MsgCloseCodex) ValidateFields() sdk.Error {
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgCloseCodex) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgCloseCodex) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// Implements types.OwnerMsg
+func (msg MsgCloseCodex) GetOwner() string { return msg.Owner }
+
+// MsgDeclareDefault is sent by an arbiter to rule a codex in default.
+type MsgDeclareDefault struct {
+	ArbiterAccount sdk.AccAddress `json:"arbiter-account"`
+	Arbiter        string         `json:"arbiter"`
+	Codex          string         `json:"codex"`
+}
+
+var _ sdk.Msg = MsgDeclareDefault{}
+
+// Implements sdk.Msg
+func (msg MsgDeclareDefault) Type() string { return "guarantee" }
+
+// Implements sdk.Msg
+func (msg MsgDeclareDefault) ValidateBasic() sdk.Error {
+	if msg.Arbiter != types.UserId(msg.ArbiterAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Arbiter))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgDeclareDefault) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgDeclareDefault) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ArbiterAccount}
+}
+
+// MsgClaimCompensation is sent by the holder of a voucher of a closed,
+// abandoned or defaulted codex to trade it for a share of the deposit.
+type MsgClaimCompensation struct {
+	HolderAccount sdk.AccAddress `json:"holder-account"`
+	Holder        string         `json:"holder"`
+	Voucher       string         `json:"voucher"`
+}
+
+var _ sdk.Msg = MsgClaimCompensation{}
+
+// Implements sdk.Msg
+func (msg MsgClaimCompensation) Type() string { return "guarantee" }
+
+// Implements sdk.Msg
+func (msg MsgClaimCompensation) ValidateBasic() sdk.Error {
+	if msg.Holder != types.UserId(msg.HolderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Holder))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgClaimCompensation) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgClaimCompensation) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.HolderAccount}
+}
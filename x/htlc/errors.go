@@ -0,0 +1,28 @@
+package htlc
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// HTLC errors reserve 400 ~ 499.
+const (
+	DefaultCodespace sdk.CodespaceType = 14
+
+	CodeUnknownHTLC     sdk.CodeType = 401
+	CodeInvalidPreimage sdk.CodeType = 402
+	CodeTimeout         sdk.CodeType = 403
+)
+
+func ErrUnknownHTLC(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownHTLC, fmt.Sprintf("no HTLC found with the id %s", id))
+}
+
+func ErrInvalidPreimage(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidPreimage, fmt.Sprintf("the preimage does not match the hash lock of %s", id))
+}
+
+func ErrTimeout(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeTimeout, msg)
+}
@@ -0,0 +1,49 @@
+package htlc
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "htlc" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgCreateHTLC:
+			return handleMsgCreateHTLC(ctx, k, msg)
+		case *MsgClaimHTLC:
+			return handleMsgClaimHTLC(ctx, k, msg)
+		case *MsgRefundHTLC:
+			return handleMsgRefundHTLC(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized htlc Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgCreateHTLC(ctx sdk.Context, k Keeper, msg *MsgCreateHTLC) sdk.Result {
+	h, tags, err := k.Create(ctx, msg.Sender, msg.Recipient, msg.Asset, msg.HashLock, msg.Timeout)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(h.Id), Tags: tags}
+}
+
+func handleMsgClaimHTLC(ctx sdk.Context, k Keeper, msg *MsgClaimHTLC) sdk.Result {
+	tags, err := k.Claim(ctx, msg.Recipient, msg.HTLC, msg.Preimage)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgRefundHTLC(ctx sdk.Context, k Keeper, msg *MsgRefundHTLC) sdk.Result {
+	tags, err := k.Refund(ctx, msg.Sender, msg.HTLC)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
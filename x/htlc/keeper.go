@@ -0,0 +1,184 @@
+package htlc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+)
+
+// Keeper manages hash time-locked contracts. Assets are locked and released
+// through the shop keeper, the same way pending transfers are.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	sk  shop.Keeper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, sk shop.Keeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, sk: sk, codespace: codespace}
+}
+
+var htlcSeqKey = []byte("htlc-seq")
+
+// nextHTLCId returns a fresh id for a new HTLC. Ids taken by contracts loaded
+// from genesis are skipped.
+func (k Keeper) nextHTLCId(ctx sdk.Context) string {
+	store := ctx.KVStore(k.key)
+	var seq int64
+	bz := store.Get(htlcSeqKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &seq)
+	}
+	for {
+		id := types.EscrowId("htlc", seq)
+		seq++
+		if !store.Has(types.HTLCKey(id)) {
+			store.Set(htlcSeqKey, k.cdc.MustMarshalBinaryBare(seq))
+			return id
+		}
+	}
+}
+
+func (k Keeper) GetHTLC(ctx sdk.Context, id string) *types.HTLC {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.HTLCKey(id))
+	if bz == nil {
+		return nil
+	}
+	h := &types.HTLC{}
+	k.cdc.MustUnmarshalBinaryBare(bz, h)
+	return h
+}
+
+func (k Keeper) SetHTLC(ctx sdk.Context, h *types.HTLC) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.HTLCKey(h.Id), k.cdc.MustMarshalBinaryBare(h))
+}
+
+func (k Keeper) DeleteHTLC(ctx sdk.Context, id string) {
+	store := ctx.KVStore(k.key)
+	store.Delete(types.HTLCKey(id))
+}
+
+func (k Keeper) IterateHTLCs(ctx sdk.Context, process func(*types.HTLC) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("htlc:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		h := &types.HTLC{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), h)
+		if process(h) {
+			return
+		}
+	}
+}
+
+// Create locks an asset of the sender in a new HTLC.
+func (k Keeper) Create(ctx sdk.Context, sender string, recipient string, asset types.BvsAsset, hashLock []byte, timeout int64) (*types.HTLC, sdk.Tags, sdk.Error) {
+	if types.IdKind(recipient) != types.KindUser {
+		return nil, nil, shop.ErrInvalidId(k.sk.Codespace(), recipient)
+	}
+	if timeout <= ctx.BlockHeight() {
+		return nil, nil, ErrTimeout(k.codespace, fmt.Sprintf("the timeout %d has already passed", timeout))
+	}
+	h := &types.HTLC{
+		Id:        k.nextHTLCId(ctx),
+		Sender:    sender,
+		Recipient: recipient,
+		Vouchers:  asset.Vouchers,
+		HashLock:  hashLock,
+		Timeout:   timeout,
+	}
+	if !asset.Coins.IsZero() {
+		h.Coins = asset.Coins
+	}
+	tags, err := k.sk.LockAsset(ctx, sender, h.Id, recipient, asset)
+	if err != nil {
+		return nil, nil, err
+	}
+	k.SetHTLC(ctx, h)
+
+	tags = tags.AppendTags(sdk.NewTags(
+		"action", []byte("create-htlc"),
+		"htlc", []byte(h.Id),
+		"recipient", []byte(recipient),
+		"hash-lock", []byte(hex.EncodeToString(hashLock)),
+	))
+	return h, tags, nil
+}
+
+// Claim hands the assets of an HTLC over to its recipient on presentation of
+// the preimage before the timeout. The preimage is tagged so that the
+// counterparty of a swap can pick it up.
+func (k Keeper) Claim(ctx sdk.Context, recipient string, id string, preimage []byte) (sdk.Tags, sdk.Error) {
+	h := k.GetHTLC(ctx, id)
+	if h == nil {
+		return nil, ErrUnknownHTLC(k.codespace, id)
+	}
+	if h.Recipient != recipient {
+		return nil, sdk.ErrUnauthorized(fmt.Sprintf("%s is not the recipient of %s", recipient, id))
+	}
+	if ctx.BlockHeight() >= h.Timeout {
+		return nil, ErrTimeout(k.codespace, fmt.Sprintf("%s timed out at %d", id, h.Timeout))
+	}
+	hash := sha256.Sum256(preimage)
+	if !bytes.Equal(hash[:], h.HashLock) {
+		return nil, ErrInvalidPreimage(k.codespace, id)
+	}
+	tags, err := k.close(ctx, h, h.Recipient, "claim-htlc")
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTag("preimage", []byte(hex.EncodeToString(preimage))), nil
+}
+
+// Refund returns the assets of an HTLC to its sender once it has timed out.
+func (k Keeper) Refund(ctx sdk.Context, sender string, id string) (sdk.Tags, sdk.Error) {
+	h := k.GetHTLC(ctx, id)
+	if h == nil {
+		return nil, ErrUnknownHTLC(k.codespace, id)
+	}
+	if h.Sender != sender {
+		return nil, sdk.ErrUnauthorized(fmt.Sprintf("%s is not the sender of %s", sender, id))
+	}
+	if ctx.BlockHeight() < h.Timeout {
+		return nil, ErrTimeout(k.codespace, fmt.Sprintf("%s can be refunded from %d", id, h.Timeout))
+	}
+	return k.close(ctx, h, h.Sender, "refund-htlc")
+}
+
+func (k Keeper) close(ctx sdk.Context, h *types.HTLC, to string, action string) (sdk.Tags, sdk.Error) {
+	tags, err := k.sk.ReleaseAsset(ctx, h.Id, to, types.BvsAsset{Coins: h.Coins, Vouchers: h.Vouchers})
+	if err != nil {
+		return nil, err
+	}
+	k.DeleteHTLC(ctx, h.Id)
+	return tags.AppendTags(sdk.NewTags("action", []byte(action), "htlc", []byte(h.Id))), nil
+}
+
+// InitGenesis loads the HTLCs of the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, htlcs []*types.HTLC) {
+	for _, h := range htlcs {
+		k.SetHTLC(ctx, h)
+	}
+}
+
+// WriteGenesis returns the open HTLCs for the genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) []*types.HTLC {
+	htlcs := []*types.HTLC{}
+	k.IterateHTLCs(ctx, func(h *types.HTLC) bool {
+		htlcs = append(htlcs, h)
+		return false
+	})
+	return htlcs
+}
@@ -0,0 +1,128 @@
+package htlc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgCreateHTLC locks an asset of the sender under a hash lock until the
+// timeout height.
+type MsgCreateHTLC struct {
+	SenderAccount sdk.AccAddress `json:"sender-account"`
+	Sender        string         `json:"sender"`
+	Recipient     string         `json:"recipient"`
+	Asset         types.BvsAsset `json:"asset"`
+	HashLock      []byte         `json:"hash-lock"`
+	Timeout       int64          `json:"timeout"`
+}
+
+var _ sdk.Msg = MsgCreateHTLC{}
+
+// Implements sdk.Msg
+func (msg MsgCreateHTLC) Type() string { return "htlc" }
+
+// Implements sdk.Msg
+func (msg MsgCreateHTLC) ValidateBasic() sdk.Error {
+	if msg.Sender != types.UserId(msg.SenderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Sender))
+	}
+	if !msg.Asset.Coins.IsValid() || !msg.Asset.Coins.IsNotNegative() {
+		return sdk.ErrInvalidCoins(msg.Asset.Coins.String())
+	}
+	if msg.Asset.Coins.IsZero() && len(msg.Asset.Vouchers) == 0 {
+		return sdk.ErrUnknownRequest("nothing to lock")
+	}
+	if len(msg.HashLock) != 32 {
+		return sdk.ErrUnknownRequest("the hash lock must be a SHA-256 digest")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgCreateHTLC) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgCreateHTLC) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.SenderAccount}
+}
+
+// MsgClaimHTLC is sent by the recipient of an HTLC to take over its assets by
+// revealing the preimage of the hash lock.
+type MsgClaimHTLC struct {
+	RecipientAccount sdk.AccAddress `json:"recipient-account"`
+	Recipient        string         `json:"recipient"`
+	HTLC             string         `json:"htlc"`
+	Preimage         []byte         `json:"preimage"`
+}
+
+var _ sdk.Msg = MsgClaimHTLC{}
+
+// Implements sdk.Msg
+func (msg MsgClaimHTLC) Type() string { return "htlc" }
+
+// Implements sdk.Msg
+func (msg MsgClaimHTLC) ValidateBasic() sdk.Error {
+	if msg.Recipient != types.UserId(msg.RecipientAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Recipient))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgClaimHTLC) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgClaimHTLC) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.RecipientAccount}
+}
+
+// MsgRefundHTLC is sent by the sender of an HTLC to take back its assets
+// after the timeout.
+type MsgRefundHTLC struct {
+	SenderAccount sdk.AccAddress `json:"sender-account"`
+	Sender        string         `json:"sender"`
+	HTLC          string         `json:"htlc"`
+}
+
+var _ sdk.Msg = MsgRefundHTLC{}
+
+// Implements sdk.Msg
+func (msg MsgRefundHTLC) Type() string { return "htlc" }
+
+// Implements sdk.Msg
+func (msg MsgRefundHTLC) ValidateBasic() sdk.Error {
+	if msg.Sender != types.UserId(msg.SenderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Sender))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgRefundHTLC) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgRefundHTLC) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.SenderAccount}
+}
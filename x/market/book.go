@@ -0,0 +1,337 @@
+package market
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// OrderPeriod is the number of blocks an order stays in the book unless its
+// trader asks for another period.
+const OrderPeriod = 10000
+
+var orderSeqKey = []byte("order-seq")
+
+// nextOrderSeq returns a fresh sequence number for a new order. Sequence
+// numbers taken by orders loaded from genesis are skipped.
+func (k Keeper) nextOrderSeq(ctx sdk.Context) int64 {
+	store := ctx.KVStore(k.key)
+	var seq int64
+	bz := store.Get(orderSeqKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &seq)
+	}
+	for {
+		next := seq
+		seq++
+		if !store.Has(types.OrderKey(orderId(next))) {
+			store.Set(orderSeqKey, k.cdc.MustMarshalBinaryBare(seq))
+			return next
+		}
+	}
+}
+
+func orderId(seq int64) string {
+	return types.EscrowId("order", seq)
+}
+
+func (k Keeper) GetOrder(ctx sdk.Context, id string) *types.Order {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.OrderKey(id))
+	if bz == nil {
+		return nil
+	}
+	o := &types.Order{}
+	k.cdc.MustUnmarshalBinaryBare(bz, o)
+	return o
+}
+
+// SetOrder stores an order along with its book, trader and expiry indices.
+func (k Keeper) SetOrder(ctx sdk.Context, o *types.Order) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.OrderKey(o.Id), k.cdc.MustMarshalBinaryBare(o))
+	store.Set(types.OrderBookKey(o), []byte(o.Id))
+	store.Set(append(types.OrderByTraderPrefix(o.Trader), []byte(o.Id)...), []byte(o.Id))
+	store.Set(append(types.OrderByExpiryPrefix(o.ExpireOn), []byte(o.Id)...), []byte(o.Id))
+}
+
+func (k Keeper) DeleteOrder(ctx sdk.Context, o *types.Order) {
+	store := ctx.KVStore(k.key)
+	store.Delete(types.OrderKey(o.Id))
+	store.Delete(types.OrderBookKey(o))
+	store.Delete(append(types.OrderByTraderPrefix(o.Trader), []byte(o.Id)...))
+	store.Delete(append(types.OrderByExpiryPrefix(o.ExpireOn), []byte(o.Id)...))
+}
+
+func (k Keeper) IterateOrders(ctx sdk.Context, process func(*types.Order) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("order:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		o := &types.Order{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), o)
+		if process(o) {
+			return
+		}
+	}
+}
+
+// touchBook marks the order book of a codex to be matched at the end of the
+// block.
+func (k Keeper) touchBook(ctx sdk.Context, codex string) {
+	ctx.KVStore(k.key).Set(types.Id2StoreKey("book-touched:", codex), []byte(codex))
+}
+
+// checkTradable tells whether vouchers of a codex are fungible enough to be
+// traded through the order book.
+func (k Keeper) checkTradable(ctx sdk.Context, codexId string) sdk.Error {
+	cod := k.sk.CodexMapper().GetCodex(ctx, codexId)
+	if cod == nil {
+		return ErrNotTradable(k.codespace, codexId, "unknown codex")
+	}
+	if cod.TransferPolicy() == types.TransferNone {
+		return ErrNotTradable(k.codespace, codexId, "non-transferable")
+	}
+	if cod.StoredValue {
+		return ErrNotTradable(k.codespace, codexId, "vouchers carry their own balance")
+	}
+	return nil
+}
+
+// PlaceOrder puts a bid or an ask in the order book of a codex. An ask takes
+// the vouchers it sells into escrow, and a bid the coins to pay for all the
+// vouchers it buys at its price.
+func (k Keeper) PlaceOrder(ctx sdk.Context, trader string, codexId string, side string, price sdk.Coin, quantity int, vouchers []string, expireAfter int) (*types.Order, sdk.Tags, sdk.Error) {
+	if err := k.checkTradable(ctx, codexId); err != nil {
+		return nil, nil, err
+	}
+	if err := k.sk.CheckDenom(ctx, price.Denom); err != nil {
+		return nil, nil, err
+	}
+	if expireAfter <= 0 {
+		expireAfter = OrderPeriod
+	}
+	seq := k.nextOrderSeq(ctx)
+	o := &types.Order{
+		Id:       orderId(seq),
+		Seq:      seq,
+		Codex:    codexId,
+		Side:     side,
+		Trader:   trader,
+		Price:    price,
+		Quantity: quantity,
+		PlacedOn: int(ctx.BlockHeight()),
+		ExpireOn: int(ctx.BlockHeight()) + expireAfter,
+	}
+
+	tags := sdk.NewTags("action", []byte("place-order"), "codex", []byte(codexId), "order", []byte(o.Id))
+	if side == types.OrderAsk {
+		for _, id := range vouchers {
+			vou, err := k.sk.GetLiveVoucher(ctx, id)
+			if err != nil {
+				return nil, nil, err
+			}
+			if vou.Origin != codexId {
+				return nil, nil, ErrNotTradable(k.codespace, codexId, fmt.Sprintf("%s is not one of its vouchers", id))
+			}
+			if err := k.sk.FreezeVoucher(ctx, trader, o.Id, id); err != nil {
+				return nil, nil, err
+			}
+		}
+		o.Vouchers = vouchers
+		o.Quantity = len(vouchers)
+	} else {
+		o.Escrow = sdk.Coins{sdk.Coin{Denom: price.Denom, Amount: price.Amount.MulRaw(int64(quantity))}}
+		subTags, err := k.sk.SubtractCoins(ctx, trader, o.Escrow)
+		if err != nil {
+			return nil, nil, err
+		}
+		tags = tags.AppendTags(subTags)
+	}
+	k.SetOrder(ctx, o)
+	k.touchBook(ctx, codexId)
+	return o, tags, nil
+}
+
+// CancelOrder takes an order out of the book on behalf of its trader.
+func (k Keeper) CancelOrder(ctx sdk.Context, trader string, id string) (sdk.Tags, sdk.Error) {
+	o := k.GetOrder(ctx, id)
+	if o == nil {
+		return nil, ErrUnknownOrder(k.codespace, id)
+	}
+	if o.Trader != trader {
+		return nil, ErrNotTrader(k.codespace, trader, id)
+	}
+	tags, err := k.closeOrder(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTags(sdk.NewTags("action", []byte("cancel-order"), "order", []byte(id))), nil
+}
+
+// closeOrder returns what an order holds in escrow to its trader and drops
+// it.
+func (k Keeper) closeOrder(ctx sdk.Context, o *types.Order) (sdk.Tags, sdk.Error) {
+	tags := sdk.EmptyTags()
+	for _, id := range o.Vouchers {
+		if err := k.sk.ReleaseVoucher(ctx, o.Id, o.Trader, id); err != nil {
+			return nil, err
+		}
+	}
+	if !o.Escrow.IsZero() {
+		addTags, err := k.sk.AddCoins(ctx, o.Trader, o.Escrow)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(addTags)
+	}
+	k.DeleteOrder(ctx, o)
+	return tags, nil
+}
+
+// bestOrder returns the order first in line on one side of a book.
+func (k Keeper) bestOrder(ctx sdk.Context, codex string, side string, denom string) *types.Order {
+	iter := sdk.KVStorePrefixIterator(ctx.KVStore(k.key), types.OrderBookPrefix(codex, side, denom))
+	defer iter.Close()
+	if !iter.Valid() {
+		return nil
+	}
+	return k.GetOrder(ctx, string(iter.Value()))
+}
+
+// matchBook matches the bids and asks of a codex for as long as they cross,
+// one voucher at a time. A trade is made at the price of the order placed
+// first. A bid which cannot take a voucher, such as one lacking the approval
+// of the codex owner, is closed, and so is an expired voucher on sale.
+func (k Keeper) matchBook(ctx sdk.Context, codex string) sdk.Tags {
+	tags := sdk.EmptyTags()
+	for _, denom := range []string{types.DenomSilver, types.DenomGold} {
+		for {
+			bid := k.bestOrder(ctx, codex, types.OrderBid, denom)
+			ask := k.bestOrder(ctx, codex, types.OrderAsk, denom)
+			if bid == nil || ask == nil || bid.Price.Amount.LT(ask.Price.Amount) {
+				break
+			}
+			price := ask.Price
+			if bid.Seq < ask.Seq {
+				price = bid.Price
+			}
+
+			voucherId := ask.Vouchers[0]
+			cacheCtx, write := ctx.CacheContext()
+			fillTags, err := k.fill(cacheCtx, bid, ask, price)
+			if err == nil {
+				write()
+				tags = tags.AppendTags(fillTags)
+				continue
+			}
+			if _, err := k.sk.GetLiveVoucher(ctx, voucherId); err != nil {
+				tags = tags.AppendTags(k.tryClose(ctx, ask, k.dropVoucher))
+			} else {
+				tags = tags.AppendTags(k.tryClose(ctx, bid, k.closeOrder))
+			}
+		}
+	}
+	return tags
+}
+
+// fill sells the first voucher of an ask to a bid at a price no higher than
+// the bid price. The bidder keeps the difference.
+func (k Keeper) fill(ctx sdk.Context, bid *types.Order, ask *types.Order, price sdk.Coin) (sdk.Tags, sdk.Error) {
+	voucherId := ask.Vouchers[0]
+	unit := sdk.Coins{bid.Price}
+	if _, err := k.sk.AddCoins(ctx, bid.Trader, unit); err != nil {
+		return nil, err
+	}
+	tags, err := k.sk.SellVoucher(ctx, ask.Id, ask.Trader, bid.Trader, voucherId, sdk.Coins{price})
+	if err != nil {
+		return nil, err
+	}
+
+	bid.Escrow = bid.Escrow.Minus(unit)
+	bid.Quantity--
+	bid.Filled++
+	ask.Vouchers = ask.Vouchers[1:]
+	ask.Quantity--
+	ask.Filled++
+	for _, o := range []*types.Order{bid, ask} {
+		if o.Quantity > 0 {
+			k.SetOrder(ctx, o)
+		} else if _, err := k.closeOrder(ctx, o); err != nil {
+			return nil, err
+		}
+	}
+	return tags.AppendTags(sdk.NewTags("bid", []byte(bid.Id), "ask", []byte(ask.Id))), nil
+}
+
+// dropVoucher returns the first voucher of an ask to its trader.
+func (k Keeper) dropVoucher(ctx sdk.Context, ask *types.Order) (sdk.Tags, sdk.Error) {
+	if err := k.sk.ReleaseVoucher(ctx, ask.Id, ask.Trader, ask.Vouchers[0]); err != nil {
+		return nil, err
+	}
+	ask.Vouchers = ask.Vouchers[1:]
+	ask.Quantity--
+	if ask.Quantity > 0 {
+		k.SetOrder(ctx, ask)
+	} else {
+		k.DeleteOrder(ctx, ask)
+	}
+	return sdk.EmptyTags(), nil
+}
+
+// tryClose hands assets of an order back with close in a cache context. If
+// that fails, the order is dropped from the book with its assets left where
+// they are, and the failure is logged, so that one broken order does not halt
+// the chain at the end of a block.
+func (k Keeper) tryClose(ctx sdk.Context, o *types.Order, close func(sdk.Context, *types.Order) (sdk.Tags, sdk.Error)) sdk.Tags {
+	cacheCtx, write := ctx.CacheContext()
+	tags, err := close(cacheCtx, o)
+	if err == nil {
+		write()
+		return tags
+	}
+	k.DeleteOrder(ctx, o)
+	ctx.Logger().With("module", "x/market").Error(fmt.Sprintf("order %s dropped: %s", o.Id, err.Error()))
+	return sdk.NewTags("dropped-order", []byte(o.Id))
+}
+
+// EndBlocker closes the orders which expired, then matches the order books
+// which changed during the block.
+func EndBlocker(ctx sdk.Context, k Keeper) sdk.Tags {
+	store := ctx.KVStore(k.key)
+	start := []byte("order-expiry:")
+	end := types.OrderByExpiryPrefix(int(ctx.BlockHeight()))
+	iter := store.Iterator(start, end)
+	var keys [][]byte
+	var expired []string
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+		expired = append(expired, string(iter.Value()))
+	}
+	iter.Close()
+
+	tags := sdk.EmptyTags()
+	for i, id := range expired {
+		o := k.GetOrder(ctx, id)
+		if o == nil {
+			// the index outlived its order
+			store.Delete(keys[i])
+			continue
+		}
+		tags = tags.AppendTags(k.tryClose(ctx, o, k.closeOrder)).AppendTag("expired-order", []byte(id))
+	}
+
+	iter = sdk.KVStorePrefixIterator(store, []byte("book-touched:"))
+	var touched []string
+	for ; iter.Valid(); iter.Next() {
+		touched = append(touched, string(iter.Value()))
+	}
+	iter.Close()
+	for _, codex := range touched {
+		store.Delete(types.Id2StoreKey("book-touched:", codex))
+		tags = tags.AppendTags(k.matchBook(ctx, codex))
+	}
+	return tags
+}
@@ -0,0 +1,38 @@
+package market
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Market errors reserve 900 ~ 999.
+const (
+	DefaultCodespace sdk.CodespaceType = 19
+
+	CodeUnknownListing sdk.CodeType = 901
+	CodeNotSeller      sdk.CodeType = 902
+	CodeUnknownOrder   sdk.CodeType = 903
+	CodeNotTrader      sdk.CodeType = 904
+	CodeNotTradable    sdk.CodeType = 905
+)
+
+func ErrUnknownListing(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownListing, fmt.Sprintf("no listing found with the id %s", id))
+}
+
+func ErrNotSeller(codespace sdk.CodespaceType, seller string, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotSeller, fmt.Sprintf("%s is not the seller of the listing %s", seller, id))
+}
+
+func ErrUnknownOrder(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownOrder, fmt.Sprintf("no order found with the id %s", id))
+}
+
+func ErrNotTrader(codespace sdk.CodespaceType, trader string, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotTrader, fmt.Sprintf("%s did not place the order %s", trader, id))
+}
+
+func ErrNotTradable(codespace sdk.CodespaceType, codex string, reason string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotTradable, fmt.Sprintf("vouchers of the codex %s can not be traded: %s", codex, reason))
+}
@@ -0,0 +1,69 @@
+package market
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "market" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgList:
+			return handleMsgList(ctx, k, msg)
+		case *MsgBuy:
+			return handleMsgBuy(ctx, k, msg)
+		case *MsgDelist:
+			return handleMsgDelist(ctx, k, msg)
+		case *MsgPlaceOrder:
+			return handleMsgPlaceOrder(ctx, k, msg)
+		case *MsgCancelOrder:
+			return handleMsgCancelOrder(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized market Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgList(ctx sdk.Context, k Keeper, msg *MsgList) sdk.Result {
+	l, tags, err := k.List(ctx, msg.Seller, msg.Voucher, msg.Price)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(l.Id), Tags: tags}
+}
+
+func handleMsgBuy(ctx sdk.Context, k Keeper, msg *MsgBuy) sdk.Result {
+	tags, err := k.Buy(ctx, msg.Buyer, msg.Listing)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgDelist(ctx sdk.Context, k Keeper, msg *MsgDelist) sdk.Result {
+	tags, err := k.Delist(ctx, msg.Seller, msg.Listing)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgPlaceOrder(ctx sdk.Context, k Keeper, msg *MsgPlaceOrder) sdk.Result {
+	o, tags, err := k.PlaceOrder(ctx, msg.Trader, msg.Codex, msg.Side, msg.Price, msg.Quantity, msg.Vouchers, msg.ExpireAfter)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(o.Id), Tags: tags}
+}
+
+func handleMsgCancelOrder(ctx sdk.Context, k Keeper, msg *MsgCancelOrder) sdk.Result {
+	tags, err := k.CancelOrder(ctx, msg.Trader, msg.Order)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
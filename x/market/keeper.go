@@ -0,0 +1,174 @@
+package market
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+)
+
+// Keeper manages the listings of vouchers for sale. Listed vouchers are held
+// in escrow and sold through the shop keeper, which applies the transfer
+// policy and royalty of their codex.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	sk  shop.Keeper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, sk shop.Keeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, sk: sk, codespace: codespace}
+}
+
+var listingSeqKey = []byte("listing-seq")
+
+// nextListingId returns a fresh id for a new listing. Ids taken by listings
+// loaded from genesis are skipped.
+func (k Keeper) nextListingId(ctx sdk.Context) string {
+	store := ctx.KVStore(k.key)
+	var seq int64
+	bz := store.Get(listingSeqKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &seq)
+	}
+	for {
+		id := types.EscrowId("market", seq)
+		seq++
+		if !store.Has(types.ListingKey(id)) {
+			store.Set(listingSeqKey, k.cdc.MustMarshalBinaryBare(seq))
+			return id
+		}
+	}
+}
+
+func (k Keeper) GetListing(ctx sdk.Context, id string) *types.Listing {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.ListingKey(id))
+	if bz == nil {
+		return nil
+	}
+	l := &types.Listing{}
+	k.cdc.MustUnmarshalBinaryBare(bz, l)
+	return l
+}
+
+// SetListing stores a listing along with its codex and price indices.
+func (k Keeper) SetListing(ctx sdk.Context, l *types.Listing) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.ListingKey(l.Id), k.cdc.MustMarshalBinaryBare(l))
+	store.Set(append(types.ListingByCodexPrefix(l.Codex), []byte(l.Id)...), []byte(l.Id))
+	store.Set(types.ListingByPriceKey(l), []byte(l.Id))
+}
+
+func (k Keeper) DeleteListing(ctx sdk.Context, l *types.Listing) {
+	store := ctx.KVStore(k.key)
+	store.Delete(types.ListingKey(l.Id))
+	store.Delete(append(types.ListingByCodexPrefix(l.Codex), []byte(l.Id)...))
+	store.Delete(types.ListingByPriceKey(l))
+}
+
+func (k Keeper) IterateListings(ctx sdk.Context, process func(*types.Listing) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("listing:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		l := &types.Listing{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), l)
+		if process(l) {
+			return
+		}
+	}
+}
+
+// List puts a voucher up for sale and takes it into escrow. Vouchers of a
+// non-transferable codex cannot be listed; whether the buyer may receive the
+// voucher is checked when it is bought.
+func (k Keeper) List(ctx sdk.Context, seller string, voucherId string, price sdk.Coin) (*types.Listing, sdk.Tags, sdk.Error) {
+	if err := k.sk.CheckDenom(ctx, price.Denom); err != nil {
+		return nil, nil, err
+	}
+	vou, err := k.sk.GetLiveVoucher(ctx, voucherId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cod := k.sk.CodexMapper().GetCodex(ctx, vou.Origin); cod != nil {
+		if policy := cod.TransferPolicy(); policy == types.TransferNone {
+			return nil, nil, shop.ErrNotTransferable(k.sk.Codespace(), voucherId, policy)
+		}
+	}
+
+	l := &types.Listing{
+		Id:       k.nextListingId(ctx),
+		Voucher:  voucherId,
+		Codex:    vou.Origin,
+		Seller:   seller,
+		Price:    price,
+		ListedOn: int(ctx.BlockHeight()),
+	}
+	if err := k.sk.FreezeVoucher(ctx, seller, l.Id, voucherId); err != nil {
+		return nil, nil, err
+	}
+	k.SetListing(ctx, l)
+	return l, sdk.NewTags("action", []byte("list"), "voucher", []byte(voucherId), "listing", []byte(l.Id)), nil
+}
+
+// Buy pays the price of a listing and takes the listed voucher.
+func (k Keeper) Buy(ctx sdk.Context, buyer string, id string) (sdk.Tags, sdk.Error) {
+	l := k.GetListing(ctx, id)
+	if l == nil {
+		return nil, ErrUnknownListing(k.codespace, id)
+	}
+	tags, err := k.sk.SellVoucher(ctx, l.Id, l.Seller, buyer, l.Voucher, sdk.Coins{l.Price})
+	if err != nil {
+		return nil, err
+	}
+	k.DeleteListing(ctx, l)
+	return tags.AppendTag("listing", []byte(id)), nil
+}
+
+// Delist takes a listing down and returns the voucher to its seller.
+func (k Keeper) Delist(ctx sdk.Context, seller string, id string) (sdk.Tags, sdk.Error) {
+	l := k.GetListing(ctx, id)
+	if l == nil {
+		return nil, ErrUnknownListing(k.codespace, id)
+	}
+	if l.Seller != seller {
+		return nil, ErrNotSeller(k.codespace, seller, id)
+	}
+	if err := k.sk.ReleaseVoucher(ctx, l.Id, l.Seller, l.Voucher); err != nil {
+		return nil, err
+	}
+	k.DeleteListing(ctx, l)
+	return sdk.NewTags("action", []byte("delist"), "voucher", []byte(l.Voucher), "listing", []byte(id)), nil
+}
+
+// InitGenesis loads the open listings and orders of the genesis state. The
+// books with orders are matched at the end of the first block.
+func InitGenesis(ctx sdk.Context, k Keeper, listings []*types.Listing, orders []*types.Order) {
+	for _, l := range listings {
+		k.SetListing(ctx, l)
+	}
+	for _, o := range orders {
+		k.SetOrder(ctx, o)
+		k.touchBook(ctx, o.Codex)
+	}
+}
+
+// WriteGenesis returns the open listings and orders for the genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) ([]*types.Listing, []*types.Order) {
+	listings := []*types.Listing{}
+	k.IterateListings(ctx, func(l *types.Listing) bool {
+		listings = append(listings, l)
+		return false
+	})
+	orders := []*types.Order{}
+	k.IterateOrders(ctx, func(o *types.Order) bool {
+		orders = append(orders, o)
+		return false
+	})
+	return listings, orders
+}
@@ -0,0 +1,217 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgList is sent by the holder of a voucher to put it up for sale.
+type MsgList struct {
+	SellerAccount sdk.AccAddress `json:"seller-account"`
+	Seller        string         `json:"seller"`
+	Voucher       string         `json:"voucher"`
+	Price         sdk.Coin       `json:"price"`
+}
+
+var _ sdk.Msg = MsgList{}
+
+// Implements sdk.Msg
+func (msg MsgList) Type() string { return "market" }
+
+// Implements sdk.Msg
+func (msg MsgList) ValidateBasic() sdk.Error {
+	if msg.Seller != types.UserId(msg.SellerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Seller))
+	}
+	if msg.Price.Denom != types.DenomSilver && msg.Price.Denom != types.DenomGold {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("vouchers are sold for %s or %s", types.DenomSilver, types.DenomGold))
+	}
+	if !msg.Price.IsPositive() || !msg.Price.Amount.IsInt64() {
+		return sdk.ErrInvalidCoins(msg.Price.String())
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgList) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgList) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.SellerAccount}
+}
+
+// MsgBuy is sent by anyone to buy a listed voucher at its price.
+type MsgBuy struct {
+	BuyerAccount sdk.AccAddress `json:"buyer-account"`
+	Buyer        string         `json:"buyer"`
+	Listing      string         `json:"listing"`
+}
+
+var _ sdk.Msg = MsgBuy{}
+
+// Implements sdk.Msg
+func (msg MsgBuy) Type() string { return "market" }
+
+// Implements sdk.Msg
+func (msg MsgBuy) ValidateBasic() sdk.Error {
+	if msg.Buyer != types.UserId(msg.BuyerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Buyer))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgBuy) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgBuy) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.BuyerAccount}
+}
+
+// MsgDelist is sent by the seller of a listed voucher to take it back.
+type MsgDelist struct {
+	SellerAccount sdk.AccAddress `json:"seller-account"`
+	Seller        string         `json:"seller"`
+	Listing       string         `json:"listing"`
+}
+
+var _ sdk.Msg = MsgDelist{}
+
+// Implements sdk.Msg
+func (msg MsgDelist) Type() string { return "market" }
+
+// Implements sdk.Msg
+func (msg MsgDelist) ValidateBasic() sdk.Error {
+	if msg.Seller != types.UserId(msg.SellerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Seller))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgDelist) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgDelist) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.SellerAccount}
+}
+
+// MsgPlaceOrder puts a bid or an ask for vouchers of a codex in its order
+// book. An ask sells the listed vouchers, while a bid buys Quantity of them.
+type MsgPlaceOrder struct {
+	TraderAccount sdk.AccAddress `json:"trader-account"`
+	Trader        string         `json:"trader"`
+	Codex         string         `json:"codex"`
+	Side          string         `json:"side"`
+	Price         sdk.Coin       `json:"price"` // per voucher
+	Quantity      int            `json:"quantity"`
+	Vouchers      []string       `json:"vouchers"`
+
+	// ExpireAfter is the number of blocks the order stays in the book. Zero
+	// means OrderPeriod.
+	ExpireAfter int `json:"expire-after"`
+}
+
+var _ sdk.Msg = MsgPlaceOrder{}
+
+// Implements sdk.Msg
+func (msg MsgPlaceOrder) Type() string { return "market" }
+
+// Implements sdk.Msg
+func (msg MsgPlaceOrder) ValidateBasic() sdk.Error {
+	if msg.Trader != types.UserId(msg.TraderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Trader))
+	}
+	if msg.Price.Denom != types.DenomSilver && msg.Price.Denom != types.DenomGold {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("vouchers are sold for %s or %s", types.DenomSilver, types.DenomGold))
+	}
+	if !msg.Price.IsPositive() || !msg.Price.Amount.IsInt64() {
+		return sdk.ErrInvalidCoins(msg.Price.String())
+	}
+	switch msg.Side {
+	case types.OrderAsk:
+		if len(msg.Vouchers) == 0 {
+			return sdk.ErrUnknownRequest("an ask needs vouchers to sell")
+		}
+	case types.OrderBid:
+		if msg.Quantity <= 0 || len(msg.Vouchers) > 0 {
+			return sdk.ErrUnknownRequest("a bid needs a positive quantity and no vouchers")
+		}
+	default:
+		return sdk.ErrUnknownRequest(fmt.Sprintf("unknown side %s", msg.Side))
+	}
+	if msg.ExpireAfter < 0 {
+		return sdk.ErrUnknownRequest("negative expire-after")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgPlaceOrder) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgPlaceOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.TraderAccount}
+}
+
+// MsgCancelOrder takes an order out of the book.
+type MsgCancelOrder struct {
+	TraderAccount sdk.AccAddress `json:"trader-account"`
+	Trader        string         `json:"trader"`
+	Order         string         `json:"order"`
+}
+
+var _ sdk.Msg = MsgCancelOrder{}
+
+// Implements sdk.Msg
+func (msg MsgCancelOrder) Type() string { return "market" }
+
+// Implements sdk.Msg
+func (msg MsgCancelOrder) ValidateBasic() sdk.Error {
+	if msg.Trader != types.UserId(msg.TraderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Trader))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgCancelOrder) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgCancelOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.TraderAccount}
+}
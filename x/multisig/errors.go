@@ -0,0 +1,48 @@
+package multisig
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Multisig errors reserve 800 ~ 899.
+const (
+	DefaultCodespace sdk.CodespaceType = 18
+
+	CodeUnknownMultisig sdk.CodeType = 801
+	CodeNotMember       sdk.CodeType = 802
+	CodeUnknownProposal sdk.CodeType = 803
+	CodeProposalExpired sdk.CodeType = 804
+	CodeAlreadyApproved sdk.CodeType = 805
+	CodeInvalidProposal sdk.CodeType = 806
+	CodeExecution       sdk.CodeType = 807
+)
+
+func ErrUnknownMultisig(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownMultisig, fmt.Sprintf("no multisig found with the id %s", id))
+}
+
+func ErrNotMember(codespace sdk.CodespaceType, user string, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotMember, fmt.Sprintf("%s is not a member of the multisig %s", user, id))
+}
+
+func ErrUnknownProposal(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownProposal, fmt.Sprintf("no proposal found with the id %s", id))
+}
+
+func ErrProposalExpired(codespace sdk.CodespaceType, id string, expireOn int) sdk.Error {
+	return sdk.NewError(codespace, CodeProposalExpired, fmt.Sprintf("the proposal %s expired on %d", id, expireOn))
+}
+
+func ErrAlreadyApproved(codespace sdk.CodespaceType, user string, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeAlreadyApproved, fmt.Sprintf("%s already approved the proposal %s", user, id))
+}
+
+func ErrInvalidProposal(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidProposal, msg)
+}
+
+func ErrExecution(codespace sdk.CodespaceType, id string, log string) sdk.Error {
+	return sdk.NewError(codespace, CodeExecution, fmt.Sprintf("the proposal %s failed: %s", id, log))
+}
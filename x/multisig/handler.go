@@ -0,0 +1,46 @@
+package multisig
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "multisig" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgCreateMultisig:
+			return handleMsgCreateMultisig(ctx, k, msg)
+		case *MsgPropose:
+			return handleMsgPropose(ctx, k, msg)
+		case *MsgApproveProposal:
+			return handleMsgApproveProposal(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized multisig Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgCreateMultisig(ctx sdk.Context, k Keeper, msg *MsgCreateMultisig) sdk.Result {
+	ms, tags := k.CreateMultisig(ctx, msg.Members, msg.Threshold)
+	return sdk.Result{Data: []byte(ms.Id), Tags: tags}
+}
+
+func handleMsgPropose(ctx sdk.Context, k Keeper, msg *MsgPropose) sdk.Result {
+	p, tags, err := k.Propose(ctx, msg.Proposer, msg.Multisig, msg.Msg, msg.ExpireAfter)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(p.Id), Tags: tags}
+}
+
+func handleMsgApproveProposal(ctx sdk.Context, k Keeper, msg *MsgApproveProposal) sdk.Result {
+	tags, err := k.Approve(ctx, msg.Approver, msg.Proposal)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
@@ -0,0 +1,285 @@
+package multisig
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// ApprovalPeriod is the number of blocks a proposal stays open for approvals
+// unless its proposer asks for another period.
+const ApprovalPeriod = 1000
+
+// Keeper manages multisigs and the proposals sent on their behalf. Approved
+// proposals are run through the message router of the app, with the multisig
+// as the owner.
+type Keeper struct {
+	key    sdk.StoreKey
+	cdc    *wire.Codec
+	router baseapp.Router
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, router baseapp.Router, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, router: router, codespace: codespace}
+}
+
+var (
+	multisigSeqKey = []byte("multisig-seq")
+	proposalSeqKey = []byte("proposal-seq")
+)
+
+// nextSeq returns the next value of a sequence for which id is not taken yet.
+// Ids taken by records loaded from genesis are skipped.
+func (k Keeper) nextSeq(ctx sdk.Context, seqKey []byte, idOf func(int64) string, keyOf func(string) []byte) string {
+	store := ctx.KVStore(k.key)
+	var seq int64
+	bz := store.Get(seqKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &seq)
+	}
+	for {
+		id := idOf(seq)
+		seq++
+		if !store.Has(keyOf(id)) {
+			store.Set(seqKey, k.cdc.MustMarshalBinaryBare(seq))
+			return id
+		}
+	}
+}
+
+func (k Keeper) GetMultisig(ctx sdk.Context, id string) *types.Multisig {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.MultisigKey(id))
+	if bz == nil {
+		return nil
+	}
+	ms := &types.Multisig{}
+	k.cdc.MustUnmarshalBinaryBare(bz, ms)
+	return ms
+}
+
+func (k Keeper) SetMultisig(ctx sdk.Context, ms *types.Multisig) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.MultisigKey(ms.Id), k.cdc.MustMarshalBinaryBare(ms))
+}
+
+func (k Keeper) IterateMultisigs(ctx sdk.Context, process func(*types.Multisig) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("multisig:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		ms := &types.Multisig{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), ms)
+		if process(ms) {
+			return
+		}
+	}
+}
+
+func (k Keeper) GetProposal(ctx sdk.Context, id string) *types.Proposal {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.ProposalKey(id))
+	if bz == nil {
+		return nil
+	}
+	p := &types.Proposal{}
+	k.cdc.MustUnmarshalBinaryBare(bz, p)
+	return p
+}
+
+// SetProposal stores a proposal along with its multisig and expiry indices.
+func (k Keeper) SetProposal(ctx sdk.Context, p *types.Proposal) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.ProposalKey(p.Id), k.cdc.MustMarshalBinaryBare(p))
+	store.Set(append(types.ProposalByMultisigPrefix(p.Multisig), []byte(p.Id)...), []byte(p.Id))
+	store.Set(append(types.ProposalByExpiryPrefix(p.ExpireOn), []byte(p.Id)...), []byte(p.Id))
+}
+
+func (k Keeper) DeleteProposal(ctx sdk.Context, p *types.Proposal) {
+	store := ctx.KVStore(k.key)
+	store.Delete(types.ProposalKey(p.Id))
+	store.Delete(append(types.ProposalByMultisigPrefix(p.Multisig), []byte(p.Id)...))
+	store.Delete(append(types.ProposalByExpiryPrefix(p.ExpireOn), []byte(p.Id)...))
+}
+
+func (k Keeper) IterateProposals(ctx sdk.Context, process func(*types.Proposal) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("proposal:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		p := &types.Proposal{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), p)
+		if process(p) {
+			return
+		}
+	}
+}
+
+// CreateMultisig registers a new multisig. Members and threshold are checked
+// by MsgCreateMultisig.
+func (k Keeper) CreateMultisig(ctx sdk.Context, members []string, threshold int) (*types.Multisig, sdk.Tags) {
+	ms := &types.Multisig{
+		Id:        k.nextSeq(ctx, multisigSeqKey, types.MultisigId, types.MultisigKey),
+		Members:   members,
+		Threshold: threshold,
+	}
+	k.SetMultisig(ctx, ms)
+	return ms, sdk.NewTags("action", []byte("create-multisig"), "multisig", []byte(ms.Id))
+}
+
+// Propose opens a proposal to send a message on behalf of a multisig, with the
+// approval of its proposer. The message is run right away if that is enough.
+func (k Keeper) Propose(ctx sdk.Context, proposer string, multisigId string, msg sdk.Msg, expireAfter int) (*types.Proposal, sdk.Tags, sdk.Error) {
+	ms := k.GetMultisig(ctx, multisigId)
+	if ms == nil {
+		return nil, nil, ErrUnknownMultisig(k.codespace, multisigId)
+	}
+	if !ms.IsMember(proposer) {
+		return nil, nil, ErrNotMember(k.codespace, proposer, multisigId)
+	}
+	if err := k.validateProposed(msg, multisigId); err != nil {
+		return nil, nil, err
+	}
+	if expireAfter <= 0 {
+		expireAfter = ApprovalPeriod
+	}
+
+	p := &types.Proposal{
+		Id:        k.nextSeq(ctx, proposalSeqKey, proposalId, types.ProposalKey),
+		Multisig:  multisigId,
+		Msg:       msg,
+		Approvals: []string{proposer},
+		ExpireOn:  int(ctx.BlockHeight()) + expireAfter,
+	}
+	tags := sdk.NewTags("action", []byte("propose"), "multisig", []byte(multisigId), "proposal", []byte(p.Id))
+	execTags, err := k.approved(ctx, ms, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, tags.AppendTags(execTags), nil
+}
+
+func proposalId(seq int64) string {
+	return types.EscrowId("proposal", seq)
+}
+
+// validateProposed checks a message proposed on behalf of a multisig. Only
+// owner messages naming the multisig as their owner can be proposed. The
+// signer check of the message does not apply, as the multisig signs through
+// the approvals of its members, but its fields are checked all the same.
+func (k Keeper) validateProposed(msg sdk.Msg, multisigId string) sdk.Error {
+	om, ok := msg.(types.OwnerMsg)
+	if !ok || msg.Type() == "multisig" {
+		return ErrInvalidProposal(k.codespace, fmt.Sprintf("%T can not be proposed", msg))
+	}
+	if om.GetOwner() != multisigId {
+		return ErrInvalidProposal(k.codespace, fmt.Sprintf("the proposed message is not sent by %s", multisigId))
+	}
+	return om.ValidateFields()
+}
+
+// Approve adds the approval of a member to an open proposal, and runs its
+// message once the threshold of the multisig is reached.
+func (k Keeper) Approve(ctx sdk.Context, approver string, id string) (sdk.Tags, sdk.Error) {
+	p := k.GetProposal(ctx, id)
+	if p == nil {
+		return nil, ErrUnknownProposal(k.codespace, id)
+	}
+	if ctx.BlockHeight() > int64(p.ExpireOn) {
+		return nil, ErrProposalExpired(k.codespace, id, p.ExpireOn)
+	}
+	ms := k.GetMultisig(ctx, p.Multisig)
+	if ms == nil {
+		return nil, ErrUnknownMultisig(k.codespace, p.Multisig)
+	}
+	if !ms.IsMember(approver) {
+		return nil, ErrNotMember(k.codespace, approver, ms.Id)
+	}
+	for _, user := range p.Approvals {
+		if user == approver {
+			return nil, ErrAlreadyApproved(k.codespace, approver, id)
+		}
+	}
+
+	p.Approvals = append(p.Approvals, approver)
+	tags := sdk.NewTags("action", []byte("approve-proposal"), "proposal", []byte(id))
+	execTags, err := k.approved(ctx, ms, p)
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTags(execTags), nil
+}
+
+// approved stores a proposal short of approvals, or runs its message and
+// drops it once the threshold is reached. A failing message fails the
+// approval that would have run it.
+func (k Keeper) approved(ctx sdk.Context, ms *types.Multisig, p *types.Proposal) (sdk.Tags, sdk.Error) {
+	if len(p.Approvals) < ms.Threshold {
+		k.SetProposal(ctx, p)
+		return sdk.EmptyTags(), nil
+	}
+	k.DeleteProposal(ctx, p)
+
+	handler := k.router.Route(p.Msg.Type())
+	if handler == nil {
+		return nil, ErrInvalidProposal(k.codespace, fmt.Sprintf("no route for %s", p.Msg.Type()))
+	}
+	res := handler(ctx, p.Msg)
+	if !res.IsOK() {
+		return nil, ErrExecution(k.codespace, p.Id, res.Log)
+	}
+	return res.Tags.AppendTag("executed", []byte(p.Id)), nil
+}
+
+// InitGenesis loads the multisigs and open proposals of the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, multisigs []*types.Multisig, proposals []*types.Proposal) {
+	for _, ms := range multisigs {
+		k.SetMultisig(ctx, ms)
+	}
+	for _, p := range proposals {
+		k.SetProposal(ctx, p)
+	}
+}
+
+// WriteGenesis returns the multisigs and open proposals for the genesis
+// state.
+func WriteGenesis(ctx sdk.Context, k Keeper) ([]*types.Multisig, []*types.Proposal) {
+	multisigs := []*types.Multisig{}
+	k.IterateMultisigs(ctx, func(ms *types.Multisig) bool {
+		multisigs = append(multisigs, ms)
+		return false
+	})
+	proposals := []*types.Proposal{}
+	k.IterateProposals(ctx, func(p *types.Proposal) bool {
+		proposals = append(proposals, p)
+		return false
+	})
+	return multisigs, proposals
+}
+
+// EndBlocker drops the proposals whose approvals expired.
+func EndBlocker(ctx sdk.Context, k Keeper) sdk.Tags {
+	store := ctx.KVStore(k.key)
+	start := []byte("proposal-expiry:")
+	end := types.ProposalByExpiryPrefix(int(ctx.BlockHeight()))
+	iter := store.Iterator(start, end)
+	var expired []string
+	for ; iter.Valid(); iter.Next() {
+		expired = append(expired, string(iter.Value()))
+	}
+	iter.Close()
+
+	tags := sdk.EmptyTags()
+	for _, id := range expired {
+		k.DeleteProposal(ctx, k.GetProposal(ctx, id))
+		tags = tags.AppendTag("expired-proposal", []byte(id))
+	}
+	return tags
+}
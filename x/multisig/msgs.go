@@ -0,0 +1,143 @@
+package multisig
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgCreateMultisig registers a multisig of users, Threshold of whom must
+// approve what is sent on its behalf. The creator need not be a member.
+type MsgCreateMultisig struct {
+	CreatorAccount sdk.AccAddress `json:"creator-account"`
+	Creator        string         `json:"creator"`
+	Members        []string       `json:"members"`
+	Threshold      int            `json:"threshold"`
+}
+
+var _ sdk.Msg = MsgCreateMultisig{}
+
+// Implements sdk.Msg
+func (msg MsgCreateMultisig) Type() string { return "multisig" }
+
+// Implements sdk.Msg
+func (msg MsgCreateMultisig) ValidateBasic() sdk.Error {
+	if msg.Creator != types.UserId(msg.CreatorAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Creator))
+	}
+	seen := map[string]bool{}
+	for _, member := range msg.Members {
+		if types.IdKind(member) != types.KindUser {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("%s is not a user id", member))
+		}
+		if seen[member] {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("%s is listed twice", member))
+		}
+		seen[member] = true
+	}
+	if msg.Threshold <= 0 || msg.Threshold > len(msg.Members) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("threshold %d out of %d members", msg.Threshold, len(msg.Members)))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgCreateMultisig) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgCreateMultisig) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.CreatorAccount}
+}
+
+// MsgPropose is sent by a member of a multisig to propose an owner message on
+// behalf of the multisig. The proposal counts as approved by its proposer.
+type MsgPropose struct {
+	ProposerAccount sdk.AccAddress `json:"proposer-account"`
+	Proposer        string         `json:"proposer"`
+	Multisig        string         `json:"multisig"`
+	Msg             sdk.Msg        `json:"msg"`
+
+	// ExpireAfter is the number of blocks the proposal stays open for
+	// approvals. Zero means ApprovalPeriod.
+	ExpireAfter int `json:"expire-after"`
+}
+
+var _ sdk.Msg = MsgPropose{}
+
+// Implements sdk.Msg
+func (msg MsgPropose) Type() string { return "multisig" }
+
+// Implements sdk.Msg
+func (msg MsgPropose) ValidateBasic() sdk.Error {
+	if msg.Proposer != types.UserId(msg.ProposerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Proposer))
+	}
+	if types.IdKind(msg.Multisig) != types.KindMultisig {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("%s is not a multisig id", msg.Multisig))
+	}
+	if msg.Msg == nil {
+		return sdk.ErrUnknownRequest("nothing proposed")
+	}
+	if msg.ExpireAfter < 0 {
+		return sdk.ErrUnknownRequest("negative expire-after")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgPropose) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgPropose) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ProposerAccount}
+}
+
+// MsgApproveProposal is sent by a member of a multisig to approve one of its
+// open proposals.
+type MsgApproveProposal struct {
+	ApproverAccount sdk.AccAddress `json:"approver-account"`
+	Approver        string         `json:"approver"`
+	Proposal        string         `json:"proposal"`
+}
+
+var _ sdk.Msg = MsgApproveProposal{}
+
+// Implements sdk.Msg
+func (msg MsgApproveProposal) Type() string { return "multisig" }
+
+// Implements sdk.Msg
+func (msg MsgApproveProposal) ValidateBasic() sdk.Error {
+	if msg.Approver != types.UserId(msg.ApproverAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Approver))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgApproveProposal) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgApproveProposal) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.ApproverAccount}
+}
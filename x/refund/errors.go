@@ -0,0 +1,28 @@
+package refund
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Refund errors reserve 500 ~ 599.
+const (
+	DefaultCodespace sdk.CodespaceType = 15
+
+	CodeNoRefund       sdk.CodeType = 501
+	CodeWindowClosed   sdk.CodeType = 502
+	CodeUnknownRequest sdk.CodeType = 503
+)
+
+func ErrNoRefund(codespace sdk.CodespaceType, codex string) sdk.Error {
+	return sdk.NewError(codespace, CodeNoRefund, fmt.Sprintf("the codex %s offers no refunds", codex))
+}
+
+func ErrWindowClosed(codespace sdk.CodespaceType, id string, closedAt int) sdk.Error {
+	return sdk.NewError(codespace, CodeWindowClosed, fmt.Sprintf("the refund window of %s closed at %d", id, closedAt))
+}
+
+func ErrUnknownRequest(codespace sdk.CodespaceType, voucher string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownRequest, fmt.Sprintf("no refund requested for the voucher %s", voucher))
+}
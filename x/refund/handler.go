@@ -0,0 +1,49 @@
+package refund
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "refund" type messages.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case *MsgRequestRefund:
+			return handleMsgRequestRefund(ctx, k, msg)
+		case *MsgApproveRefund:
+			return handleMsgApproveRefund(ctx, k, msg)
+		case *MsgRejectRefund:
+			return handleMsgRejectRefund(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized refund Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgRequestRefund(ctx sdk.Context, k Keeper, msg *MsgRequestRefund) sdk.Result {
+	r, tags, err := k.RequestRefund(ctx, msg.Holder, msg.Voucher)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: []byte(r.Id), Tags: tags}
+}
+
+func handleMsgApproveRefund(ctx sdk.Context, k Keeper, msg *MsgApproveRefund) sdk.Result {
+	tags, err := k.ApproveRefund(ctx, msg.Owner, msg.Voucher)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgRejectRefund(ctx sdk.Context, k Keeper, msg *MsgRejectRefund) sdk.Result {
+	tags, err := k.RejectRefund(ctx, msg.Owner, msg.Voucher)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
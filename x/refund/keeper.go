@@ -0,0 +1,279 @@
+package refund
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/shop"
+)
+
+// ResponsePeriod is the number of blocks a codex owner has to answer a refund
+// request before it is approved by default.
+const ResponsePeriod = 1000
+
+// Keeper manages refund requests. Vouchers under request are frozen and
+// settled through the shop keeper.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	sk  shop.Keeper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, sk shop.Keeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{key: key, cdc: cdc, sk: sk, codespace: codespace}
+}
+
+var refundSeqKey = []byte("refund-seq")
+
+// nextRequestId returns a fresh id for a new refund request. Ids taken by
+// requests loaded from genesis are skipped.
+func (k Keeper) nextRequestId(ctx sdk.Context) string {
+	store := ctx.KVStore(k.key)
+	var seq int64
+	bz := store.Get(refundSeqKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &seq)
+	}
+	for {
+		id := types.EscrowId("refund", seq)
+		seq++
+		if !store.Has(types.RefundKey(id)) {
+			store.Set(refundSeqKey, k.cdc.MustMarshalBinaryBare(seq))
+			return id
+		}
+	}
+}
+
+func (k Keeper) GetRequest(ctx sdk.Context, id string) *types.RefundRequest {
+	store := ctx.KVStore(k.key)
+	bz := store.Get(types.RefundKey(id))
+	if bz == nil {
+		return nil
+	}
+	r := &types.RefundRequest{}
+	k.cdc.MustUnmarshalBinaryBare(bz, r)
+	return r
+}
+
+// SetRequest stores a refund request along with its deadline index.
+func (k Keeper) SetRequest(ctx sdk.Context, r *types.RefundRequest) {
+	store := ctx.KVStore(k.key)
+	store.Set(types.RefundKey(r.Id), k.cdc.MustMarshalBinaryBare(r))
+	store.Set(append(types.RefundByDeadlinePrefix(r.Deadline), []byte(r.Id)...), []byte(r.Id))
+}
+
+func (k Keeper) DeleteRequest(ctx sdk.Context, r *types.RefundRequest) {
+	store := ctx.KVStore(k.key)
+	store.Delete(types.RefundKey(r.Id))
+	store.Delete(append(types.RefundByDeadlinePrefix(r.Deadline), []byte(r.Id)...))
+}
+
+func (k Keeper) IterateRequests(ctx sdk.Context, process func(*types.RefundRequest) (stop bool)) {
+	store := ctx.KVStore(k.key)
+	iter := sdk.KVStorePrefixIterator(store, []byte("refund:"))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		r := &types.RefundRequest{}
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), r)
+		if process(r) {
+			return
+		}
+	}
+}
+
+// Window returns the refund window of a codex within the bounds set by the
+// refund params.
+func (k Keeper) Window(ctx sdk.Context, cod *types.Codex) int {
+	bounds := k.sk.ParamsMapper().GetParams(ctx).Refund
+	window := cod.RefundWindow
+	if window < bounds.MinWindow {
+		window = bounds.MinWindow
+	}
+	if bounds.MaxWindow > 0 && window > bounds.MaxWindow {
+		window = bounds.MaxWindow
+	}
+	return window
+}
+
+// RequestRefund freezes a voucher and opens a refund request for it, provided
+// the refund window of its codex is still open.
+func (k Keeper) RequestRefund(ctx sdk.Context, holder string, voucherId string) (*types.RefundRequest, sdk.Tags, sdk.Error) {
+	vou, err := k.sk.GetLiveVoucher(ctx, voucherId)
+	if err != nil {
+		return nil, nil, err
+	}
+	cod := k.sk.CodexMapper().GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, nil, shop.ErrUnknownCodex(k.sk.Codespace(), vou.Origin)
+	}
+	window := k.Window(ctx, cod)
+	if window <= 0 {
+		return nil, nil, ErrNoRefund(k.codespace, cod.Id)
+	}
+	if closedAt := vou.IssuedOn + window; ctx.BlockHeight() > int64(closedAt) {
+		return nil, nil, ErrWindowClosed(k.codespace, voucherId, closedAt)
+	}
+
+	r := &types.RefundRequest{
+		Id:       k.nextRequestId(ctx),
+		Voucher:  voucherId,
+		Holder:   holder,
+		Deadline: int(ctx.BlockHeight()) + ResponsePeriod,
+	}
+	if err := k.sk.FreezeVoucher(ctx, holder, r.Id, voucherId); err != nil {
+		return nil, nil, err
+	}
+	k.SetRequest(ctx, r)
+	return r, sdk.NewTags("action", []byte("request-refund"), "voucher", []byte(voucherId), "refund", []byte(r.Id)), nil
+}
+
+// getRequestOf returns the open refund request for a voucher. A voucher under
+// request is held by the request's escrow id.
+func (k Keeper) getRequestOf(ctx sdk.Context, voucherId string) (*types.RefundRequest, *types.Codex, sdk.Error) {
+	vou := k.sk.VoucherMapper().GetVoucher(ctx, voucherId)
+	if vou == nil {
+		return nil, nil, shop.ErrUnknownVoucher(k.sk.Codespace(), voucherId)
+	}
+	r := k.GetRequest(ctx, vou.Holder)
+	if r == nil || r.Voucher != voucherId {
+		return nil, nil, ErrUnknownRequest(k.codespace, voucherId)
+	}
+	cod := k.sk.CodexMapper().GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, nil, shop.ErrUnknownCodex(k.sk.Codespace(), vou.Origin)
+	}
+	return r, cod, nil
+}
+
+// ApproveRefund settles the refund request for a voucher on behalf of the
+// codex owner.
+func (k Keeper) ApproveRefund(ctx sdk.Context, owner string, voucherId string) (sdk.Tags, sdk.Error) {
+	r, cod, err := k.getRequestOf(ctx, voucherId)
+	if err != nil {
+		return nil, err
+	}
+	if cod.Owner != owner {
+		return nil, shop.ErrNotCodexOwner(k.sk.Codespace(), owner, cod.Id)
+	}
+	k.sk.TouchCodex(ctx, cod.Id)
+	return k.refund(ctx, r, cod)
+}
+
+// RejectRefund returns the voucher under request to its holder.
+func (k Keeper) RejectRefund(ctx sdk.Context, owner string, voucherId string) (sdk.Tags, sdk.Error) {
+	r, cod, err := k.getRequestOf(ctx, voucherId)
+	if err != nil {
+		return nil, err
+	}
+	if cod.Owner != owner {
+		return nil, shop.ErrNotCodexOwner(k.sk.Codespace(), owner, cod.Id)
+	}
+	k.sk.TouchCodex(ctx, cod.Id)
+	if err := k.sk.ReleaseVoucher(ctx, r.Id, r.Holder, r.Voucher); err != nil {
+		return nil, err
+	}
+	k.DeleteRequest(ctx, r)
+	return sdk.NewTags("action", []byte("reject-refund"), "voucher", []byte(voucherId)), nil
+}
+
+// refund pays the holder back and burns the voucher. An ordinary voucher is
+// refunded its unit price out of the codex funds, while a stored-value
+// voucher gives back its remaining balance and a voucher with escrowed
+// proceeds gives back those, which is where their price went.
+func (k Keeper) refund(ctx sdk.Context, r *types.RefundRequest, cod *types.Codex) (sdk.Tags, sdk.Error) {
+	vou := k.sk.VoucherMapper().GetVoucher(ctx, r.Voucher)
+	tags := sdk.EmptyTags()
+	if cod.StoredValue {
+		balanceTags, err := k.sk.ReleaseBalance(ctx, vou, r.Holder, vou.Balance)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(balanceTags)
+	} else if vou.Proceeds > 0 {
+		releaseTags, err := k.sk.ReleaseProceeds(ctx, vou, r.Holder)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(releaseTags)
+	} else if cod.UnitPrice > 0 {
+		price := sdk.Coins{sdk.NewInt64Coin(types.DenomSilver, int64(cod.UnitPrice))}
+		sendTags, err := k.sk.SendCoins(ctx, cod.Id, r.Holder, price)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(sendTags)
+	}
+	if err := k.sk.BurnVoucher(ctx, r.Id, r.Voucher); err != nil {
+		return nil, err
+	}
+	k.DeleteRequest(ctx, r)
+	return tags.AppendTags(sdk.NewTags("action", []byte("refund"), "voucher", []byte(r.Voucher))), nil
+}
+
+// InitGenesis loads the open refund requests of the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper, requests []*types.RefundRequest) {
+	for _, r := range requests {
+		k.SetRequest(ctx, r)
+	}
+}
+
+// WriteGenesis returns the open refund requests for the genesis state.
+func WriteGenesis(ctx sdk.Context, k Keeper) []*types.RefundRequest {
+	requests := []*types.RefundRequest{}
+	k.IterateRequests(ctx, func(r *types.RefundRequest) bool {
+		requests = append(requests, r)
+		return false
+	})
+	return requests
+}
+
+// EndBlocker approves the refund requests left unanswered past their
+// deadline. A request the codex cannot pay for is dropped and the voucher
+// returned to its holder; should even that fail, the failure is logged
+// rather than halting the chain.
+func EndBlocker(ctx sdk.Context, k Keeper) sdk.Tags {
+	store := ctx.KVStore(k.key)
+	start := []byte("refund-deadline:")
+	end := types.RefundByDeadlinePrefix(int(ctx.BlockHeight()))
+	iter := store.Iterator(start, end)
+	var keys [][]byte
+	var due []string
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+		due = append(due, string(iter.Value()))
+	}
+	iter.Close()
+
+	tags := sdk.EmptyTags()
+	for i, id := range due {
+		r := k.GetRequest(ctx, id)
+		if r == nil {
+			// the index outlived its request
+			store.Delete(keys[i])
+			continue
+		}
+		_, cod, err := k.getRequestOf(ctx, r.Voucher)
+		if err == nil {
+			cacheCtx, write := ctx.CacheContext()
+			var refundTags sdk.Tags
+			refundTags, err = k.refund(cacheCtx, r, cod)
+			if err == nil {
+				write()
+				tags = tags.AppendTags(refundTags)
+				continue
+			}
+		}
+		if err := k.sk.ReleaseVoucher(ctx, r.Id, r.Holder, r.Voucher); err != nil {
+			ctx.Logger().With("module", "x/refund").Error(fmt.Sprintf("voucher %s of %s not returned: %s", r.Voucher, r.Id, err.Error()))
+		}
+		k.DeleteRequest(ctx, r)
+		tags = tags.AppendTags(sdk.NewTags("action", []byte("refund-failed"), "voucher", []byte(r.Voucher)))
+	}
+	return tags
+}
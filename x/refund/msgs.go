@@ -0,0 +1,131 @@
+package refund
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// MsgRequestRefund is sent by the holder of a voucher to ask for a refund
+// within the refund window of its codex.
+type MsgRequestRefund struct {
+	HolderAccount sdk.AccAddress `json:"holder-account"`
+	Holder        string         `json:"holder"`
+	Voucher       string         `json:"voucher"`
+}
+
+var _ sdk.Msg = MsgRequestRefund{}
+
+// Implements sdk.Msg
+func (msg MsgRequestRefund) Type() string { return "refund" }
+
+// Implements sdk.Msg
+func (msg MsgRequestRefund) ValidateBasic() sdk.Error {
+	if msg.Holder != types.UserId(msg.HolderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Holder))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgRequestRefund) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgRequestRefund) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.HolderAccount}
+}
+
+// MsgApproveRefund is sent by the codex owner to refund a voucher under
+// request.
+type MsgApproveRefund struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Voucher      string         `json:"voucher"`
+}
+
+var _ types.OwnerMsg = MsgApproveRefund{}
+
+// Implements sdk.Msg
+func (msg MsgApproveRefund) Type() string { return "refund" }
+
+// Implements sdk.Msg
+func (msg MsgApproveRefund) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return msg.ValidateFields()
+}
+
+// Implements types.OwnerMsg
+func (msg MsgApproveRefund) ValidateFields() sdk.Error {
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgApproveRefund) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgApproveRefund) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// Implements types.OwnerMsg
+func (msg MsgApproveRefund) GetOwner() string { return msg.Owner }
+
+// MsgRejectRefund is sent by the codex owner to turn down a refund request,
+// returning the voucher to its holder.
+type MsgRejectRefund struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Voucher      string         `json:"voucher"`
+}
+
+var _ types.OwnerMsg = MsgRejectRefund{}
+
+// Implements sdk.Msg
+func (msg MsgRejectRefund) Type() string { return "refund" }
+
+// Implements sdk.Msg
+func (msg MsgRejectRefund) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return msg.ValidateFields()
+}
+
+// Implements types.OwnerMsg
+func (msg MsgRejectRefund) ValidateFields() sdk.Error {
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgRejectRefund) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgRejectRefund) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// Implements types.OwnerMsg
+func (msg MsgRejectRefund) GetOwner() string { return msg.Owner }
@@ -0,0 +1,103 @@
+package shop
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Shop errors reserve 100 ~ 199.
+const (
+	DefaultCodespace sdk.CodespaceType = 11
+
+	CodeInvalidId           sdk.CodeType = 101
+	CodeUnknownCodex        sdk.CodeType = 102
+	CodeUnknownVoucher      sdk.CodeType = 103
+	CodeNotHolder           sdk.CodeType = 104
+	CodeSoldOut             sdk.CodeType = 105
+	CodeInvalidPayment      sdk.CodeType = 106
+	CodeVoucherExpired      sdk.CodeType = 107
+	CodeNotStoredValue      sdk.CodeType = 108
+	CodeInsufficientBalance sdk.CodeType = 109
+	CodeNotTransferable     sdk.CodeType = 110
+	CodeNotCodexOwner       sdk.CodeType = 111
+	CodeUnknownPending      sdk.CodeType = 112
+	CodeDeadline            sdk.CodeType = 113
+	CodeNotExpired          sdk.CodeType = 114
+	CodeCodexClosed         sdk.CodeType = 115
+	CodeUndercovered        sdk.CodeType = 116
+	CodeInvalidRoyalty      sdk.CodeType = 117
+	CodeDenomNotAllowed     sdk.CodeType = 118
+)
+
+func ErrInvalidId(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidId, fmt.Sprintf("invalid id %s", id))
+}
+
+func ErrUnknownCodex(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownCodex, fmt.Sprintf("no codex found with the id %s", id))
+}
+
+func ErrUnknownVoucher(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownVoucher, fmt.Sprintf("no voucher found with the id %s", id))
+}
+
+func ErrNotHolder(codespace sdk.CodespaceType, holder string, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotHolder, fmt.Sprintf("%s does not hold the voucher %s", holder, id))
+}
+
+func ErrSoldOut(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeSoldOut, fmt.Sprintf("no more vouchers available from the codex %s", id))
+}
+
+func ErrInvalidPayment(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidPayment, msg)
+}
+
+func ErrVoucherExpired(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeVoucherExpired, fmt.Sprintf("the voucher %s has expired", id))
+}
+
+func ErrNotStoredValue(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotStoredValue, fmt.Sprintf("the voucher %s does not carry a balance", id))
+}
+
+func ErrInsufficientBalance(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeInsufficientBalance, fmt.Sprintf("the voucher %s has insufficient balance", id))
+}
+
+func ErrNotTransferable(codespace sdk.CodespaceType, id string, policy string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotTransferable, fmt.Sprintf("the voucher %s cannot be transferred under the %s policy", id, policy))
+}
+
+func ErrNotCodexOwner(codespace sdk.CodespaceType, owner string, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotCodexOwner, fmt.Sprintf("%s does not own the codex %s", owner, id))
+}
+
+func ErrUnknownPending(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownPending, fmt.Sprintf("no pending transfer found with the id %s", id))
+}
+
+func ErrNotExpired(codespace sdk.CodespaceType, id string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotExpired, fmt.Sprintf("the voucher %s has not expired", id))
+}
+
+func ErrDeadline(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeDeadline, msg)
+}
+
+func ErrCodexClosed(codespace sdk.CodespaceType, id string, status string) sdk.Error {
+	return sdk.NewError(codespace, CodeCodexClosed, fmt.Sprintf("the codex %s is %s", id, status))
+}
+
+func ErrUndercovered(codespace sdk.CodespaceType, id string, deposit int, required int) sdk.Error {
+	return sdk.NewError(codespace, CodeUndercovered, fmt.Sprintf("the codex %s would keep a deposit of %d, less than %d for its vouchers", id, deposit, required))
+}
+
+func ErrInvalidRoyalty(codespace sdk.CodespaceType, id string, err error) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidRoyalty, fmt.Sprintf("the codex %s can not take royalties: %v", id, err))
+}
+
+func ErrDenomNotAllowed(codespace sdk.CodespaceType, denom string) sdk.Error {
+	return sdk.NewError(codespace, CodeDenomNotAllowed, fmt.Sprintf("vouchers can not be priced in %s", denom))
+}
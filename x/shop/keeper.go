@@ -0,0 +1,550 @@
+package shop
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/vesting"
+)
+
+// Keeper moves BVS assets around. User accounts keep their coins in the
+// account store and are handled by bank.Keeper, while a codex keeps its coins
+// in its own record. Every path that changes coins of a codex or the holder
+// of a voucher should go through this keeper. Locked coins of vesting
+// accounts cannot be taken out of them.
+type Keeper struct {
+	cm types.CodexMapper
+	vm types.VoucherMapper
+	pm types.PendingMapper
+	ck bank.Keeper
+	vk vesting.Keeper
+	am types.ParamsMapper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cm types.CodexMapper, vm types.VoucherMapper, pm types.PendingMapper, ck bank.Keeper, vk vesting.Keeper, am types.ParamsMapper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{cm: cm, vm: vm, pm: pm, ck: ck, vk: vk, am: am, codespace: codespace}
+}
+
+func (k Keeper) CodexMapper() types.CodexMapper     { return k.cm }
+func (k Keeper) VoucherMapper() types.VoucherMapper { return k.vm }
+func (k Keeper) PendingMapper() types.PendingMapper { return k.pm }
+func (k Keeper) ParamsMapper() types.ParamsMapper   { return k.am }
+func (k Keeper) Codespace() sdk.CodespaceType       { return k.codespace }
+
+// AddCoins credits coins to a user or a codex.
+func (k Keeper) AddCoins(ctx sdk.Context, id string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	switch types.IdKind(id) {
+	case types.KindUser:
+		addr, err := types.AddressFromUserId(id)
+		if err != nil {
+			return nil, ErrInvalidId(k.codespace, id)
+		}
+		_, tags, sdkErr := k.ck.AddCoins(ctx, addr, amt)
+		return tags, sdkErr
+	case types.KindCodex:
+		cod := k.cm.GetCodex(ctx, id)
+		if cod == nil {
+			return nil, ErrUnknownCodex(k.codespace, id)
+		}
+		cod.Coins = cod.Coins.Plus(amt)
+		cod.SyncDeposit()
+		k.cm.SetCodex(ctx, cod)
+		return sdk.NewTags("recipient", []byte(id)), nil
+	}
+	return nil, ErrInvalidId(k.codespace, id)
+}
+
+// SubtractCoins debits coins from a user or a codex. The unearned proceeds and
+// escrowed balances of a codex cannot be debited this way; see ReleaseProceeds
+// and ReleaseBalance.
+func (k Keeper) SubtractCoins(ctx sdk.Context, id string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	switch types.IdKind(id) {
+	case types.KindUser:
+		addr, err := types.AddressFromUserId(id)
+		if err != nil {
+			return nil, ErrInvalidId(k.codespace, id)
+		}
+		if err := k.vk.CheckSpend(ctx, addr, amt); err != nil {
+			return nil, err
+		}
+		_, tags, sdkErr := k.ck.SubtractCoins(ctx, addr, amt)
+		return tags, sdkErr
+	case types.KindCodex:
+		cod := k.cm.GetCodex(ctx, id)
+		if cod == nil {
+			return nil, ErrUnknownCodex(k.codespace, id)
+		}
+		newCoins := cod.Coins.Minus(amt)
+		if !newCoins.IsNotNegative() || cod.DepositOf(newCoins) < 0 {
+			return nil, sdk.ErrInsufficientCoins(fmt.Sprintf("%s < %s", cod.Coins, amt))
+		}
+		cod.Coins = newCoins
+		cod.SyncDeposit()
+		k.cm.SetCodex(ctx, cod)
+		return sdk.NewTags("sender", []byte(id)), nil
+	}
+	return nil, ErrInvalidId(k.codespace, id)
+}
+
+// SendCoins moves coins between users and codices.
+func (k Keeper) SendCoins(ctx sdk.Context, from string, to string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	subTags, err := k.SubtractCoins(ctx, from, amt)
+	if err != nil {
+		return nil, err
+	}
+	addTags, err := k.AddCoins(ctx, to, amt)
+	if err != nil {
+		return nil, err
+	}
+	return subTags.AppendTags(addTags), nil
+}
+
+// GetLiveVoucher returns a voucher which exists and has not expired yet.
+func (k Keeper) GetLiveVoucher(ctx sdk.Context, id string) (*types.Voucher, sdk.Error) {
+	vou := k.vm.GetVoucher(ctx, id)
+	if vou == nil {
+		return nil, ErrUnknownVoucher(k.codespace, id)
+	}
+	if vou.IsExpired(ctx.BlockHeight()) {
+		return nil, ErrVoucherExpired(k.codespace, id)
+	}
+	return vou, nil
+}
+
+// CheckTransferable tells whether the transfer policy of the issuing codex
+// allows a voucher to be handed over to a new holder. An empty holder, which
+// is not known yet, is only allowed under the free policy.
+func (k Keeper) CheckTransferable(ctx sdk.Context, vou *types.Voucher, to string) sdk.Error {
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		// vouchers of dealers are not bound by any codex policy
+		return nil
+	}
+	switch policy := cod.TransferPolicy(); policy {
+	case types.TransferFree:
+		return nil
+	case types.TransferOwnerApproved:
+		if to == "" || vou.ApprovedTo != to {
+			return ErrNotTransferable(k.codespace, vou.Id, policy)
+		}
+		return nil
+	default:
+		return ErrNotTransferable(k.codespace, vou.Id, policy)
+	}
+}
+
+// TransferVoucher changes the holder of a voucher from one user to another,
+// subject to the transfer policy of the issuing codex.
+func (k Keeper) TransferVoucher(ctx sdk.Context, from string, to string, id string) (sdk.Tags, sdk.Error) {
+	if types.IdKind(to) != types.KindUser {
+		return nil, ErrInvalidId(k.codespace, to)
+	}
+	vou, err := k.GetLiveVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if vou.Holder != from {
+		return nil, ErrNotHolder(k.codespace, from, id)
+	}
+	if err := k.CheckTransferable(ctx, vou, to); err != nil {
+		return nil, err
+	}
+	vou.Holder = to
+	vou.ApprovedTo = ""
+	k.vm.SetVoucher(ctx, vou)
+	return sdk.NewTags("voucher", []byte(id), "holder", []byte(to)), nil
+}
+
+// EscrowVoucher hands a voucher over to an escrow on its way to the given
+// recipient. The transfer policy is checked against the eventual recipient
+// unless it is not known yet, in which case the policy must be free.
+func (k Keeper) EscrowVoucher(ctx sdk.Context, from string, escrow string, to string, id string) sdk.Error {
+	vou, err := k.GetLiveVoucher(ctx, id)
+	if err != nil {
+		return err
+	}
+	if vou.Holder != from {
+		return ErrNotHolder(k.codespace, from, id)
+	}
+	if err := k.CheckTransferable(ctx, vou, to); err != nil {
+		return err
+	}
+	vou.Holder = escrow
+	k.vm.SetVoucher(ctx, vou)
+	return nil
+}
+
+// ReleaseVoucher hands a voucher held by an escrow over to a user, which is
+// either the recipient checked by EscrowVoucher or the original holder.
+func (k Keeper) ReleaseVoucher(ctx sdk.Context, escrow string, to string, id string) sdk.Error {
+	vou := k.vm.GetVoucher(ctx, id)
+	if vou == nil {
+		return ErrUnknownVoucher(k.codespace, id)
+	}
+	if vou.Holder != escrow {
+		return ErrNotHolder(k.codespace, escrow, id)
+	}
+	vou.Holder = to
+	vou.ApprovedTo = ""
+	k.vm.SetVoucher(ctx, vou)
+	return nil
+}
+
+// LockAsset takes coins and vouchers of an asset from a user into an escrow
+// on its way to the given recipient, as EscrowVoucher does for a voucher. The
+// coins are held by no account until ReleaseAsset pays them out.
+func (k Keeper) LockAsset(ctx sdk.Context, from string, escrow string, to string, asset types.BvsAsset) (sdk.Tags, sdk.Error) {
+	tags := sdk.EmptyTags()
+	if !asset.Coins.IsZero() {
+		subTags, err := k.SubtractCoins(ctx, from, asset.Coins)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(subTags)
+	}
+	for _, id := range asset.Vouchers {
+		if err := k.EscrowVoucher(ctx, from, escrow, to, id); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// ReleaseAsset hands an asset locked by an escrow with LockAsset over to a
+// user, which is either the recipient or the original holder.
+func (k Keeper) ReleaseAsset(ctx sdk.Context, escrow string, to string, asset types.BvsAsset) (sdk.Tags, sdk.Error) {
+	tags := sdk.EmptyTags()
+	if !asset.Coins.IsZero() {
+		addTags, err := k.AddCoins(ctx, to, asset.Coins)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(addTags)
+	}
+	for _, id := range asset.Vouchers {
+		if err := k.ReleaseVoucher(ctx, escrow, to, id); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// SendAsset moves coins and vouchers of an asset from one user to another.
+// Vouchers sent this way are gifts, which owe no royalty; see SellVoucher.
+func (k Keeper) SendAsset(ctx sdk.Context, from string, to string, asset types.BvsAsset) (sdk.Tags, sdk.Error) {
+	tags := sdk.EmptyTags()
+	if !asset.Coins.IsZero() {
+		coinTags, err := k.SendCoins(ctx, from, to, asset.Coins)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(coinTags)
+	}
+	for _, id := range asset.Vouchers {
+		vouTags, err := k.TransferVoucher(ctx, from, to, id)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(vouTags)
+	}
+	if len(asset.Vouchers) > 0 {
+		tags = tags.AppendTag("action", []byte("gift"))
+	}
+	return tags, nil
+}
+
+// ApproveTransfer lets the owner of a codex with the owner-approved policy
+// allow one of its vouchers to be transferred to the given user. The approval
+// is used up by the next transfer.
+func (k Keeper) ApproveTransfer(ctx sdk.Context, owner string, id string, to string) (sdk.Tags, sdk.Error) {
+	vou, err := k.GetLiveVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, vou.Origin)
+	}
+	if cod.Owner != owner {
+		return nil, ErrNotCodexOwner(k.codespace, owner, cod.Id)
+	}
+	if policy := cod.TransferPolicy(); policy != types.TransferOwnerApproved {
+		return nil, ErrNotTransferable(k.codespace, id, policy)
+	}
+	k.TouchCodex(ctx, cod.Id)
+	vou.ApprovedTo = to
+	k.vm.SetVoucher(ctx, vou)
+	return sdk.NewTags("action", []byte("approve-transfer"), "voucher", []byte(id)), nil
+}
+
+// TouchCodex records an action of the codex owner at the current height, which
+// keeps the codex from being considered abandoned.
+func (k Keeper) TouchCodex(ctx sdk.Context, codexId string) {
+	cod := k.cm.GetCodex(ctx, codexId)
+	if cod == nil {
+		return
+	}
+	cod.LastActive = int(ctx.BlockHeight())
+	k.cm.SetCodex(ctx, cod)
+}
+
+// Purchase issues a new voucher of a codex to the buyer in exchange for the
+// paid coins. An ordinary codex sells vouchers at exactly its unit price and
+// the payment goes to the codex owner. A stored-value codex takes any amount
+// of silver not less than its unit price and escrows the payment in the codex
+// as the balance of the voucher.
+func (k Keeper) Purchase(ctx sdk.Context, buyer string, codexId string, paid sdk.Coins) (*types.Voucher, sdk.Tags, sdk.Error) {
+	cod := k.cm.GetCodex(ctx, codexId)
+	if cod == nil {
+		return nil, nil, ErrUnknownCodex(k.codespace, codexId)
+	}
+	if cod.CountAvail <= 0 {
+		return nil, nil, ErrSoldOut(k.codespace, codexId)
+	}
+
+	price := sdk.Coins{sdk.NewInt64Coin(types.DenomSilver, int64(cod.UnitPrice))}
+	if cod.StoredValue {
+		if len(paid) != 1 || paid[0].Denom != types.DenomSilver || !paid.IsGTE(price) {
+			return nil, nil, ErrInvalidPayment(k.codespace,
+				fmt.Sprintf("stored-value vouchers take at least %s", price))
+		}
+	} else if !paid.IsEqual(price) {
+		return nil, nil, ErrInvalidPayment(k.codespace,
+			fmt.Sprintf("expected %s, got %s", price, paid))
+	}
+
+	tags, err := k.SubtractCoins(ctx, buyer, paid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cod.EscrowProceeds && !cod.StoredValue {
+		k.escrowProceeds(ctx, codexId, paid)
+	} else if !cod.StoredValue {
+		payTags, err := k.AddCoins(ctx, k.Payee(cod), paid)
+		if err != nil {
+			return nil, nil, err
+		}
+		tags = tags.AppendTags(payTags)
+	}
+
+	vou, err := k.IssueVoucher(ctx, codexId, buyer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cod.StoredValue {
+		k.escrowBalance(ctx, vou, paid)
+	} else if cod.EscrowProceeds {
+		vou.Proceeds = cod.UnitPrice
+		k.vm.SetVoucher(ctx, vou)
+	}
+
+	tags = tags.AppendTags(sdk.NewTags(
+		"action", []byte("purchase"),
+		"codex", []byte(codexId),
+		"voucher", []byte(vou.Id),
+	))
+	return vou, tags, nil
+}
+
+// IssueVoucher creates a new voucher of a codex held by the given user,
+// without taking any payment for it.
+func (k Keeper) IssueVoucher(ctx sdk.Context, codexId string, holder string) (*types.Voucher, sdk.Error) {
+	cod := k.cm.GetCodex(ctx, codexId)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, codexId)
+	}
+	if cod.CountAvail <= 0 {
+		return nil, ErrSoldOut(k.codespace, codexId)
+	}
+	vou := &types.Voucher{
+		Id:       k.nextVoucherId(ctx, cod),
+		Origin:   codexId,
+		Holder:   holder,
+		IssuedOn: int(ctx.BlockHeight()),
+	}
+	expireAfter := cod.ExpireAfter
+	if max := k.am.GetParams(ctx).Shop.MaxExpireAfter; max > 0 && (expireAfter <= 0 || expireAfter > max) {
+		expireAfter = max
+	}
+	if expireAfter > 0 {
+		vou.ExpireOn = int(ctx.BlockHeight()) + expireAfter
+	}
+	cod.CountAvail--
+	cod.CountLive++
+	k.cm.SetCodex(ctx, cod)
+	k.vm.SetVoucher(ctx, vou)
+	return vou, nil
+}
+
+// nextVoucherId picks the id for the next voucher of a codex and bumps its
+// serial counter. Ids taken by vouchers loaded from genesis are skipped.
+func (k Keeper) nextVoucherId(ctx sdk.Context, cod *types.Codex) string {
+	for {
+		id := types.VoucherId(cod.Id, cod.CountIssued)
+		cod.CountIssued++
+		if k.vm.GetVoucher(ctx, id) == nil {
+			return id
+		}
+	}
+}
+
+// Redeem burns a voucher returned to its issuing codex by the holder. A
+// stored-value voucher must be spent down before it can be redeemed. Proceeds
+// escrowed for the voucher are released to the codex owner.
+func (k Keeper) Redeem(ctx sdk.Context, holder string, id string) (sdk.Tags, sdk.Error) {
+	vou, err := k.GetLiveVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if vou.Holder != holder {
+		return nil, ErrNotHolder(k.codespace, holder, id)
+	}
+	if !vou.Balance.IsZero() {
+		return nil, ErrInvalidPayment(k.codespace,
+			fmt.Sprintf("the voucher %s still carries %s", id, vou.Balance))
+	}
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, vou.Origin)
+	}
+	tags, err := k.ReleaseProceeds(ctx, vou, k.Payee(cod))
+	if err != nil {
+		return nil, err
+	}
+	if err := k.burnVoucher(ctx, vou); err != nil {
+		return nil, err
+	}
+	return tags.AppendTags(sdk.NewTags("action", []byte("redeem"), "voucher", []byte(id))), nil
+}
+
+// FreezeVoucher hands a voucher over to an escrow which will either return
+// it to the holder or burn it, so no transfer policy applies.
+func (k Keeper) FreezeVoucher(ctx sdk.Context, holder string, escrow string, id string) sdk.Error {
+	vou, err := k.GetLiveVoucher(ctx, id)
+	if err != nil {
+		return err
+	}
+	if vou.Holder != holder {
+		return ErrNotHolder(k.codespace, holder, id)
+	}
+	vou.Holder = escrow
+	k.vm.SetVoucher(ctx, vou)
+	return nil
+}
+
+// BurnVoucher burns a voucher held by the given holder, usually an escrow.
+// Any balance left on the voucher is gone with it, so the caller must settle
+// it first.
+func (k Keeper) BurnVoucher(ctx sdk.Context, escrow string, id string) sdk.Error {
+	vou := k.vm.GetVoucher(ctx, id)
+	if vou == nil {
+		return ErrUnknownVoucher(k.codespace, id)
+	}
+	if vou.Holder != escrow {
+		return ErrNotHolder(k.codespace, escrow, id)
+	}
+	return k.burnVoucher(ctx, vou)
+}
+
+func (k Keeper) burnVoucher(ctx sdk.Context, vou *types.Voucher) sdk.Error {
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return ErrUnknownCodex(k.codespace, vou.Origin)
+	}
+	cod.CountLive--
+	k.cm.SetCodex(ctx, cod)
+	k.vm.DeleteVoucher(ctx, vou.Id)
+	return nil
+}
+
+// SpendVoucher pays part of the balance of a stored-value voucher to the
+// owner of the issuing codex.
+func (k Keeper) SpendVoucher(ctx sdk.Context, holder string, id string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	vou, err := k.GetLiveVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if vou.Holder != holder {
+		return nil, ErrNotHolder(k.codespace, holder, id)
+	}
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, vou.Origin)
+	}
+	if !cod.StoredValue {
+		return nil, ErrNotStoredValue(k.codespace, id)
+	}
+	tags, err := k.ReleaseBalance(ctx, vou, k.Payee(cod), amt)
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTags(sdk.NewTags("action", []byte("spend"), "voucher", []byte(id))), nil
+}
+
+// ReloadVoucher tops up the balance of a stored-value voucher with silver
+// from the payer.
+func (k Keeper) ReloadVoucher(ctx sdk.Context, payer string, id string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	vou, err := k.GetLiveVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, vou.Origin)
+	}
+	if !cod.StoredValue {
+		return nil, ErrNotStoredValue(k.codespace, id)
+	}
+	if len(amt) != 1 || amt[0].Denom != types.DenomSilver {
+		return nil, ErrInvalidPayment(k.codespace, "stored-value vouchers take silver only")
+	}
+	tags, err := k.SubtractCoins(ctx, payer, amt)
+	if err != nil {
+		return nil, err
+	}
+	k.escrowBalance(ctx, vou, amt)
+	return tags.AppendTags(sdk.NewTags("action", []byte("reload"), "voucher", []byte(id))), nil
+}
+
+// escrowBalance credits silver paid onto a stored-value voucher to its codex,
+// where it is held apart from the deposit until spent or paid back.
+func (k Keeper) escrowBalance(ctx sdk.Context, vou *types.Voucher, amt sdk.Coins) {
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	cod.Coins = cod.Coins.Plus(amt)
+	cod.Escrowed += int(amt.AmountOf(types.DenomSilver).Int64())
+	cod.SyncDeposit()
+	k.cm.SetCodex(ctx, cod)
+	vou.Balance = vou.Balance.Plus(amt)
+	k.vm.SetVoucher(ctx, vou)
+}
+
+// ReleaseBalance pays part of the balance of a stored-value voucher out of its
+// codex to the given user or codex.
+func (k Keeper) ReleaseBalance(ctx sdk.Context, vou *types.Voucher, to string, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	newBalance := vou.Balance.Minus(amt)
+	if !newBalance.IsNotNegative() {
+		return nil, ErrInsufficientBalance(k.codespace, vou.Id)
+	}
+	if amt.IsZero() {
+		return sdk.EmptyTags(), nil
+	}
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, vou.Origin)
+	}
+	cod.Escrowed -= int(amt.AmountOf(types.DenomSilver).Int64())
+	k.cm.SetCodex(ctx, cod)
+	tags, err := k.SendCoins(ctx, cod.Id, to, amt)
+	if err != nil {
+		return nil, err
+	}
+	vou.Balance = newBalance
+	k.vm.SetVoucher(ctx, vou)
+	return tags, nil
+}
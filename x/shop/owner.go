@@ -0,0 +1,324 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// Payee returns who gets paid for the business of a codex: its owner, or the
+// codex itself when the owner is a multisig, which has no account to pay to.
+// The members can then take the coins out with MsgWithdraw.
+func (k Keeper) Payee(cod *types.Codex) string {
+	if types.IdKind(cod.Owner) == types.KindUser {
+		return cod.Owner
+	}
+	return cod.Id
+}
+
+// getOwnedCodex returns a codex after checking its owner, and records the
+// activity of the owner.
+func (k Keeper) getOwnedCodex(ctx sdk.Context, owner string, codexId string) (*types.Codex, sdk.Error) {
+	cod := k.cm.GetCodex(ctx, codexId)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, codexId)
+	}
+	if cod.Owner != owner {
+		return nil, ErrNotCodexOwner(k.codespace, owner, codexId)
+	}
+	cod.LastActive = int(ctx.BlockHeight())
+	k.cm.SetCodex(ctx, cod)
+	return cod, nil
+}
+
+// checkCoverage tells whether the silver of coins, less the unearned
+// proceeds and escrowed balances of a codex, covers the minimum deposit per
+// voucher for the live vouchers of the codex and the avail ones still for
+// sale.
+func (k Keeper) checkCoverage(ctx sdk.Context, cod *types.Codex, coins sdk.Coins, avail int) sdk.Error {
+	deposit := cod.DepositOf(coins)
+	required := k.am.GetParams(ctx).Shop.MinDepositPerVoucher * (avail + cod.CountLive)
+	if deposit < required {
+		return ErrUndercovered(k.codespace, cod.Id, deposit, required)
+	}
+	return nil
+}
+
+// CheckDenom tells whether vouchers may be priced in a denomination.
+func (k Keeper) CheckDenom(ctx sdk.Context, denom string) sdk.Error {
+	if !k.am.GetParams(ctx).IsDenomAllowed(denom) {
+		return ErrDenomNotAllowed(k.codespace, denom)
+	}
+	return nil
+}
+
+// Withdraw moves coins of an active codex to a user, as long as they are not
+// unearned proceeds or escrowed balances and the deposit left covers the
+// vouchers of the codex. The deposit backs the vouchers for sale and the live
+// ones alike, so that only the part backing those for sale can be taken out:
+// as many vouchers for sale as the silver taken out backs are retired with
+// it, and each voucher left keeps at least the backing it had.
+func (k Keeper) Withdraw(ctx sdk.Context, owner string, codexId string, amt sdk.Coins, to string) (sdk.Tags, sdk.Error) {
+	cod, err := k.getOwnedCodex(ctx, owner, codexId)
+	if err != nil {
+		return nil, err
+	}
+	if cod.Status != types.CodexActive {
+		return nil, ErrCodexClosed(k.codespace, codexId, cod.Status)
+	}
+	if types.IdKind(to) != types.KindUser {
+		return nil, ErrInvalidId(k.codespace, to)
+	}
+	if !amt.IsValid() || !amt.IsPositive() {
+		return nil, sdk.ErrInvalidCoins(amt.String())
+	}
+	retired := 0
+	if withdrawn := int(amt.AmountOf(types.DenomSilver).Int64()); cod.CountLive > 0 && withdrawn > 0 {
+		count := cod.CountAvail + cod.CountLive
+		if cod.Deposit > 0 {
+			retired = (withdrawn*count + cod.Deposit - 1) / cod.Deposit
+		}
+		if cod.Deposit <= 0 || retired > cod.CountAvail {
+			return nil, ErrUndercovered(k.codespace, codexId, cod.Deposit-withdrawn, cod.Deposit*cod.CountLive/count)
+		}
+	}
+	if err := k.checkCoverage(ctx, cod, cod.Coins.Minus(amt), cod.CountAvail); err != nil {
+		return nil, err
+	}
+	cod.CountAvail -= retired
+	k.cm.SetCodex(ctx, cod)
+	tags, err := k.SendCoins(ctx, codexId, to, amt)
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTags(sdk.NewTags("action", []byte("withdraw"), "codex", []byte(codexId))), nil
+}
+
+// Restock makes more vouchers of an active codex available for sale.
+func (k Keeper) Restock(ctx sdk.Context, owner string, codexId string, count int) (sdk.Tags, sdk.Error) {
+	if count <= 0 {
+		return nil, sdk.ErrUnknownRequest("non-positive count")
+	}
+	cod, err := k.getOwnedCodex(ctx, owner, codexId)
+	if err != nil {
+		return nil, err
+	}
+	if cod.Status != types.CodexActive {
+		return nil, ErrCodexClosed(k.codespace, codexId, cod.Status)
+	}
+	if err := k.checkCoverage(ctx, cod, cod.Coins, cod.CountAvail+count); err != nil {
+		return nil, err
+	}
+	cod.CountAvail += count
+	k.cm.SetCodex(ctx, cod)
+	return sdk.NewTags("action", []byte("restock"), "codex", []byte(codexId)), nil
+}
+
+// TransferOwnership hands a codex over to a new owner, either a user or a
+// multisig.
+func (k Keeper) TransferOwnership(ctx sdk.Context, owner string, codexId string, newOwner string) (sdk.Tags, sdk.Error) {
+	cod, err := k.getOwnedCodex(ctx, owner, codexId)
+	if err != nil {
+		return nil, err
+	}
+	if kind := types.IdKind(newOwner); kind != types.KindUser && kind != types.KindMultisig {
+		return nil, ErrInvalidId(k.codespace, newOwner)
+	}
+	cod.Owner = newOwner
+	k.cm.SetCodex(ctx, cod)
+	return sdk.NewTags("action", []byte("transfer-ownership"), "codex", []byte(codexId), "owner", []byte(newOwner)), nil
+}
+
+// MsgWithdraw takes coins out of a codex.
+type MsgWithdraw struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Codex        string         `json:"codex"`
+	Amount       sdk.Coins      `json:"amount"`
+	Recipient    string         `json:"recipient"`
+}
+
+var _ types.OwnerMsg = MsgWithdraw{}
+
+// Implements sdk.Msg
+func (msg MsgWithdraw) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgWithdraw) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return msg.ValidateFields()
+}
+
+// Implements types.OwnerMsg
+func (msg MsgWithdraw) ValidateFields() sdk.Error {
+	if !msg.Amount.IsValid() || !msg.Amount.IsPositive() {
+		return sdk.ErrInvalidCoins(msg.Amount.String())
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgWithdraw) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgWithdraw) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// Implements types.OwnerMsg
+func (msg MsgWithdraw) GetOwner() string { return msg.Owner }
+
+func BuildWithdrawMsg(ownerAccount sdk.AccAddress, owner string, codex string, amount sdk.Coins, recp string) sdk.Msg {
+	return &MsgWithdraw{
+		OwnerAccount: ownerAccount,
+		Owner:        owner,
+		Codex:        codex,
+		Amount:       amount,
+		Recipient:    recp,
+	}
+}
+
+// MsgRestock makes more vouchers of a codex available for sale.
+type MsgRestock struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Codex        string         `json:"codex"`
+	Count        int            `json:"count"`
+}
+
+var _ types.OwnerMsg = MsgRestock{}
+
+// Implements sdk.Msg
+func (msg MsgRestock) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgRestock) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return msg.ValidateFields()
+}
+
+// Implements types.OwnerMsg
+func (msg MsgRestock) ValidateFields() sdk.Error {
+	if msg.Count <= 0 {
+		return sdk.ErrUnknownRequest("non-positive count")
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgRestock) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgRestock) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// Implements types.OwnerMsg
+func (msg MsgRestock) GetOwner() string { return msg.Owner }
+
+func BuildRestockMsg(ownerAccount sdk.AccAddress, owner string, codex string, count int) sdk.Msg {
+	return &MsgRestock{
+		OwnerAccount: ownerAccount,
+		Owner:        owner,
+		Codex:        codex,
+		Count:        count,
+	}
+}
+
+// MsgTransferOwnership hands a codex over to a new owner.
+type MsgTransferOwnership struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Codex        string         `json:"codex"`
+	NewOwner     string         `json:"new-owner"`
+}
+
+var _ types.OwnerMsg = MsgTransferOwnership{}
+
+// Implements sdk.Msg
+func (msg MsgTransferOwnership) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgTransferOwnership) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return msg.ValidateFields()
+}
+
+// Implements types.OwnerMsg
+func (msg MsgTransferOwnership) ValidateFields() sdk.Error {
+	if kind := types.IdKind(msg.NewOwner); kind != types.KindUser && kind != types.KindMultisig {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("%s can not own a codex", msg.NewOwner))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgTransferOwnership) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgTransferOwnership) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// Implements types.OwnerMsg
+func (msg MsgTransferOwnership) GetOwner() string { return msg.Owner }
+
+func BuildTransferOwnershipMsg(ownerAccount sdk.AccAddress, owner string, codex string, newOwner string) sdk.Msg {
+	return &MsgTransferOwnership{
+		OwnerAccount: ownerAccount,
+		Owner:        owner,
+		Codex:        codex,
+		NewOwner:     newOwner,
+	}
+}
+
+func handleMsgWithdraw(ctx sdk.Context, k Keeper, msg *MsgWithdraw) sdk.Result {
+	tags, err := k.Withdraw(ctx, msg.Owner, msg.Codex, msg.Amount, msg.Recipient)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgRestock(ctx sdk.Context, k Keeper, msg *MsgRestock) sdk.Result {
+	tags, err := k.Restock(ctx, msg.Owner, msg.Codex, msg.Count)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgTransferOwnership(ctx sdk.Context, k Keeper, msg *MsgTransferOwnership) sdk.Result {
+	tags, err := k.TransferOwnership(ctx, msg.Owner, msg.Codex, msg.NewOwner)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
@@ -0,0 +1,182 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// SendPending moves an asset from the sender into a new pending transfer,
+// which the recipient may accept until pendingFor blocks from now.
+func (k Keeper) SendPending(ctx sdk.Context, sender string, recipient string, asset types.BvsAsset, pendingFor int) (*types.PendingTransfer, sdk.Tags, sdk.Error) {
+	if types.IdKind(recipient) != types.KindUser {
+		return nil, nil, ErrInvalidId(k.codespace, recipient)
+	}
+	pt := &types.PendingTransfer{
+		Id:        k.pm.NextPendingId(ctx),
+		Sender:    sender,
+		Recipient: recipient,
+		Vouchers:  asset.Vouchers,
+		Deadline:  int(ctx.BlockHeight()) + pendingFor,
+	}
+	if !asset.Coins.IsZero() {
+		pt.Coins = asset.Coins
+	}
+	tags, err := k.LockAsset(ctx, sender, pt.Id, recipient, asset)
+	if err != nil {
+		return nil, nil, err
+	}
+	k.pm.SetPending(ctx, pt)
+
+	tags = tags.AppendTags(sdk.NewTags(
+		"action", []byte("send-pending"),
+		"pending", []byte(pt.Id),
+		"recipient", []byte(recipient),
+	))
+	return pt, tags, nil
+}
+
+// AcceptTransfer hands the assets of a pending transfer over to its recipient.
+func (k Keeper) AcceptTransfer(ctx sdk.Context, recipient string, id string) (sdk.Tags, sdk.Error) {
+	pt := k.pm.GetPending(ctx, id)
+	if pt == nil {
+		return nil, ErrUnknownPending(k.codespace, id)
+	}
+	if pt.Recipient != recipient {
+		return nil, sdk.ErrUnauthorized(fmt.Sprintf("%s is not the recipient of %s", recipient, id))
+	}
+	if ctx.BlockHeight() > int64(pt.Deadline) {
+		return nil, ErrDeadline(k.codespace, fmt.Sprintf("%s was to be accepted by %d", id, pt.Deadline))
+	}
+	return k.closePending(ctx, pt, pt.Recipient, "accept-transfer")
+}
+
+// ReclaimTransfer returns the assets of a pending transfer to its sender once
+// the deadline has passed.
+func (k Keeper) ReclaimTransfer(ctx sdk.Context, sender string, id string) (sdk.Tags, sdk.Error) {
+	pt := k.pm.GetPending(ctx, id)
+	if pt == nil {
+		return nil, ErrUnknownPending(k.codespace, id)
+	}
+	if pt.Sender != sender {
+		return nil, sdk.ErrUnauthorized(fmt.Sprintf("%s is not the sender of %s", sender, id))
+	}
+	if ctx.BlockHeight() <= int64(pt.Deadline) {
+		return nil, ErrDeadline(k.codespace, fmt.Sprintf("%s can be reclaimed after %d", id, pt.Deadline))
+	}
+	return k.closePending(ctx, pt, pt.Sender, "reclaim-transfer")
+}
+
+func (k Keeper) closePending(ctx sdk.Context, pt *types.PendingTransfer, to string, action string) (sdk.Tags, sdk.Error) {
+	tags, err := k.ReleaseAsset(ctx, pt.Id, to, types.BvsAsset{Coins: pt.Coins, Vouchers: pt.Vouchers})
+	if err != nil {
+		return nil, err
+	}
+	k.pm.DeletePending(ctx, pt)
+	return tags.AppendTags(sdk.NewTags("action", []byte(action), "pending", []byte(pt.Id))), nil
+}
+
+// MsgAcceptTransfer is sent by the recipient of a pending transfer to take
+// over its assets.
+type MsgAcceptTransfer struct {
+	RecipientAccount sdk.AccAddress `json:"recipient-account"`
+	Recipient        string         `json:"recipient"`
+	Pending          string         `json:"pending"`
+}
+
+var _ sdk.Msg = MsgAcceptTransfer{}
+
+// Implements sdk.Msg
+func (msg MsgAcceptTransfer) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgAcceptTransfer) ValidateBasic() sdk.Error {
+	if msg.Recipient != types.UserId(msg.RecipientAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Recipient))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgAcceptTransfer) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgAcceptTransfer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.RecipientAccount}
+}
+
+func BuildAcceptTransferMsg(recipientAccount sdk.AccAddress, recipient string, pending string) sdk.Msg {
+	return &MsgAcceptTransfer{
+		RecipientAccount: recipientAccount,
+		Recipient:        recipient,
+		Pending:          pending,
+	}
+}
+
+// MsgReclaimTransfer is sent by the sender of a pending transfer to take back
+// its assets after the deadline.
+type MsgReclaimTransfer struct {
+	SenderAccount sdk.AccAddress `json:"sender-account"`
+	Sender        string         `json:"sender"`
+	Pending       string         `json:"pending"`
+}
+
+var _ sdk.Msg = MsgReclaimTransfer{}
+
+// Implements sdk.Msg
+func (msg MsgReclaimTransfer) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgReclaimTransfer) ValidateBasic() sdk.Error {
+	if msg.Sender != types.UserId(msg.SenderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Sender))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgReclaimTransfer) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgReclaimTransfer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.SenderAccount}
+}
+
+func BuildReclaimTransferMsg(senderAccount sdk.AccAddress, sender string, pending string) sdk.Msg {
+	return &MsgReclaimTransfer{
+		SenderAccount: senderAccount,
+		Sender:        sender,
+		Pending:       pending,
+	}
+}
+
+func handleMsgAcceptTransfer(ctx sdk.Context, k Keeper, msg *MsgAcceptTransfer) sdk.Result {
+	tags, err := k.AcceptTransfer(ctx, msg.Recipient, msg.Pending)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgReclaimTransfer(ctx sdk.Context, k Keeper, msg *MsgReclaimTransfer) sdk.Result {
+	tags, err := k.ReclaimTransfer(ctx, msg.Sender, msg.Pending)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
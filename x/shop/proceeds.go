@@ -0,0 +1,130 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// escrowProceeds credits the price of a voucher to a codex as unearned
+// revenue.
+func (k Keeper) escrowProceeds(ctx sdk.Context, codexId string, paid sdk.Coins) {
+	cod := k.cm.GetCodex(ctx, codexId)
+	cod.Coins = cod.Coins.Plus(paid)
+	cod.Unearned += int(paid.AmountOf(types.DenomSilver).Int64())
+	cod.SyncDeposit()
+	k.cm.SetCodex(ctx, cod)
+}
+
+// ReleaseProceeds pays the proceeds escrowed for a voucher out of its codex to
+// the given user. It is a no-op for vouchers without escrowed proceeds.
+func (k Keeper) ReleaseProceeds(ctx sdk.Context, vou *types.Voucher, to string) (sdk.Tags, sdk.Error) {
+	if vou.Proceeds == 0 {
+		return sdk.EmptyTags(), nil
+	}
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, vou.Origin)
+	}
+	cod.Unearned -= vou.Proceeds
+	k.cm.SetCodex(ctx, cod)
+	tags, err := k.SendCoins(ctx, cod.Id, to, sdk.Coins{sdk.NewInt64Coin(types.DenomSilver, int64(vou.Proceeds))})
+	if err != nil {
+		return nil, err
+	}
+	vou.Proceeds = 0
+	k.vm.SetVoucher(ctx, vou)
+	return tags, nil
+}
+
+// SettleExpired burns an expired voucher and hands its escrowed proceeds and
+// balance over according to the breakage policy of its codex. Anyone may
+// settle an expired voucher.
+func (k Keeper) SettleExpired(ctx sdk.Context, id string) (sdk.Tags, sdk.Error) {
+	vou := k.vm.GetVoucher(ctx, id)
+	if vou == nil {
+		return nil, ErrUnknownVoucher(k.codespace, id)
+	}
+	if !vou.IsExpired(ctx.BlockHeight()) {
+		return nil, ErrNotExpired(k.codespace, id)
+	}
+	if types.IdKind(vou.Holder) != types.KindUser {
+		// frozen vouchers are settled by the module holding them
+		return nil, ErrNotHolder(k.codespace, vou.Holder, id)
+	}
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod == nil {
+		return nil, ErrUnknownCodex(k.codespace, vou.Origin)
+	}
+
+	to := k.Payee(cod)
+	if cod.Breakage == types.BreakageRefund {
+		to = vou.Holder
+	}
+	tags, err := k.ReleaseProceeds(ctx, vou, to)
+	if err != nil {
+		return nil, err
+	}
+	balanceTags, err := k.ReleaseBalance(ctx, vou, to, vou.Balance)
+	if err != nil {
+		return nil, err
+	}
+	tags = tags.AppendTags(balanceTags)
+	if err := k.burnVoucher(ctx, vou); err != nil {
+		return nil, err
+	}
+	return tags.AppendTags(sdk.NewTags("action", []byte("settle-expired"), "voucher", []byte(id))), nil
+}
+
+// MsgSettleExpired settles the breakage of an expired voucher.
+type MsgSettleExpired struct {
+	SenderAccount sdk.AccAddress `json:"sender-account"`
+	Sender        string         `json:"sender"`
+	Voucher       string         `json:"voucher"`
+}
+
+var _ sdk.Msg = MsgSettleExpired{}
+
+// Implements sdk.Msg
+func (msg MsgSettleExpired) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgSettleExpired) ValidateBasic() sdk.Error {
+	if msg.Sender != types.UserId(msg.SenderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Sender))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgSettleExpired) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgSettleExpired) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.SenderAccount}
+}
+
+func BuildSettleExpiredMsg(senderAccount sdk.AccAddress, sender string, voucher string) sdk.Msg {
+	return &MsgSettleExpired{
+		SenderAccount: senderAccount,
+		Sender:        sender,
+		Voucher:       voucher,
+	}
+}
+
+func handleMsgSettleExpired(ctx sdk.Context, k Keeper, msg *MsgSettleExpired) sdk.Result {
+	tags, err := k.SettleExpired(ctx, msg.Voucher)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
@@ -0,0 +1,71 @@
+package shop
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// RoyaltyTo returns who the royalty on resales of the vouchers of a codex is
+// paid to.
+func (k Keeper) RoyaltyTo(cod *types.Codex) string {
+	if cod.RoyaltyTo != "" {
+		return cod.RoyaltyTo
+	}
+	return k.Payee(cod)
+}
+
+// SellVoucher hands a voucher held by from over to buyer for a price, which
+// the buyer pays to seller less the royalty of the issuing codex. The holder
+// is either the seller or an escrow selling on its behalf. Every path
+// transferring a voucher for consideration goes through here.
+func (k Keeper) SellVoucher(ctx sdk.Context, from string, seller string, buyer string, id string, price sdk.Coins) (sdk.Tags, sdk.Error) {
+	if types.IdKind(buyer) != types.KindUser {
+		return nil, ErrInvalidId(k.codespace, buyer)
+	}
+	vou, err := k.GetLiveVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if vou.Holder != from {
+		return nil, ErrNotHolder(k.codespace, from, id)
+	}
+	if err := k.CheckTransferable(ctx, vou, buyer); err != nil {
+		return nil, err
+	}
+	cod := k.cm.GetCodex(ctx, vou.Origin)
+	if cod != nil {
+		if err := cod.CheckRoyalty(); err != nil {
+			return nil, ErrInvalidRoyalty(k.codespace, cod.Id, err)
+		}
+	}
+
+	tags, err := k.SubtractCoins(ctx, buyer, price)
+	if err != nil {
+		return nil, err
+	}
+	net := price
+	if cod != nil {
+		if royalty := cod.RoyaltyOf(price); !royalty.IsZero() {
+			to := k.RoyaltyTo(cod)
+			royaltyTags, err := k.AddCoins(ctx, to, royalty)
+			if err != nil {
+				return nil, err
+			}
+			tags = tags.AppendTags(royaltyTags).AppendTags(sdk.NewTags("royalty", []byte(royalty.String()), "royalty-to", []byte(to)))
+			net = price.Minus(royalty)
+		}
+	}
+	if !net.IsZero() {
+		payTags, err := k.AddCoins(ctx, seller, net)
+		if err != nil {
+			return nil, err
+		}
+		tags = tags.AppendTags(payTags)
+	}
+
+	vou.Holder = buyer
+	vou.ApprovedTo = ""
+	k.vm.SetVoucher(ctx, vou)
+	return tags.AppendTags(sdk.NewTags("action", []byte("sale"), "voucher", []byte(id), "holder", []byte(buyer))), nil
+}
@@ -2,6 +2,8 @@ package shop
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
@@ -15,6 +17,10 @@ type MsgBvs struct {
 	Sender        string         `json:"sender"`
 	Recipient     string         `json:"recipient"`
 	Asset         types.BvsAsset `json:"asset"`
+
+	// PendingFor, when positive, sends the asset in pending mode: the
+	// recipient has this many blocks to accept it with MsgAcceptTransfer.
+	PendingFor int `json:"pending-for"`
 }
 
 var _ sdk.Msg = MsgBvs{}
@@ -23,7 +29,21 @@ var _ sdk.Msg = MsgBvs{}
 func (msg MsgBvs) Type() string { return "bvs" }
 
 // Implementw sdk.Msg
-func (msg MsgBvs) ValidateBasic() sdk.Error { return nil }
+func (msg MsgBvs) ValidateBasic() sdk.Error {
+	if msg.Sender != types.UserId(msg.SenderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Sender))
+	}
+	if !msg.Asset.Coins.IsValid() || !msg.Asset.Coins.IsNotNegative() {
+		return sdk.ErrInvalidCoins(msg.Asset.Coins.String())
+	}
+	if msg.PendingFor < 0 {
+		return sdk.ErrUnknownRequest("negative pending-for")
+	}
+	if msg.PendingFor > 0 && types.IdKind(msg.Recipient) != types.KindUser {
+		return sdk.ErrUnknownRequest("only users can receive pending transfers")
+	}
+	return nil
+}
 
 // Implementw sdk.Msg
 func (msg MsgBvs) GetSignBytes() []byte {
@@ -49,9 +69,266 @@ func BuildBvsMsg(senderAccount sdk.AccAddress, sender string, recp string, asset
 	}
 }
 
-func NewHandler(mapper types.CodexMapper) sdk.Handler {
+// build the sendTx msg in pending mode
+func BuildPendingBvsMsg(senderAccount sdk.AccAddress, sender string, recp string, asset *types.BvsAsset, pendingFor int) sdk.Msg {
+	return &MsgBvs{
+		SenderAccount: senderAccount,
+		Sender:        sender,
+		Recipient:     recp,
+		Asset:         *asset,
+		PendingFor:    pendingFor,
+	}
+}
+
+// MsgSpendVoucher pays part of the balance of a stored-value voucher to the
+// owner of the issuing codex.
+type MsgSpendVoucher struct {
+	HolderAccount sdk.AccAddress `json:"holder-account"`
+	Holder        string         `json:"holder"`
+	Voucher       string         `json:"voucher"`
+	Amount        sdk.Coins      `json:"amount"`
+}
+
+var _ sdk.Msg = MsgSpendVoucher{}
+
+// Implements sdk.Msg
+func (msg MsgSpendVoucher) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgSpendVoucher) ValidateBasic() sdk.Error {
+	if msg.Holder != types.UserId(msg.HolderAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Holder))
+	}
+	if !msg.Amount.IsValid() || !msg.Amount.IsPositive() {
+		return sdk.ErrInvalidCoins(msg.Amount.String())
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgSpendVoucher) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgSpendVoucher) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.HolderAccount}
+}
+
+func BuildSpendVoucherMsg(holderAccount sdk.AccAddress, holder string, voucher string, amount sdk.Coins) sdk.Msg {
+	return &MsgSpendVoucher{
+		HolderAccount: holderAccount,
+		Holder:        holder,
+		Voucher:       voucher,
+		Amount:        amount,
+	}
+}
+
+// MsgReloadVoucher tops up the balance of a stored-value voucher. Anyone may
+// reload a voucher, not only its holder.
+type MsgReloadVoucher struct {
+	PayerAccount sdk.AccAddress `json:"payer-account"`
+	Payer        string         `json:"payer"`
+	Voucher      string         `json:"voucher"`
+	Amount       sdk.Coins      `json:"amount"`
+}
+
+var _ sdk.Msg = MsgReloadVoucher{}
+
+// Implements sdk.Msg
+func (msg MsgReloadVoucher) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgReloadVoucher) ValidateBasic() sdk.Error {
+	if msg.Payer != types.UserId(msg.PayerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Payer))
+	}
+	if !msg.Amount.IsValid() || !msg.Amount.IsPositive() {
+		return sdk.ErrInvalidCoins(msg.Amount.String())
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgReloadVoucher) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgReloadVoucher) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.PayerAccount}
+}
+
+func BuildReloadVoucherMsg(payerAccount sdk.AccAddress, payer string, voucher string, amount sdk.Coins) sdk.Msg {
+	return &MsgReloadVoucher{
+		PayerAccount: payerAccount,
+		Payer:        payer,
+		Voucher:      voucher,
+		Amount:       amount,
+	}
+}
+
+// MsgApproveTransfer is sent by the owner of a codex with the owner-approved
+// transfer policy to allow one of its vouchers to change hands.
+type MsgApproveTransfer struct {
+	OwnerAccount sdk.AccAddress `json:"owner-account"`
+	Owner        string         `json:"owner"`
+	Voucher      string         `json:"voucher"`
+	Recipient    string         `json:"recipient"`
+}
+
+var _ types.OwnerMsg = MsgApproveTransfer{}
+
+// Implements sdk.Msg
+func (msg MsgApproveTransfer) Type() string { return "bvs" }
+
+// Implements sdk.Msg
+func (msg MsgApproveTransfer) ValidateBasic() sdk.Error {
+	if msg.Owner != types.UserId(msg.OwnerAccount) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not the id of the signer", msg.Owner))
+	}
+	return msg.ValidateFields()
+}
+
+// Implements types.OwnerMsg
+func (msg MsgApproveTransfer) ValidateFields() sdk.Error {
+	if types.IdKind(msg.Recipient) != types.KindUser {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("%s is not a user id", msg.Recipient))
+	}
+	return nil
+}
+
+// Implements sdk.Msg
+func (msg MsgApproveTransfer) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return []byte{}
+	}
+	return b
+}
+
+// Implements sdk.Msg
+func (msg MsgApproveTransfer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAccount}
+}
+
+// Implements types.OwnerMsg
+func (msg MsgApproveTransfer) GetOwner() string { return msg.Owner }
+
+func BuildApproveTransferMsg(ownerAccount sdk.AccAddress, owner string, voucher string, recp string) sdk.Msg {
+	return &MsgApproveTransfer{
+		OwnerAccount: ownerAccount,
+		Owner:        owner,
+		Voucher:      voucher,
+		Recipient:    recp,
+	}
+}
+
+// Handler
+
+func NewHandler(k Keeper) sdk.Handler {
 	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
-		// TODO
-		return sdk.Result{}
+		switch msg := msg.(type) {
+		case *MsgBvs:
+			return handleMsgBvs(ctx, k, msg)
+		case *MsgSpendVoucher:
+			return handleMsgSpendVoucher(ctx, k, msg)
+		case *MsgReloadVoucher:
+			return handleMsgReloadVoucher(ctx, k, msg)
+		case *MsgApproveTransfer:
+			return handleMsgApproveTransfer(ctx, k, msg)
+		case *MsgAcceptTransfer:
+			return handleMsgAcceptTransfer(ctx, k, msg)
+		case *MsgReclaimTransfer:
+			return handleMsgReclaimTransfer(ctx, k, msg)
+		case *MsgSettleExpired:
+			return handleMsgSettleExpired(ctx, k, msg)
+		case *MsgWithdraw:
+			return handleMsgWithdraw(ctx, k, msg)
+		case *MsgRestock:
+			return handleMsgRestock(ctx, k, msg)
+		case *MsgTransferOwnership:
+			return handleMsgTransferOwnership(ctx, k, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized bvs Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+// Sending coins to a codex purchases a voucher from it, and sending a voucher
+// back to its codex redeems it. Otherwise the asset changes hands, either
+// right away or through a pending transfer.
+func handleMsgBvs(ctx sdk.Context, k Keeper, msg *MsgBvs) sdk.Result {
+	if msg.PendingFor > 0 {
+		pt, tags, err := k.SendPending(ctx, msg.Sender, msg.Recipient, msg.Asset, msg.PendingFor)
+		if err != nil {
+			return err.Result()
+		}
+		return sdk.Result{Data: []byte(pt.Id), Tags: tags}
+	}
+	if types.IdKind(msg.Recipient) != types.KindCodex {
+		tags, err := k.SendAsset(ctx, msg.Sender, msg.Recipient, msg.Asset)
+		if err != nil {
+			return err.Result()
+		}
+		return sdk.Result{Tags: tags}
+	}
+
+	tags := sdk.EmptyTags()
+	for _, id := range msg.Asset.Vouchers {
+		vou := k.vm.GetVoucher(ctx, id)
+		if vou == nil {
+			return ErrUnknownVoucher(k.codespace, id).Result()
+		}
+		if vou.Origin != msg.Recipient {
+			return ErrInvalidId(k.codespace, msg.Recipient).Result()
+		}
+		redeemTags, err := k.Redeem(ctx, msg.Sender, id)
+		if err != nil {
+			return err.Result()
+		}
+		tags = tags.AppendTags(redeemTags)
+	}
+	if !msg.Asset.Coins.IsZero() {
+		vou, purchaseTags, err := k.Purchase(ctx, msg.Sender, msg.Recipient, msg.Asset.Coins)
+		if err != nil {
+			return err.Result()
+		}
+		tags = tags.AppendTags(purchaseTags)
+		return sdk.Result{Data: []byte(vou.Id), Tags: tags}
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgSpendVoucher(ctx sdk.Context, k Keeper, msg *MsgSpendVoucher) sdk.Result {
+	tags, err := k.SpendVoucher(ctx, msg.Holder, msg.Voucher, msg.Amount)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgReloadVoucher(ctx sdk.Context, k Keeper, msg *MsgReloadVoucher) sdk.Result {
+	tags, err := k.ReloadVoucher(ctx, msg.Payer, msg.Voucher, msg.Amount)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Tags: tags}
+}
+
+func handleMsgApproveTransfer(ctx sdk.Context, k Keeper, msg *MsgApproveTransfer) sdk.Result {
+	tags, err := k.ApproveTransfer(ctx, msg.Owner, msg.Voucher, msg.Recipient)
+	if err != nil {
+		return err.Result()
 	}
+	return sdk.Result{Tags: tags}
 }
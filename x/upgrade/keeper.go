@@ -0,0 +1,164 @@
+package upgrade
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+	"github.com/dcgraph/bvs-cosmos/x/gov"
+)
+
+// A Migration rewrites the records of the stores from the schema version
+// Version-1 to Version.
+type Migration struct {
+	Version int
+	Migrate func(ctx sdk.Context) error
+}
+
+// Keeper carries out the software upgrades passed by governance. A binary
+// knows the upgrades it can carry out by their names, along with the
+// migrations they run, and reads records of the schema version it is made
+// with.
+type Keeper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+	gk  gov.Keeper
+
+	version  int
+	handlers map[string][]Migration
+}
+
+// NewKeeper returns a new Keeper of a binary reading records of the given
+// schema version.
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, gk gov.Keeper, version int) Keeper {
+	return Keeper{key: key, cdc: cdc, gk: gk, version: version, handlers: map[string][]Migration{}}
+}
+
+// SetHandler registers the upgrade of the given name, which runs the
+// migrations in the order of their versions.
+func (k Keeper) SetHandler(name string, migrations ...Migration) {
+	k.handlers[name] = migrations
+}
+
+// HasHandler tells whether the binary can carry out the upgrade of the given
+// name.
+func (k Keeper) HasHandler(name string) bool {
+	_, ok := k.handlers[name]
+	return ok
+}
+
+// Version returns the schema version the binary reads.
+func (k Keeper) Version() int { return k.version }
+
+// GetSchemaVersion returns the schema version of the records in the stores.
+// Chains begun before the version was recorded are of the first one.
+func (k Keeper) GetSchemaVersion(ctx sdk.Context) int {
+	bz := ctx.KVStore(k.key).Get(types.SchemaVersionKey)
+	if bz == nil {
+		return 1
+	}
+	var version int
+	k.cdc.MustUnmarshalBinaryBare(bz, &version)
+	return version
+}
+
+func (k Keeper) SetSchemaVersion(ctx sdk.Context, version int) {
+	ctx.KVStore(k.key).Set(types.SchemaVersionKey, k.cdc.MustMarshalBinaryBare(version))
+}
+
+// RewriteRecords rewrites in place the values of a store under a prefix. It
+// is meant for migrations, which decode the records in their old schema and
+// encode them in the new one.
+func RewriteRecords(ctx sdk.Context, key sdk.StoreKey, prefix []byte, rewrite func(bz []byte) ([]byte, error)) error {
+	store := ctx.KVStore(key)
+	iter := sdk.KVStorePrefixIterator(store, prefix)
+	keys, values := [][]byte{}, [][]byte{}
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+		values = append(values, iter.Value())
+	}
+	iter.Close()
+
+	for i, k := range keys {
+		bz, err := rewrite(values[i])
+		if err != nil {
+			return fmt.Errorf("%s: %v", k, err)
+		}
+		store.Set(k, bz)
+	}
+	return nil
+}
+
+// MigrateCodices rewrites the codex records of a codex store, decoded from
+// their old schema by decode.
+func MigrateCodices(ctx sdk.Context, cdc *wire.Codec, key sdk.StoreKey, decode func(bz []byte) (*types.Codex, error)) error {
+	return RewriteRecords(ctx, key, []byte("codex:"), func(bz []byte) ([]byte, error) {
+		cod, err := decode(bz)
+		if err != nil {
+			return nil, err
+		}
+		return cdc.MarshalBinaryBare(cod)
+	})
+}
+
+// MigrateVouchers rewrites the voucher records of a voucher store, decoded
+// from their old schema by decode.
+func MigrateVouchers(ctx sdk.Context, cdc *wire.Codec, key sdk.StoreKey, decode func(bz []byte) (*types.Voucher, error)) error {
+	return RewriteRecords(ctx, key, []byte("voucher:"), func(bz []byte) ([]byte, error) {
+		vou, err := decode(bz)
+		if err != nil {
+			return nil, err
+		}
+		return cdc.MarshalBinaryBare(vou)
+	})
+}
+
+// Apply carries out an upgrade, running its migrations the stores have not
+// gone through yet.
+func (k Keeper) Apply(ctx sdk.Context, name string) error {
+	migrations, ok := k.handlers[name]
+	if !ok {
+		return fmt.Errorf("no handler for the upgrade %s", name)
+	}
+	for _, m := range migrations {
+		if m.Version <= k.GetSchemaVersion(ctx) {
+			continue
+		}
+		if err := m.Migrate(ctx); err != nil {
+			return fmt.Errorf("migration to the schema version %d: %v", m.Version, err)
+		}
+		k.SetSchemaVersion(ctx, m.Version)
+	}
+	return nil
+}
+
+// BeginBlocker carries out the upgrade planned at the current height. The
+// node halts when the binary does not know the upgrade, so that it can be
+// restarted with the binary that does, or when the binary does not read
+// the schema of the records in the stores.
+func BeginBlocker(ctx sdk.Context, k Keeper) sdk.Tags {
+	tags := sdk.EmptyTags()
+	if plan := k.gk.GetUpgradePlan(ctx); plan != nil && ctx.BlockHeight() >= plan.Height {
+		if !k.HasHandler(plan.Name) {
+			panic(fmt.Sprintf("UPGRADE %s NEEDED at height %d", plan.Name, plan.Height))
+		}
+		if err := k.Apply(ctx, plan.Name); err != nil {
+			panic(fmt.Sprintf("upgrade %s failed: %v", plan.Name, err))
+		}
+		k.gk.ClearUpgradePlan(ctx)
+		ctx.Logger().Info(fmt.Sprintf("upgraded to %s at height %d", plan.Name, ctx.BlockHeight()))
+		tags = tags.AppendTag("upgrade", []byte(plan.Name))
+	}
+	if version := k.GetSchemaVersion(ctx); version != k.version {
+		panic(fmt.Sprintf("the stores are of the schema version %d, but the binary reads %d", version, k.version))
+	}
+	return tags
+}
+
+// InitGenesis records that the stores of a new chain are of the schema
+// version of the binary, which reads the genesis state.
+func InitGenesis(ctx sdk.Context, k Keeper) {
+	k.SetSchemaVersion(ctx, k.version)
+}
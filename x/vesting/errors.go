@@ -0,0 +1,18 @@
+package vesting
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Vesting errors reserve 1300 ~ 1399.
+const (
+	DefaultCodespace sdk.CodespaceType = 23
+
+	CodeLockedCoins sdk.CodeType = 1301
+)
+
+func ErrLockedCoins(codespace sdk.CodespaceType, addr sdk.AccAddress, spendable sdk.Coins, amt sdk.Coins) sdk.Error {
+	return sdk.NewError(codespace, CodeLockedCoins, fmt.Sprintf("%s can spend %s, not %s, until its coins vest", addr, spendable, amt))
+}
@@ -0,0 +1,21 @@
+package vesting
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+)
+
+// NewBankHandler returns a handler of bank messages refusing to send locked
+// coins, before handing them over to next.
+func NewBankHandler(k Keeper, next sdk.Handler) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		if msg, ok := msg.(bank.MsgSend); ok {
+			for _, in := range msg.Inputs {
+				if err := k.CheckSpend(ctx, in.Address, in.Coins); err != nil {
+					return err.Result()
+				}
+			}
+		}
+		return next(ctx, msg)
+	}
+}
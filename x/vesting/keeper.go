@@ -0,0 +1,56 @@
+package vesting
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+
+	"github.com/dcgraph/bvs-cosmos/types"
+)
+
+// Keeper tells what coins of vesting accounts are locked.
+type Keeper struct {
+	cdc *wire.Codec
+	am  auth.AccountMapper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper returns a new Keeper.
+func NewKeeper(cdc *wire.Codec, am auth.AccountMapper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{cdc: cdc, am: am, codespace: codespace}
+}
+
+// Balance returns the coins of an account, split into those vested and
+// those locked at the current height.
+func (k Keeper) Balance(ctx sdk.Context, addr sdk.AccAddress) *types.VestingBalance {
+	b := &types.VestingBalance{Address: addr, Coins: sdk.Coins{}, Vested: sdk.Coins{}, Locked: sdk.Coins{}, Spendable: sdk.Coins{}}
+	acc := k.am.GetAccount(ctx, addr)
+	if acc == nil {
+		return b
+	}
+	b.Coins = acc.GetCoins()
+	b.Locked = types.LockedCoins(acc, ctx.BlockHeight())
+	b.Spendable = types.SpendableCoins(acc, ctx.BlockHeight())
+	if vacc, ok := acc.(types.VestingAccount); ok {
+		b.Vested = vacc.VestedCoins(ctx.BlockHeight())
+	}
+	return b
+}
+
+// CheckSpend tells whether an account may send amt without touching its
+// locked coins.
+func (k Keeper) CheckSpend(ctx sdk.Context, addr sdk.AccAddress, amt sdk.Coins) sdk.Error {
+	acc := k.am.GetAccount(ctx, addr)
+	if acc == nil {
+		return nil
+	}
+	if _, ok := acc.(types.VestingAccount); !ok {
+		return nil
+	}
+	spendable := types.SpendableCoins(acc, ctx.BlockHeight())
+	if !spendable.IsGTE(amt) {
+		return ErrLockedCoins(k.codespace, addr, spendable, amt)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package vesting
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Query answers the custom queries of the module, returning JSON:
+//
+//	custom/vesting/balance/<address>  the vested and locked coins of an account
+func Query(ctx sdk.Context, k Keeper, path []string) ([]byte, sdk.Error) {
+	if len(path) == 0 {
+		return nil, sdk.ErrUnknownRequest("no vesting query given")
+	}
+	var res interface{}
+	switch {
+	case path[0] == "balance" && len(path) == 2:
+		addr, err := sdk.AccAddressFromBech32(path[1])
+		if err != nil {
+			return nil, sdk.ErrInvalidAddress(path[1])
+		}
+		res = k.Balance(ctx, addr)
+	default:
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("unknown vesting query %v", path))
+	}
+	bz, err := k.cdc.MarshalJSON(res)
+	if err != nil {
+		return nil, sdk.ErrInternal(err.Error())
+	}
+	return bz, nil
+}